@@ -0,0 +1,445 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"circle-center/globals"
+	"circle-center/processor/operation"
+	"circle-center/reader"
+)
+
+// runRead parses any pack file and prints its content.
+func runRead(c *cli, args []string) (int, error) {
+	fs := c.flags()
+	declared := fs.String("type", "", "file type: appfilter, icon_pack, drawable, appmap, theme_resources or archive")
+	files, err := c.parse(fs, args, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		return 0, err
+	}
+	typ := reader.FileType(*declared)
+	if typ == reader.FileTypeUnknown {
+		if reader.IsArchive(data) {
+			typ = reader.FileTypeArchive
+		} else if typ, err = reader.DetectFileType(bytes.NewReader(data)); errors.Is(err, reader.ErrInconclusiveType) {
+			typ = reader.FileTypeAppFilter
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+	var content any
+	var text func(w io.Writer)
+	r := bytes.NewReader(data)
+	switch typ {
+	case reader.FileTypeAppFilter:
+		res, err := reader.ParseAppFilterDocument(r)
+		if err != nil {
+			return 0, err
+		}
+		content = res
+		text = func(w io.Writer) {
+			fmt.Fprintf(w, "appfilter: %d items\n", len(res.Items))
+			printItems(w, "", res.Items)
+		}
+	case reader.FileTypeIconPack:
+		res, err := reader.ParseIconPackFromReader(r)
+		if err != nil {
+			return 0, err
+		}
+		content = res
+		text = func(w io.Writer) {
+			fmt.Fprintf(w, "icon_pack: %d arrays\n", len(res.Arrays))
+			for _, arr := range res.Arrays {
+				fmt.Fprintf(w, "%s (%d)\n", arr.Name, len(arr.Items))
+				for _, item := range arr.Items {
+					fmt.Fprintf(w, "  %s\n", item)
+				}
+			}
+		}
+	case reader.FileTypeDrawable:
+		res, err := reader.ParseDrawableFromReader(r)
+		if err != nil {
+			return 0, err
+		}
+		content = res
+		text = func(w io.Writer) {
+			fmt.Fprintf(w, "drawable: version %s, %d categories\n", res.Version, len(res.Categories))
+			for _, cat := range res.Categories {
+				fmt.Fprintf(w, "%s (%d)\n", cat.Title, len(cat.Drawables))
+				for _, d := range cat.Drawables {
+					fmt.Fprintf(w, "  %s\n", d)
+				}
+			}
+		}
+	case reader.FileTypeAppMap:
+		res, err := reader.ParseAppMapFromReader(r)
+		if err != nil {
+			return 0, err
+		}
+		content = res
+		text = func(w io.Writer) {
+			fmt.Fprintf(w, "appmap: %d items\n", len(res.Items))
+			for _, item := range res.Items {
+				fmt.Fprintf(w, "%s -> %s\n", item.Class, item.Drawable)
+			}
+		}
+	case reader.FileTypeTheme:
+		res, err := reader.ParseThemeFromReader(r)
+		if err != nil {
+			return 0, err
+		}
+		content = res
+		text = func(w io.Writer) {
+			fmt.Fprintf(w, "theme_resources: %q, %d icons\n", res.Label, len(res.Icons))
+			for _, icon := range res.Icons {
+				fmt.Fprintf(w, "%s -> %s\n", icon.Component, icon.Drawable)
+			}
+		}
+	case reader.FileTypeArchive:
+		res, err := reader.ParseArchive(r, int64(len(data)))
+		if err != nil {
+			return 0, err
+		}
+		content = res
+		text = func(w io.Writer) {
+			kinds := make([]string, 0, len(res.Sources))
+			for kind := range res.Sources {
+				kinds = append(kinds, kind)
+			}
+			sort.Strings(kinds)
+			for _, kind := range kinds {
+				fmt.Fprintf(w, "%s: %s\n", kind, res.Sources[kind])
+			}
+			fmt.Fprintf(w, "appfilter: %d items\n", len(res.AppFilter))
+		}
+	default:
+		return 0, fmt.Errorf("unknown type %q", typ)
+	}
+
+	return exitOK, c.print(map[string]any{"type": typ, "content": content}, text)
+}
+
+// runDiff compares two appfilters; differences are findings. -report writes
+// a Markdown or HTML report instead of the listing.
+func runDiff(c *cli, args []string) (int, error) {
+	fs := c.flags()
+	normalize := fs.Bool("normalize", false, "match components after normalising them")
+	report := fs.String("report", "", "write a markdown or html report instead")
+	title := fs.String("title", "", "report title")
+	icons := fs.String("icons", "", "html report: icon directory to embed thumbnails from")
+	files, err := c.parse(fs, args, 2)
+	if err != nil {
+		return 0, err
+	}
+
+	diff, err := operation.DiffAppFilters(files[0], files[1], operation.DiffOptions{NormalizeComponents: *normalize})
+	if err != nil {
+		return 0, err
+	}
+	found := findings(len(diff.OnlyInFirst)+len(diff.OnlyInSecond)+len(diff.Changed) > 0)
+
+	if *report != "" {
+		opts := operation.DiffReportOptions{Title: *title}
+		if *icons != "" {
+			local, err := reader.ParseIconDirectory(*icons)
+			if err != nil {
+				return 0, err
+			}
+			opts.Thumbnails = operation.LocalThumbnails(local)
+		}
+		return found, operation.RenderDiffReport(c.stdout, diff, operation.ReportFormat(*report), opts)
+	}
+
+	err = c.print(map[string]any{
+		"only_in_first":  diff.OnlyInFirst,
+		"only_in_second": diff.OnlyInSecond,
+		"common":         diff.Common,
+		"changed":        diff.Changed,
+	}, func(w io.Writer) {
+		printItems(w, "- ", diff.OnlyInFirst)
+		printItems(w, "+ ", diff.OnlyInSecond)
+		for _, ch := range diff.Changed {
+			fmt.Fprintf(w, "~ %s: %s -> %s\n", ch.Component, describeItem(ch.Before), describeItem(ch.After))
+		}
+		fmt.Fprintf(w, "%d removed, %d added, %d changed, %d unchanged\n",
+			len(diff.OnlyInFirst), len(diff.OnlyInSecond), len(diff.Changed), len(diff.Common))
+	})
+	return found, err
+}
+
+// runMerge merges two appfilters, or three with -base. Conflicts are
+// findings. The merged file goes to -o, or to stdout.
+func runMerge(c *cli, args []string) (int, error) {
+	fs := c.flags()
+	base := fs.String("base", "", "common ancestor; enables a three-way merge of OURS and THEIRS")
+	markers := fs.Bool("markers", false, "three-way: write conflicts with git-style markers")
+	intoFirst := fs.Bool("into-first", false, "two-way: merge SECOND into FIRST instead of FIRST into SECOND")
+	selected := fs.String("components", "", "two-way: comma-separated components to merge instead of all")
+	out := fs.String("o", "", "output file; stdout when empty")
+	files, err := c.parse(fs, args, 2)
+	if err != nil {
+		return 0, err
+	}
+
+	var result any
+	var content []byte
+	conflicts := 0
+	if *base != "" {
+		var readers [3]*os.File
+		for i, path := range []string{*base, files[0], files[1]} {
+			f, err := os.Open(path)
+			if err != nil {
+				return 0, err
+			}
+			defer f.Close()
+			readers[i] = f
+		}
+		res, merged, err := operation.MergeThreeWay(readers[0], readers[1], readers[2], operation.Merge3Options{Markers: *markers})
+		if err != nil {
+			return 0, err
+		}
+		result, content, conflicts = res, merged, len(res.Conflicts)
+	} else {
+		req := operation.MergeRequest{MergeIntoFirst: *intoFirst}
+		if *selected != "" {
+			req.Mode = operation.MergeSelected
+			for _, comp := range strings.Split(*selected, ",") {
+				req.SelectedComponents = append(req.SelectedComponents, strings.TrimSpace(comp))
+			}
+		}
+		first, err := os.Open(files[0])
+		if err != nil {
+			return 0, err
+		}
+		defer first.Close()
+		second, err := os.Open(files[1])
+		if err != nil {
+			return 0, err
+		}
+		defer second.Close()
+
+		var buf bytes.Buffer
+		res, err := operation.MergeAppFilterReaders(first, second, &buf, req)
+		if err != nil {
+			return 0, err
+		}
+		result, content = res, buf.Bytes()
+	}
+
+	if *out != "" {
+		if err := os.WriteFile(*out, content, 0o644); err != nil {
+			return 0, err
+		}
+	}
+	if c.format == "json" {
+		payload := map[string]any{"result": result}
+		if *out == "" {
+			payload["content"] = string(content)
+		}
+		return findings(conflicts > 0), c.print(payload, nil)
+	}
+	if *out == "" {
+		if _, err := c.stdout.Write(content); err != nil {
+			return 0, err
+		}
+	}
+	if conflicts > 0 {
+		fmt.Fprintf(c.stderr, "%d conflicts\n", conflicts)
+	}
+	return findings(conflicts > 0), nil
+}
+
+// runLint checks an appfilter. Errors are findings, and warnings too with
+// -strict.
+func runLint(c *cli, args []string) (int, error) {
+	fs := c.flags()
+	rules := fs.String("rules", "", `JSON object mapping rule ids to a severity, e.g. {"missing-app-name":"off"}`)
+	iconPack := fs.String("icon-pack", "", "icon_pack.xml for the missing-from-icon-pack rule")
+	strict := fs.Bool("strict", false, "treat warnings as findings")
+	files, err := c.parse(fs, args, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	var cfg operation.LintConfig
+	if *rules != "" {
+		if err := json.Unmarshal([]byte(*rules), &cfg.Rules); err != nil {
+			return 0, fmt.Errorf("invalid rules: %w", err)
+		}
+	}
+	if *iconPack != "" {
+		res, err := reader.ReadIconPack(*iconPack)
+		if err != nil {
+			return 0, err
+		}
+		cfg.IconPack = &res
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	result, err := operation.LintAppFilter(f, cfg)
+	if err != nil {
+		return 0, err
+	}
+
+	errs := operation.CountFindings(result, operation.SeverityError)
+	warnings := operation.CountFindings(result, operation.SeverityWarning)
+	err = c.print(map[string]any{
+		"findings": result,
+		"summary": map[string]int{
+			"total":    len(result),
+			"errors":   errs,
+			"warnings": warnings,
+			"infos":    operation.CountFindings(result, operation.SeverityInfo),
+		},
+	}, func(w io.Writer) {
+		for _, f := range result {
+			fmt.Fprintf(w, "%s:%d: %s [%s] %s\n", files[0], f.Line, f.Severity, f.Rule, f.Message)
+		}
+	})
+	return findings(errs > 0 || (*strict && warnings > 0)), err
+}
+
+// runFormat formats an appfilter to stdout, in place with -w, or only
+// reports whether it is formatted with -check (an unformatted file is a
+// finding).
+func runFormat(c *cli, args []string) (int, error) {
+	fs := c.flags()
+	sortBy := fs.String("sort", "", "group order: app_name (default) or package")
+	indent := fs.Int("indent", 4, "spaces per level")
+	write := fs.Bool("w", false, "write the result back to the file")
+	check := fs.Bool("check", false, "only report whether the file is formatted")
+	files, err := c.parse(fs, args, 1)
+	if err != nil {
+		return 0, err
+	}
+	if *indent < 1 || *indent > 8 {
+		return 0, fmt.Errorf("invalid indent %d", *indent)
+	}
+	opts := operation.FormatOptions{Sort: operation.FormatSort(*sortBy), Indent: strings.Repeat(" ", *indent)}
+	switch opts.Sort {
+	case "", operation.SortByAppName, operation.SortByPackage:
+	default:
+		return 0, fmt.Errorf("invalid sort %q", *sortBy)
+	}
+
+	src, err := os.ReadFile(files[0])
+	if err != nil {
+		return 0, err
+	}
+	var buf bytes.Buffer
+	result, err := operation.FormatAppFilter(bytes.NewReader(src), &buf, opts)
+	if err != nil {
+		return 0, err
+	}
+	changed := !bytes.Equal(src, buf.Bytes())
+
+	if *write && changed {
+		if err := os.WriteFile(files[0], buf.Bytes(), 0o644); err != nil {
+			return 0, err
+		}
+	}
+	if c.format == "json" {
+		payload := map[string]any{"result": result, "changed": changed}
+		if !*write && !*check {
+			payload["content"] = buf.String()
+		}
+		return findings(*check && changed), c.print(payload, nil)
+	}
+	switch {
+	case *check:
+		if changed {
+			fmt.Fprintf(c.stdout, "%s is not formatted\n", files[0])
+		}
+		return findings(changed), nil
+	case *write:
+		return exitOK, nil
+	}
+	_, err = c.stdout.Write(buf.Bytes())
+	return exitOK, err
+}
+
+// runMissingIcons lists icons the appfilter does not cover.
+func runMissingIcons(c *cli, args []string) (int, error) {
+	fs := c.flags()
+	files, err := c.parse(fs, args, 2)
+	if err != nil {
+		return 0, err
+	}
+
+	missing, err := operation.FindMissingIcons(files[0], files[1])
+	if err != nil {
+		return 0, err
+	}
+	err = c.print(map[string]any{"missing_icons": missing, "count": len(missing)}, func(w io.Writer) {
+		for _, name := range missing {
+			fmt.Fprintln(w, name)
+		}
+	})
+	return findings(len(missing) > 0), err
+}
+
+// runSync adds appfilter items for icons without one. Packages whose
+// activities are unknown are written with a TODO activity and are findings.
+func runSync(c *cli, args []string) (int, error) {
+	fs := c.flags()
+	known := fs.String("known", "", "appfilter.xml whose components are used to resolve activities")
+	files, err := c.parse(fs, args, 2)
+	if err != nil {
+		return 0, err
+	}
+
+	var index *reader.ComponentIndex
+	if *known != "" {
+		items, err := reader.ReadAppFilter(*known)
+		if err != nil {
+			return 0, err
+		}
+		index = reader.NewComponentIndex(0)
+		for _, item := range items {
+			index.Add(item.Component)
+		}
+	}
+
+	report, err := operation.SyncIconsToAppFilter(files[0], files[1], index)
+	if err != nil {
+		return 0, err
+	}
+	err = c.print(report, func(w io.Writer) {
+		fmt.Fprintf(w, "added %d items\n", report.Added)
+		for _, pkg := range report.Unresolved {
+			fmt.Fprintf(w, "unresolved: %s\n", pkg)
+		}
+	})
+	return findings(len(report.Unresolved) > 0), err
+}
+
+// printItems writes one line per item, each prefixed with prefix.
+func printItems(w io.Writer, prefix string, items []globals.Item) {
+	for _, item := range items {
+		fmt.Fprintf(w, "%s%s %s\n", prefix, item.Component, describeItem(item))
+	}
+}
+
+// describeItem renders the mapping of an item as drawable (app name).
+func describeItem(item globals.Item) string {
+	if item.AppName == "" {
+		return item.Drawable
+	}
+	return fmt.Sprintf("%s (%s)", item.Drawable, item.AppName)
+}
@@ -0,0 +1,156 @@
+// Command circlectl runs the reader and processor operations on local files,
+// without the HTTP server, MySQL or Redis, so build scripts and pre-commit
+// hooks can use them.
+//
+// Usage:
+//
+//	circlectl <command> [flags] <files...>
+//
+// Every command accepts -format text (the default) or -format json. The exit
+// code is 0 when the command found nothing, 1 when it reported findings
+// (differences, lint errors, missing icons, merge conflicts, unformatted or
+// unresolved entries) and 2 on usage or I/O errors.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Exit codes.
+const (
+	exitOK       = 0
+	exitFindings = 1
+	exitError    = 2
+)
+
+// command is one circlectl subcommand. run returns the exit code; a non-nil
+// error is printed and turns into exitError.
+type command struct {
+	usage   string
+	summary string
+	run     func(c *cli, args []string) (int, error)
+}
+
+// commands lists every subcommand by name.
+var commands = map[string]command{
+	"read":          {"read [-type TYPE] FILE", "parse a pack file (type detected unless given)", runRead},
+	"diff":          {"diff [-normalize] [-report markdown|html [-title T] [-icons DIR]] FIRST SECOND", "compare two appfilter.xml files", runDiff},
+	"merge":         {"merge [-into-first] [-o OUT] FIRST SECOND | merge -base BASE [-markers] [-o OUT] OURS THEIRS", "merge appfilter.xml files", runMerge},
+	"lint":          {"lint [-rules JSON] [-icon-pack FILE] [-strict] APPFILTER", "check an appfilter.xml", runLint},
+	"format":        {"format [-sort app_name|package] [-indent N] [-w | -check] APPFILTER", "rewrite an appfilter.xml canonically", runFormat},
+	"missing-icons": {"missing-icons ICON_DIR APPFILTER", "list icons without an appfilter item", runMissingIcons},
+	"sync":          {"sync [-known APPFILTER] ICON_DIR APPFILTER", "add appfilter items for icons that have none", runSync},
+}
+
+// errUsage reports wrong arguments; the command usage is printed with it.
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return exitError
+		}
+		return exitOK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "circlectl: unknown command %q\n", args[0])
+		printUsage(stderr)
+		return exitError
+	}
+
+	c := &cli{name: args[0], stdout: stdout, stderr: stderr}
+	code, err := cmd.run(c, args[1:])
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "usage: circlectl %s\n", cmd.usage)
+		return exitError
+	case err != nil:
+		fmt.Fprintf(stderr, "circlectl %s: %v\n", args[0], err)
+		return exitError
+	}
+	return code
+}
+
+// printUsage lists the commands.
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage: circlectl <command> [flags] <files...>")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-14s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w, "\nEvery command accepts -format text|json. Exit codes: 0 clean, 1 findings, 2 errors.")
+}
+
+// cli carries the output streams and the output format of one invocation.
+type cli struct {
+	name   string
+	stdout io.Writer
+	stderr io.Writer
+	format string
+}
+
+// flags returns a flag set for the command with the shared -format flag.
+func (c *cli) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.StringVar(&c.format, "format", "text", "output format: text or json")
+	return fs
+}
+
+// parse parses args and checks the number of positional arguments.
+func (c *cli) parse(fs *flag.FlagSet, args []string, want int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, errUsage
+	}
+	if c.format != "text" && c.format != "json" {
+		return nil, fmt.Errorf("unknown format %q", c.format)
+	}
+	if fs.NArg() != want {
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+// print writes v as indented JSON, or calls text in text mode.
+func (c *cli) print(v any, text func(w io.Writer)) error {
+	if c.format == "json" {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	text(c.stdout)
+	return nil
+}
+
+// findings returns exitFindings when found is set.
+func findings(found bool) int {
+	if found {
+		return exitFindings
+	}
+	return exitOK
+}
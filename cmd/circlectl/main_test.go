@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const ctlFirst = `<resources>
+    <!-- Calc -->
+    <item component="ComponentInfo{com.calc/com.calc.Main}" drawable="calc" />
+</resources>
+`
+
+const ctlSecond = `<resources>
+    <!-- Calc -->
+    <item component="ComponentInfo{com.calc/com.calc.Main}" drawable="calc" />
+    <!-- Camera -->
+    <item component="ComponentInfo{com.cam/com.cam.Main}" drawable="cam" />
+</resources>
+`
+
+func writeTemp(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func runCtl(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestDiffExitCodes(t *testing.T) {
+	first := writeTemp(t, "first.xml", ctlFirst)
+	second := writeTemp(t, "second.xml", ctlSecond)
+
+	if code, out, _ := runCtl("diff", first, first); code != exitOK {
+		t.Errorf("identical files: exit %d\n%s", code, out)
+	}
+
+	code, out, _ := runCtl("diff", "-format", "json", first, second)
+	if code != exitFindings {
+		t.Fatalf("expected exit %d, got %d", exitFindings, code)
+	}
+	var got struct {
+		OnlyInSecond []struct{ Component string } `json:"only_in_second"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("output is not json: %v\n%s", err, out)
+	}
+	if len(got.OnlyInSecond) != 1 || got.OnlyInSecond[0].Component != "ComponentInfo{com.cam/com.cam.Main}" {
+		t.Errorf("unexpected diff: %s", out)
+	}
+}
+
+func TestFormatCheckAndWrite(t *testing.T) {
+	path := writeTemp(t, "appfilter.xml", strings.Replace(ctlSecond, "    <!-- Calc -->", "  <!-- Calc -->", 1))
+
+	if code, out, _ := runCtl("format", "-check", path); code != exitFindings || !strings.Contains(out, "not formatted") {
+		t.Fatalf("check: exit %d, output %q", code, out)
+	}
+	if code, _, stderr := runCtl("format", "-w", path); code != exitOK {
+		t.Fatalf("write: exit %d: %s", code, stderr)
+	}
+	if code, out, _ := runCtl("format", "-check", path); code != exitOK {
+		t.Fatalf("check after write: exit %d, output %q", code, out)
+	}
+}
+
+func TestLintAndUsageErrors(t *testing.T) {
+	path := writeTemp(t, "appfilter.xml", `<resources>
+    <item component="ComponentInfo{com.calc/com.calc.Main}" drawable="calc" />
+</resources>
+`)
+
+	// A missing app name is only a warning.
+	if code, _, _ := runCtl("lint", path); code != exitOK {
+		t.Errorf("lint: expected exit %d, got %d", exitOK, code)
+	}
+	if code, _, _ := runCtl("lint", "-strict", path); code != exitFindings {
+		t.Errorf("lint -strict: expected exit %d, got %d", exitFindings, code)
+	}
+
+	if code, _, stderr := runCtl("diff", path); code != exitError || !strings.Contains(stderr, "usage: circlectl diff") {
+		t.Errorf("missing argument: exit %d, stderr %q", code, stderr)
+	}
+	if code, _, _ := runCtl("nope"); code != exitError {
+		t.Errorf("unknown command: expected exit %d, got %d", exitError, code)
+	}
+	if code, _, stderr := runCtl("read", filepath.Join(t.TempDir(), "missing.xml")); code != exitError || stderr == "" {
+		t.Errorf("missing file: exit %d, stderr %q", code, stderr)
+	}
+}
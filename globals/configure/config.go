@@ -13,6 +13,7 @@ type Config struct {
 	Frontend FrontendConfig `yaml:"frontend" json:"frontend"`
 	JWT      JWTConfig      `yaml:"jwt" json:"jwt"`
 	Avatar   AvatarConfig   `yaml:"avatar" json:"avatar"`
+	Projects ProjectsConfig `yaml:"projects" json:"projects"`
 }
 
 // ServerConfig holds server configuration
@@ -78,6 +79,14 @@ type AvatarConfig struct {
 	MaxImageQuality int `yaml:"max_image_quality" json:"max_image_quality"`
 }
 
+// ProjectsConfig holds project lifecycle configuration
+type ProjectsConfig struct {
+	// How long deleted projects can be restored before they are purged
+	RetentionPeriod time.Duration `yaml:"retention_period" json:"retention_period"`
+	// How often the purge job looks for projects past the retention period
+	PurgeInterval time.Duration `yaml:"purge_interval" json:"purge_interval"`
+}
+
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
 	Issuer         string        `yaml:"issuer" json:"issuer"`
@@ -141,6 +150,10 @@ func DefaultConfig() *Config {
 			MaxUploadBytes:  2 * 1024 * 1024, // 2MB
 			MaxImageQuality: 90,
 		},
+		Projects: ProjectsConfig{
+			RetentionPeriod: 30 * 24 * time.Hour,
+			PurgeInterval:   time.Hour,
+		},
 		JWT: JWTConfig{
 			Issuer:         "circle-center",
 			ExpiryTime:     168 * time.Hour,
@@ -121,6 +121,35 @@ func (s *MailService) SendVerificationEmail(to, token, verificationURL string) e
 	return nil
 }
 
+func (s *MailService) SendInvitationEmail(data InvitationTemplateData) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	msg := mail.NewMsg()
+	if err := msg.From(s.config.From); err != nil {
+		return fmt.Errorf("failed to set from address: %w", err)
+	}
+	if err := msg.To(data.Email); err != nil {
+		return fmt.Errorf("failed to set to address: %w", err)
+	}
+	msg.Subject(fmt.Sprintf("Circle Center: you are invited to %s", data.ProjectName))
+
+	htmlContent, err := LoadInvitationTemplate(data)
+	if err != nil {
+		return fmt.Errorf("failed to load invitation template: %w", err)
+	}
+
+	msg.SetBodyString(mail.TypeTextHTML, htmlContent)
+
+	if err := s.client.DialAndSendWithContext(ctx, msg); err != nil {
+		slog.Error("Failed to send invitation email", "to", data.Email, "error", err)
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	slog.Info("Invitation email sent successfully", "to", data.Email)
+	return nil
+}
+
 func (s *MailService) SendTextEmail(to, subject, body string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -41,3 +41,34 @@ func LoadVerificationTemplate(email, token, verificationURL string) (string, err
 
 	return buf.String(), nil
 }
+
+// InvitationTemplateData holds data for project invitation email template
+type InvitationTemplateData struct {
+	Email       string
+	InviterName string
+	ProjectName string
+	Role        string
+	AcceptURL   string
+	ExpiresAt   string
+}
+
+// LoadInvitationTemplate loads and renders the project invitation email template
+func LoadInvitationTemplate(data InvitationTemplateData) (string, error) {
+	templatePath := filepath.Join("globals", "mail", "templates", "invitation", "invitation.html")
+	templateContent, err := os.ReadFile(templatePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template file: %w", err)
+	}
+
+	tmpl, err := template.New("invitation").Parse(string(templateContent))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.String(), nil
+}
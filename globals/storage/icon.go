@@ -82,6 +82,12 @@ func (s *IconStorage) AbsolutePath(relativePath string) (string, error) {
 	return s.base.AbsolutePath(relativePath)
 }
 
+// ProjectDir returns the absolute path of the directory holding every icon of
+// a project, icons/{project_id}. The directory may not exist yet.
+func (s *IconStorage) ProjectDir(projectID uint64) (string, error) {
+	return s.base.AbsolutePath(path.Join("icons", strconv.FormatUint(projectID, 10)))
+}
+
 // GetIconPath generates the expected relative path for an icon without saving it.
 // This is useful for checking if an icon already exists or for generating paths.
 func (s *IconStorage) GetIconPath(projectID uint64, drawable string, format string) string {
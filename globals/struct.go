@@ -36,11 +36,121 @@ type IconPackResources struct {
 	Arrays  []StringArray `xml:"string-array"`
 }
 
-// LocalIcon represents a PNG icon discovered in an icons directory. The
-// FileName includes extension; PackageName is derived from the base name
-// (without extension). FilePath holds the full path to the file.
+// LocalIcon represents a drawable discovered in an icons directory or an
+// Android res tree. The FileName includes extension; PackageName is derived
+// from the base name (without extension). FilePath holds the full path to the
+// preferred file, which is the highest-density variant. Variants lists every
+// file found for the same drawable name, best first.
 type LocalIcon struct {
 	FileName    string
 	PackageName string
 	FilePath    string
+	Variants    []DrawableVariant
+}
+
+// DrawableVariant is one file backing a drawable. Qualifier holds the full
+// resource configuration of the directory (e.g. "xxxhdpi-v4", empty for the
+// flat root or a plain drawable folder), Density the density qualifier alone
+// (e.g. "xxxhdpi", "nodpi", empty when unspecified) and Format the file
+// format ("png", "webp", "jpg" or "xml" for VectorDrawables).
+type DrawableVariant struct {
+	Qualifier string
+	Density   string
+	Format    string
+	FilePath  string
+}
+
+// AppFilter is the full typed model of an appfilter.xml document. Besides the
+// <item> mappings it keeps the pack-level theming elements a launcher reads
+// (<iconback>, <iconmask>, <iconupon>, <scale>) as well as the <calendar> and
+// <dynamic-clock> entries. Items keep their document order.
+type AppFilter struct {
+	IconBack      []string
+	IconMask      []string
+	IconUpon      []string
+	Scale         float64
+	Calendars     []Calendar
+	DynamicClocks []DynamicClock
+	Items         []Item
+}
+
+// Calendar represents a <calendar component prefix> element. The launcher
+// resolves the drawable as Prefix followed by the current day of month (1-31).
+type Calendar struct {
+	Component    string
+	Prefix       string
+	AppName      string
+	PackageName  string
+	ActivityName string
+}
+
+// DynamicClock represents a <dynamic-clock> element describing a layered
+// clock drawable. Layer indices and defaults are nil when the attribute is
+// absent or not a valid integer.
+type DynamicClock struct {
+	Drawable         string
+	HourLayerIndex   *int
+	MinuteLayerIndex *int
+	SecondLayerIndex *int
+	DefaultHour      *int
+	DefaultMinute    *int
+	DefaultSecond    *int
+}
+
+// DrawableCategory represents one <category title> section of drawable.xml
+// together with the drawable names of the <item> elements that follow it.
+type DrawableCategory struct {
+	Title     string
+	Drawables []string
+}
+
+// DrawableResources corresponds to the root <resources> element of
+// drawable.xml as used by dashboard apps (Blueprint, CandyBar). Categories
+// keep their document order; items that appear before the first <category>
+// are collected into a category with an empty title.
+type DrawableResources struct {
+	Version    string
+	Categories []DrawableCategory
+}
+
+// AppMapItem represents one <item class name> element of appmap.xml. Class is
+// the activity class (often relative, e.g. ".MainActivity") and Drawable is
+// the value of the name attribute.
+type AppMapItem struct {
+	Class    string
+	Drawable string
+	AppName  string // app name parsed from XML comment
+}
+
+// AppMapResources corresponds to the root <appmap> element of appmap.xml.
+type AppMapResources struct {
+	Items []AppMapItem
+}
+
+// ThemeIcon represents one <AppIcon name image> element inside
+// theme_resources.xml. Component holds the raw "pkg/activity" string.
+type ThemeIcon struct {
+	Component    string
+	Drawable     string
+	AppName      string // app name parsed from XML comment
+	PackageName  string
+	ActivityName string
+}
+
+// ThemeResources corresponds to the root <Theme> element of
+// theme_resources.xml. Label is taken from the <Label value> element.
+type ThemeResources struct {
+	Label string
+	Icons []ThemeIcon
+}
+
+// ArchiveResources collects the icon pack files found inside an APK or ZIP
+// archive. Fields are left empty when the corresponding file is absent.
+// Sources maps the file kind ("appfilter", "drawable", "appmap") to the
+// archive entry it was read from.
+type ArchiveResources struct {
+	AppFilter []Item
+	Drawable  *DrawableResources
+	AppMap    *AppMapResources
+	Sources   map[string]string
 }
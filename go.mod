@@ -16,6 +16,7 @@ require (
 	github.com/redis/go-redis/v9 v9.11.0
 	github.com/wneessen/go-mail v0.6.2
 	golang.org/x/crypto v0.40.0
+	golang.org/x/net v0.42.0
 	sigs.k8s.io/yaml v1.6.0
 )
 
@@ -55,7 +56,6 @@ require (
 	go.yaml.in/yaml/v2 v2.4.2 // indirect
 	golang.org/x/arch v0.19.0 // indirect
 	golang.org/x/image v0.30.0 // indirect
-	golang.org/x/net v0.42.0 // indirect
 	golang.org/x/sys v0.34.0 // indirect
 	golang.org/x/text v0.28.0 // indirect
 	google.golang.org/protobuf v1.36.6 // indirect
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -91,7 +92,13 @@ func main() {
 	reader.RegisterRoutes(v1)
 	editor.RegisterRoutes(v1)
 	account.RegisterRoutes(v1, dbpkg.GetDB().DB, mailService, authClient)
-	mgr.RegisterRoutes(v1, dbpkg.GetDB().DB, authClient)
+	mgr.RegisterRoutes(v1, dbpkg.GetDB().DB, mailService, authClient)
+
+	if cfg.Projects.PurgeInterval > 0 {
+		if err := mgr.StartProjectPurger(context.Background(), dbpkg.GetDB().DB, cfg.Projects.PurgeInterval); err != nil {
+			log.Printf("Warning: Failed to start project purger: %v", err)
+		}
+	}
 
 	serverAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	log.Printf("Starting server on %s", serverAddr)
@@ -0,0 +1,196 @@
+package manager
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	accountsvc "circle-center/panel/account/svc"
+	oputils "circle-center/panel/account/utils"
+	svc "circle-center/panel/manager/svc"
+	mutils "circle-center/panel/manager/utils"
+)
+
+// callerKey is the gin context key of the *svc.Caller set by AccessHandler.
+const callerKey = "caller"
+
+// maxAccessBodyBytes bounds how much of a JSON body is read to find the
+// project id in it.
+const maxAccessBodyBytes = 10 << 20
+
+// ProjectIDSource tells AccessHandler where a route carries its project id.
+type ProjectIDSource int
+
+const (
+	// FromParamID reads the :id path parameter.
+	FromParamID ProjectIDSource = iota
+	// FromParamProjectID reads the :projectId path parameter.
+	FromParamProjectID
+	// FromIconPath reads the project id of an icons/{project_id}/... *relpath.
+	FromIconPath
+	// FromBodyProjectID reads "projectId" from the JSON body, or the
+	// projectId query parameter.
+	FromBodyProjectID
+)
+
+// RouteAccess is the permission a route requires and where its project id
+// comes from. Routes with svc.PermAuthenticated carry no project id.
+type RouteAccess struct {
+	Permission svc.Permission
+	ProjectID  ProjectIDSource
+}
+
+// AccessHandler authorizes every request of a route group against a
+// permission table keyed by "METHOD /path" relative to the group, as
+// registered with gin. Routes missing from the table are refused.
+type AccessHandler struct {
+	service *svc.AccessService
+	routes  map[string]RouteAccess
+}
+
+// NewAccessHandler constructs an AccessHandler for the given route table.
+func NewAccessHandler(db *sql.DB, authClient *accountsvc.AuthClient, routes map[string]RouteAccess) *AccessHandler {
+	return &AccessHandler{service: svc.NewAccessService(db, authClient), routes: routes}
+}
+
+// Middleware returns the gin middleware for the group at basePath. It stores
+// the bearer token under "token" and the resolved caller for CallerFromContext.
+func (h *AccessHandler) Middleware(basePath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, ok := h.routes[c.Request.Method+" "+strings.TrimPrefix(c.FullPath(), basePath)]
+		if !ok {
+			// Unmatched paths fall through to gin's 404.
+			if c.FullPath() == "" {
+				c.Next()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "FORBIDDEN", "message": "route has no access rule"})
+			return
+		}
+
+		token, err := oputils.ExtractBearerToken(c)
+		if err != nil {
+			oputils.RespondWithAuthError(c, err)
+			c.Abort()
+			return
+		}
+		c.Set("token", token)
+
+		var projectID uint64
+		if route.Permission != svc.PermAuthenticated {
+			projectID, err = routeProjectID(c, route.ProjectID)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "INVALID_PROJECT_ID", "message": err.Error()})
+				return
+			}
+		}
+
+		caller, err := h.service.Authorize(c.Request.Context(), token, projectID, route.Permission)
+		if err != nil {
+			respondAccessError(c, err)
+			return
+		}
+		c.Set(callerKey, caller)
+		c.Next()
+	}
+}
+
+// CallerFromContext returns the caller resolved by AccessHandler.
+func CallerFromContext(c *gin.Context) (*svc.Caller, bool) {
+	v, ok := c.Get(callerKey)
+	if !ok {
+		return nil, false
+	}
+	caller, ok := v.(*svc.Caller)
+	return caller, ok
+}
+
+// requireCaller returns the caller resolved by AccessHandler, or responds 401
+// when the route was not authorized by it.
+func requireCaller(c *gin.Context) (*svc.Caller, bool) {
+	caller, ok := CallerFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "UNAUTHORIZED", "message": "caller not resolved"})
+	}
+	return caller, ok
+}
+
+// respondAccessError aborts with the response for an access error.
+func respondAccessError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, svc.ErrUnauthenticated):
+		oputils.RespondWithAuthError(c, oputils.AuthError{Code: "INVALID_TOKEN", Message: err.Error()})
+		c.Abort()
+	case errors.Is(err, svc.ErrProjectNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "PROJECT_NOT_FOUND", "message": err.Error()})
+	case errors.Is(err, svc.ErrProjectArchived):
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "PROJECT_ARCHIVED", "message": "project is archived and read-only"})
+	case errors.Is(err, svc.ErrForbidden):
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "FORBIDDEN", "message": "insufficient project role"})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "ACCESS_CHECK_FAILED", "message": err.Error()})
+	}
+}
+
+// routeProjectID reads the project id of the request from source.
+func routeProjectID(c *gin.Context, source ProjectIDSource) (uint64, error) {
+	var raw string
+	switch source {
+	case FromParamID:
+		raw = c.Param("id")
+	case FromParamProjectID:
+		raw = c.Param("projectId")
+	case FromIconPath:
+		_, id, err := mutils.ParseIconPath(c.Param("relpath"))
+		if err != nil {
+			return 0, err
+		}
+		return id, nil
+	case FromBodyProjectID:
+		id, err := bodyProjectID(c)
+		if err != nil {
+			return 0, err
+		}
+		raw = c.Query("projectId")
+		if id != 0 {
+			// Handlers may prefer either; both must name the same project.
+			if raw != "" && strings.TrimSpace(raw) != strconv.FormatUint(id, 10) {
+				return 0, errors.New("body and query name different projects")
+			}
+			return id, nil
+		}
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+	if err != nil || id == 0 {
+		return 0, errors.New("project id must be uint")
+	}
+	return id, nil
+}
+
+// bodyProjectID peeks at "projectId" in a JSON body and puts the body back
+// for the handler. It returns 0 when the body names no project.
+func bodyProjectID(c *gin.Context) (uint64, error) {
+	if c.Request.Body == nil {
+		return 0, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAccessBodyBytes))
+	if err != nil {
+		return 0, err
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), c.Request.Body))
+
+	var body struct {
+		ProjectID uint64 `json:"projectId"`
+	}
+	if json.Unmarshal(data, &body) != nil {
+		return 0, nil
+	}
+	return body.ProjectID, nil
+}
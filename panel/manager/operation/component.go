@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	svc "circle-center/panel/manager/svc"
+	"circle-center/processor/operation"
+)
+
+// ComponentHandler wires HTTP to ComponentService
+type ComponentHandler struct {
+	service *svc.ComponentService
+}
+
+// NewComponentHandler builds a component handler
+func NewComponentHandler(db *sql.DB) *ComponentHandler {
+	return &ComponentHandler{service: svc.NewComponentService(db)}
+}
+
+// SyncAppFilter handles POST /manager/icons/sync. It accepts form-data with
+// "icons" (a ZIP of icons named by package) and "appfilter" (file), and
+// returns the appfilter with items added for the icons it lacked. Activities
+// are looked up among the components of every stored icon and request item.
+func (h *ComponentHandler) SyncAppFilter(c *gin.Context) {
+	iconsHeader, err := c.FormFile("icons")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REQUEST", "message": "missing icons field: " + err.Error()})
+		return
+	}
+	appFilterHeader, err := c.FormFile("appfilter")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REQUEST", "message": "missing appfilter field: " + err.Error()})
+		return
+	}
+
+	icons, err := iconsHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "UPLOAD_FAILED", "message": "cannot open icons: " + err.Error()})
+		return
+	}
+	defer icons.Close()
+	appFilterFile, err := appFilterHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "UPLOAD_FAILED", "message": "cannot open appfilter: " + err.Error()})
+		return
+	}
+	defer appFilterFile.Close()
+	appFilter, err := io.ReadAll(appFilterFile)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "UPLOAD_FAILED", "message": "cannot read appfilter: " + err.Error()})
+		return
+	}
+
+	updated, report, err := h.service.SyncAppFilter(c.Request.Context(), icons, iconsHeader.Size, appFilter)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, operation.ErrArchiveTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, gin.H{"error": "SYNC_APPFILTER_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": gin.H{
+		"appfilter":  string(updated),
+		"resolved":   report.Resolved,
+		"unresolved": report.Unresolved,
+		"added":      report.Added,
+	}})
+}
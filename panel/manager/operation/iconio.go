@@ -2,6 +2,7 @@ package manager
 
 import (
 	"database/sql"
+	"errors"
 	"io"
 	"net/http"
 	"os"
@@ -11,9 +12,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/h2non/filetype"
 
+	"circle-center/globals"
 	accountsvc "circle-center/panel/account/svc"
 	oputils "circle-center/panel/account/utils"
 	svc "circle-center/panel/manager/svc"
+	"circle-center/processor/operation"
+	"circle-center/reader"
 )
 
 // IconIOHandler exposes HTTP handlers for icon upload and retrieval
@@ -142,3 +146,132 @@ func (h *IconIOHandler) GetIcon(c *gin.Context) {
 	c.Status(http.StatusOK)
 	_, _ = c.Writer.Write(bytes)
 }
+
+// renameRequest is the body of POST /manager/projects/:id/icons/rename.
+type renameRequest struct {
+	Rules  []operation.RenameRule `json:"rules" binding:"required"`
+	DryRun bool                   `json:"dry_run"`
+}
+
+// RenameDrawables handles POST /manager/projects/:id/icons/rename
+// Applies the rename rules to the project's drawables, or only previews the
+// plan when dry_run is set. Collisions are reported with 409 and no change.
+func (h *IconIOHandler) RenameDrawables(c *gin.Context) {
+	token, ok := oputils.GetTokenFromContext(c)
+	if !ok {
+		var err error
+		token, err = oputils.ExtractBearerToken(c)
+		if err != nil {
+			oputils.RespondWithAuthError(c, err)
+			return
+		}
+	}
+
+	projectID, err := strconv.ParseUint(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_PROJECT_ID", "message": "project id must be uint"})
+		return
+	}
+
+	var req renameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REQUEST", "message": err.Error()})
+		return
+	}
+
+	plan, err := h.service.RenameDrawables(c.Request.Context(), token, projectID, req.Rules, req.DryRun)
+	if errors.Is(err, operation.ErrRenameCollision) {
+		c.JSON(http.StatusConflict, gin.H{"error": "RENAME_COLLISION", "message": err.Error(), "data": plan})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "RENAME_FAILED", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"dry_run": req.DryRun,
+			"plan":    plan,
+		},
+	})
+}
+
+// DiffReport handles POST /manager/projects/:id/diffreport
+// Compares the form-data appfilters "file1" and "file2" and renders a report
+// ("report": "html" by default, or "markdown", titled by "title"). HTML
+// reports embed thumbnails of the project's stored icons.
+func (h *IconIOHandler) DiffReport(c *gin.Context) {
+	token, ok := oputils.GetTokenFromContext(c)
+	if !ok {
+		var err error
+		token, err = oputils.ExtractBearerToken(c)
+		if err != nil {
+			oputils.RespondWithAuthError(c, err)
+			return
+		}
+	}
+
+	projectID, err := strconv.ParseUint(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_PROJECT_ID", "message": "project id must be uint"})
+		return
+	}
+
+	format := operation.ReportFormat(c.DefaultPostForm("report", string(operation.ReportHTML)))
+	if format != operation.ReportHTML && format != operation.ReportMarkdown {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REPORT", "message": "report must be html or markdown"})
+		return
+	}
+
+	var files [2][]globals.Item
+	for i, field := range []string{"file1", "file2"} {
+		header, err := c.FormFile(field)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "MISSING_FILE", "message": "missing " + field})
+			return
+		}
+		f, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "OPEN_FAILED", "message": err.Error()})
+			return
+		}
+		files[i], err = reader.ParseFromReader(f)
+		f.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "PARSE_FAILED", "message": field + ": " + err.Error()})
+			return
+		}
+	}
+
+	thumbnails, err := h.service.ProjectThumbnails(c.Request.Context(), token, projectID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "FORBIDDEN", "message": err.Error()})
+		return
+	}
+
+	diff := operation.DiffItems(files[0], files[1], operation.DiffOptions{
+		NormalizeComponents: c.PostForm("normalize") == "true",
+	})
+	var report strings.Builder
+	opts := operation.DiffReportOptions{Title: c.PostForm("title"), Thumbnails: thumbnails}
+	if err := operation.RenderDiffReport(&report, diff, format, opts); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "REPORT_FAILED", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"format": format,
+			"report": report.String(),
+			"summary": gin.H{
+				"added_count":     len(diff.OnlyInSecond),
+				"removed_count":   len(diff.OnlyInFirst),
+				"changed_count":   len(diff.Changed),
+				"unchanged_count": len(diff.Common),
+			},
+		},
+	})
+}
@@ -0,0 +1,154 @@
+package manager
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"circle-center/globals/mail"
+	svc "circle-center/panel/manager/svc"
+)
+
+// InvitationHandler wires HTTP to InvitationService
+type InvitationHandler struct {
+	service *svc.InvitationService
+}
+
+// NewInvitationHandler builds an invitation handler
+func NewInvitationHandler(db *sql.DB, mailService *mail.MailService) *InvitationHandler {
+	return &InvitationHandler{service: svc.NewInvitationService(db, mailService)}
+}
+
+// Create handles POST /manager/projects/:id/invitations
+func (h *InvitationHandler) Create(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+
+	var req svc.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REQUEST", "message": err.Error()})
+		return
+	}
+
+	info, err := h.service.CreateInvitation(c.Request.Context(), caller, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CREATE_INVITATION_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Invitation created successfully", "data": info})
+}
+
+// List handles GET /manager/projects/:id/invitations
+func (h *InvitationHandler) List(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.ListInvitations(c.Request.Context(), caller.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "LIST_INVITATIONS_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": items})
+}
+
+// Resend handles POST /manager/projects/:id/invitations/:inviteId/resend
+func (h *InvitationHandler) Resend(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+	inviteID, err := strconv.ParseUint(strings.TrimSpace(c.Param("inviteId")), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_INVITATION_ID", "message": "invitation id must be uint"})
+		return
+	}
+
+	info, err := h.service.ResendInvitation(c.Request.Context(), caller, inviteID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "RESEND_INVITATION_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Invitation resent", "data": info})
+}
+
+// Revoke handles DELETE /manager/projects/:id/invitations/:inviteId
+func (h *InvitationHandler) Revoke(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+	inviteID, err := strconv.ParseUint(strings.TrimSpace(c.Param("inviteId")), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_INVITATION_ID", "message": "invitation id must be uint"})
+		return
+	}
+
+	if err := h.service.RevokeInvitation(c.Request.Context(), caller, inviteID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "REVOKE_INVITATION_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// ListMine handles GET /manager/invitations
+func (h *InvitationHandler) ListMine(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.ListMyInvitations(c.Request.Context(), caller)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "LIST_INVITATIONS_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": items})
+}
+
+// Accept handles POST /manager/invitations/accept
+func (h *InvitationHandler) Accept(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+
+	var req svc.RespondInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REQUEST", "message": err.Error()})
+		return
+	}
+
+	result, err := h.service.AcceptInvitation(c.Request.Context(), caller, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ACCEPT_INVITATION_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Invitation accepted", "data": result})
+}
+
+// Decline handles POST /manager/invitations/decline
+func (h *InvitationHandler) Decline(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+
+	var req svc.RespondInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REQUEST", "message": err.Error()})
+		return
+	}
+
+	if err := h.service.DeclineInvitation(c.Request.Context(), caller, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "DECLINE_INVITATION_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
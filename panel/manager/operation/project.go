@@ -94,6 +94,16 @@ func (h *ProjectHandler) ListProjects(c *gin.Context) {
 		}
 	}
 
+	if c.Query("deleted") == "true" {
+		list, err := h.service.ListDeletedProjects(c.Request.Context(), token)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "LIST_PROJECTS_FAILED", "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"success": true, "message": "ok", "data": list})
+		return
+	}
+
 	list, err := h.service.ListProjects(c.Request.Context(), token, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "LIST_PROJECTS_FAILED", "message": err.Error()})
@@ -193,10 +203,86 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Project deleted successfully",
+		"message": "Project deleted; it can be restored until it is purged",
 	})
 }
 
+// ArchiveProject handles POST /manager/projects/:id/archive
+func (h *ProjectHandler) ArchiveProject(c *gin.Context) {
+	token, ok := oputils.GetTokenFromContext(c)
+	if !ok {
+		var err error
+		token, err = oputils.ExtractBearerToken(c)
+		if err != nil {
+			oputils.RespondWithAuthError(c, err)
+			return
+		}
+	}
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_PROJECT_ID", "message": "project id must be uint"})
+		return
+	}
+
+	if err := h.service.ArchiveProject(c.Request.Context(), token, projectID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ARCHIVE_PROJECT_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Project archived"})
+}
+
+// UnarchiveProject handles POST /manager/projects/:id/unarchive
+func (h *ProjectHandler) UnarchiveProject(c *gin.Context) {
+	token, ok := oputils.GetTokenFromContext(c)
+	if !ok {
+		var err error
+		token, err = oputils.ExtractBearerToken(c)
+		if err != nil {
+			oputils.RespondWithAuthError(c, err)
+			return
+		}
+	}
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_PROJECT_ID", "message": "project id must be uint"})
+		return
+	}
+
+	if err := h.service.UnarchiveProject(c.Request.Context(), token, projectID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "UNARCHIVE_PROJECT_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Project unarchived"})
+}
+
+// RestoreProject handles POST /manager/projects/:id/restore
+func (h *ProjectHandler) RestoreProject(c *gin.Context) {
+	token, ok := oputils.GetTokenFromContext(c)
+	if !ok {
+		var err error
+		token, err = oputils.ExtractBearerToken(c)
+		if err != nil {
+			oputils.RespondWithAuthError(c, err)
+			return
+		}
+	}
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_PROJECT_ID", "message": "project id must be uint"})
+		return
+	}
+
+	resp, err := h.service.RestoreProject(c.Request.Context(), token, projectID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "RESTORE_PROJECT_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Project restored", "data": resp})
+}
+
 // AssignProjectRole handles POST /manager/projects/:id/roles
 func (h *ProjectHandler) AssignProjectRole(c *gin.Context) {
 	token, ok := oputils.GetTokenFromContext(c)
@@ -0,0 +1,99 @@
+package manager
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	svc "circle-center/panel/manager/svc"
+)
+
+// TransferHandler wires HTTP to TransferService
+type TransferHandler struct {
+	service *svc.TransferService
+}
+
+// NewTransferHandler builds an ownership transfer handler
+func NewTransferHandler(db *sql.DB) *TransferHandler {
+	return &TransferHandler{service: svc.NewTransferService(db)}
+}
+
+// Get handles GET /manager/projects/:id/transfer
+func (h *TransferHandler) Get(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+
+	info, err := h.service.GetTransfer(c.Request.Context(), caller.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GET_TRANSFER_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": info})
+}
+
+// Propose handles POST /manager/projects/:id/transfer
+func (h *TransferHandler) Propose(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+
+	var req svc.ProposeTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REQUEST", "message": err.Error()})
+		return
+	}
+
+	info, err := h.service.ProposeTransfer(c.Request.Context(), caller, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "PROPOSE_TRANSFER_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Ownership transfer proposed", "data": info})
+}
+
+// Cancel handles DELETE /manager/projects/:id/transfer
+func (h *TransferHandler) Cancel(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelTransfer(c.Request.Context(), caller); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CANCEL_TRANSFER_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// Accept handles POST /manager/projects/:id/transfer/accept
+func (h *TransferHandler) Accept(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+
+	info, err := h.service.AcceptTransfer(c.Request.Context(), caller)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ACCEPT_TRANSFER_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Project ownership transferred", "data": info})
+}
+
+// Decline handles POST /manager/projects/:id/transfer/decline
+func (h *TransferHandler) Decline(c *gin.Context) {
+	caller, ok := requireCaller(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeclineTransfer(c.Request.Context(), caller); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "DECLINE_TRANSFER_FAILED", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
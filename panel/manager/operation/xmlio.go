@@ -2,6 +2,7 @@ package manager
 
 import (
 	"database/sql"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -9,6 +10,7 @@ import (
 
 	svc "circle-center/panel/manager/svc"
 	mutils "circle-center/panel/manager/utils"
+	"circle-center/processor/operation"
 )
 
 // XMLIOHandler exposes endpoints for XML parse (preview) and import (confirm)
@@ -89,3 +91,87 @@ func (h *XMLIOHandler) ConfirmImport(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"status": "success", "summary": summary})
 }
+
+// ExportPackFile handles GET /manager/projects/:id/packfile?type=icon_pack|drawable
+// to generate icon_pack.xml or drawable.xml from the project's icons. The
+// optional "all" query names the array listing every drawable ("all" by
+// default, empty to leave it out).
+func (h *XMLIOHandler) ExportPackFile(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid project id"})
+		return
+	}
+
+	opts := operation.ConvertOptions{AllArray: operation.DefaultAllArray}
+	if all, ok := c.GetQuery("all"); ok {
+		opts.AllArray = all
+	}
+
+	content, err := h.service.ExportPackFile(c.Request.Context(), projectID, c.DefaultQuery("type", "drawable"), opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "content": content})
+}
+
+// DiffProject handles POST /manager/projects/:id/diff which accepts form-data
+// with a "file" field holding an appfilter.xml, appmap.xml or
+// theme_resources.xml, and compares it with the project's icons. It responds
+// with the shape of the processor's /diffappfilters: the file is the first
+// side, the project the second. Optional fields: "normalize" set to "true"
+// matches normalised components, "ignore_names" set to "true" compares
+// drawables only.
+func (h *XMLIOHandler) DiffProject(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "invalid project id"})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": "missing file field"})
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "cannot open file: " + err.Error()})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error", "message": "cannot read file: " + err.Error()})
+		return
+	}
+
+	opts := operation.DiffOptions{
+		NormalizeComponents: c.PostForm("normalize") == "true",
+		IgnoreAppNames:      c.PostForm("ignore_names") == "true",
+	}
+	res, err := h.service.DiffProject(c.Request.Context(), projectID, data, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error", "message": err.Error()})
+		return
+	}
+
+	diff := res.Diff
+	c.JSON(http.StatusOK, gin.H{
+		"status":         "success",
+		"file_type":      res.FileType,
+		"only_in_first":  diff.OnlyInFirst,
+		"only_in_second": diff.OnlyInSecond,
+		"common":         diff.Common,
+		"changed":        diff.Changed,
+		"summary": gin.H{
+			"first_count":       res.FileCount,
+			"second_count":      res.ProjectCount,
+			"only_first_count":  len(diff.OnlyInFirst),
+			"only_second_count": len(diff.OnlyInSecond),
+			"common_count":      len(diff.Common),
+			"changed_count":     len(diff.Changed),
+		},
+	})
+}
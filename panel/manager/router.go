@@ -1,25 +1,81 @@
 package manager
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"circle-center/globals/mail"
 	accountsvc "circle-center/panel/account/svc"
 	"circle-center/panel/account/utils"
 	op "circle-center/panel/manager/operation"
+	svc "circle-center/panel/manager/svc"
 )
 
+// routeAccess is the permission table of the manager routes, keyed by method
+// and path relative to /manager. Every route must be listed; the access
+// middleware refuses the others.
+var routeAccess = map[string]op.RouteAccess{
+	"GET /projects":                                   {Permission: svc.PermAuthenticated},
+	"POST /projects":                                  {Permission: svc.PermAuthenticated},
+	"GET /projects/:id":                               {Permission: svc.PermProjectRead},
+	"PUT /projects/:id":                               {Permission: svc.PermProjectWrite},
+	"DELETE /projects/:id":                            {Permission: svc.PermProjectDelete},
+	"POST /projects/:id/archive":                      {Permission: svc.PermProjectArchive},
+	"POST /projects/:id/unarchive":                    {Permission: svc.PermProjectArchive},
+	"POST /projects/:id/restore":                      {Permission: svc.PermProjectRestore},
+	"GET /projects/:id/roles":                         {Permission: svc.PermMembersRead},
+	"POST /projects/:id/roles":                        {Permission: svc.PermMembersManage},
+	"DELETE /projects/:id/roles/:userId":              {Permission: svc.PermMembersManage},
+	"GET /projects/:id/invitations":                   {Permission: svc.PermMembersManage},
+	"POST /projects/:id/invitations":                  {Permission: svc.PermMembersManage},
+	"POST /projects/:id/invitations/:inviteId/resend": {Permission: svc.PermMembersManage},
+	"DELETE /projects/:id/invitations/:inviteId":      {Permission: svc.PermMembersManage},
+	"GET /projects/:id/transfer":                      {Permission: svc.PermProjectRead},
+	"POST /projects/:id/transfer":                     {Permission: svc.PermProjectTransfer},
+	"DELETE /projects/:id/transfer":                   {Permission: svc.PermProjectTransfer},
+	"POST /projects/:id/transfer/accept":              {Permission: svc.PermProjectRead},
+	"POST /projects/:id/transfer/decline":             {Permission: svc.PermProjectRead},
+	"GET /invitations":                                {Permission: svc.PermAuthenticated},
+	"POST /invitations/accept":                        {Permission: svc.PermAuthenticated},
+	"POST /invitations/decline":                       {Permission: svc.PermAuthenticated},
+	"GET /projects/:id/tokens":                        {Permission: svc.PermTokensManage},
+	"POST /projects/:id/tokens":                       {Permission: svc.PermTokensManage},
+	"DELETE /projects/:id/tokens/:tokenId":            {Permission: svc.PermTokensManage},
+	"POST /icons/parse":                               {Permission: svc.PermAuthenticated},
+	"POST /icons/sync":                                {Permission: svc.PermAuthenticated},
+	"POST /icons/import":                              {Permission: svc.PermIconsWrite, ProjectID: op.FromBodyProjectID},
+	"GET /projects/:id/packfile":                      {Permission: svc.PermIconsRead},
+	"POST /projects/:id/diff":                         {Permission: svc.PermIconsRead},
+	"POST /projects/:id/diffreport":                   {Permission: svc.PermIconsRead},
+	"GET /projects/:id/icons":                         {Permission: svc.PermIconsRead},
+	"GET /projects/:id/icons/stats":                   {Permission: svc.PermIconsRead},
+	"POST /projects/:id/icons/rename":                 {Permission: svc.PermIconsWrite},
+	"GET /projects/:id/icons/:iconId":                 {Permission: svc.PermIconsRead},
+	"POST /projects/:id/icons":                        {Permission: svc.PermIconsWrite},
+	"PUT /projects/:id/icons/:iconId":                 {Permission: svc.PermIconsWrite},
+	"DELETE /projects/:id/icons/:iconId":              {Permission: svc.PermIconsWrite},
+	"GET /icons/*relpath":                             {Permission: svc.PermIconsRead, ProjectID: op.FromIconPath},
+	"POST /icons/:projectId/upload":                   {Permission: svc.PermIconsWrite, ProjectID: op.FromParamProjectID},
+}
+
 // RegisterRoutes registers all manager-related routes
-func RegisterRoutes(r *gin.RouterGroup, db *sql.DB, authClient *accountsvc.AuthClient) {
+func RegisterRoutes(r *gin.RouterGroup, db *sql.DB, mailService *mail.MailService, authClient *accountsvc.AuthClient) {
 	projectHandler := op.NewProjectHandler(db, authClient)
 	requestHandler := op.NewRequestHandler(db)
 	tokenHandler := op.NewTokenHandler(db)
+	invitationHandler := op.NewInvitationHandler(db, mailService)
+	transferHandler := op.NewTransferHandler(db)
 	xmlioHandler := op.NewXMLIOHandler(db)
+	componentHandler := op.NewComponentHandler(db)
 	iconHandler := op.NewIconHandler(db)
 	iconioHandler := op.NewIconIOHandler(db, authClient)
+	accessHandler := op.NewAccessHandler(db, authClient, routeAccess)
 
 	manager := r.Group("/manager")
+	manager.Use(accessHandler.Middleware(manager.BasePath()))
 	{
 		manager.GET("/projects",
 			utils.ExtractBearerTokenMiddleware(),
@@ -51,6 +107,21 @@ func RegisterRoutes(r *gin.RouterGroup, db *sql.DB, authClient *accountsvc.AuthC
 			projectHandler.DeleteProject,
 		)
 
+		manager.POST("/projects/:id/archive",
+			utils.ExtractBearerTokenMiddleware(),
+			projectHandler.ArchiveProject,
+		)
+
+		manager.POST("/projects/:id/unarchive",
+			utils.ExtractBearerTokenMiddleware(),
+			projectHandler.UnarchiveProject,
+		)
+
+		manager.POST("/projects/:id/restore",
+			utils.ExtractBearerTokenMiddleware(),
+			projectHandler.RestoreProject,
+		)
+
 		manager.POST("/projects/:id/roles",
 			utils.ExtractBearerTokenMiddleware(),
 			projectHandler.AssignProjectRole,
@@ -66,6 +137,55 @@ func RegisterRoutes(r *gin.RouterGroup, db *sql.DB, authClient *accountsvc.AuthC
 			projectHandler.DeleteProjectCollaborator,
 		)
 
+		manager.GET("/projects/:id/invitations",
+			utils.ExtractBearerTokenMiddleware(),
+			invitationHandler.List,
+		)
+		manager.POST("/projects/:id/invitations",
+			utils.ExtractBearerTokenMiddleware(),
+			invitationHandler.Create,
+		)
+		manager.POST("/projects/:id/invitations/:inviteId/resend",
+			utils.ExtractBearerTokenMiddleware(),
+			invitationHandler.Resend,
+		)
+		manager.DELETE("/projects/:id/invitations/:inviteId",
+			utils.ExtractBearerTokenMiddleware(),
+			invitationHandler.Revoke,
+		)
+		manager.GET("/projects/:id/transfer",
+			utils.ExtractBearerTokenMiddleware(),
+			transferHandler.Get,
+		)
+		manager.POST("/projects/:id/transfer",
+			utils.ExtractBearerTokenMiddleware(),
+			transferHandler.Propose,
+		)
+		manager.DELETE("/projects/:id/transfer",
+			utils.ExtractBearerTokenMiddleware(),
+			transferHandler.Cancel,
+		)
+		manager.POST("/projects/:id/transfer/accept",
+			utils.ExtractBearerTokenMiddleware(),
+			transferHandler.Accept,
+		)
+		manager.POST("/projects/:id/transfer/decline",
+			utils.ExtractBearerTokenMiddleware(),
+			transferHandler.Decline,
+		)
+		manager.GET("/invitations",
+			utils.ExtractBearerTokenMiddleware(),
+			invitationHandler.ListMine,
+		)
+		manager.POST("/invitations/accept",
+			utils.ExtractBearerTokenMiddleware(),
+			invitationHandler.Accept,
+		)
+		manager.POST("/invitations/decline",
+			utils.ExtractBearerTokenMiddleware(),
+			invitationHandler.Decline,
+		)
+
 		manager.POST("/projects/:id/tokens",
 			utils.ExtractBearerTokenMiddleware(),
 			tokenHandler.Create,
@@ -79,10 +199,26 @@ func RegisterRoutes(r *gin.RouterGroup, db *sql.DB, authClient *accountsvc.AuthC
 			utils.ExtractBearerTokenMiddleware(),
 			xmlioHandler.ParsePreview,
 		)
+		manager.POST("/icons/sync",
+			utils.ExtractBearerTokenMiddleware(),
+			componentHandler.SyncAppFilter,
+		)
 		manager.POST("/icons/import",
 			utils.ExtractBearerTokenMiddleware(),
 			xmlioHandler.ConfirmImport,
 		)
+		manager.GET("/projects/:id/packfile",
+			utils.ExtractBearerTokenMiddleware(),
+			xmlioHandler.ExportPackFile,
+		)
+		manager.POST("/projects/:id/diff",
+			utils.ExtractBearerTokenMiddleware(),
+			xmlioHandler.DiffProject,
+		)
+		manager.POST("/projects/:id/diffreport",
+			utils.ExtractBearerTokenMiddleware(),
+			iconioHandler.DiffReport,
+		)
 
 		// Icon management endpoints
 		manager.GET("/projects/:id/icons",
@@ -93,6 +229,10 @@ func RegisterRoutes(r *gin.RouterGroup, db *sql.DB, authClient *accountsvc.AuthC
 			utils.ExtractBearerTokenMiddleware(),
 			iconHandler.GetIconStats,
 		)
+		manager.POST("/projects/:id/icons/rename",
+			utils.ExtractBearerTokenMiddleware(),
+			iconioHandler.RenameDrawables,
+		)
 		manager.GET("/projects/:id/icons/:iconId",
 			utils.ExtractBearerTokenMiddleware(),
 			iconHandler.GetIcon,
@@ -125,3 +265,14 @@ func RegisterRoutes(r *gin.RouterGroup, db *sql.DB, authClient *accountsvc.AuthC
 		request.POST("/request", requestHandler.UploadRequest)
 	}
 }
+
+// StartProjectPurger runs the purge of expired deleted projects in the
+// background every interval until ctx is done.
+func StartProjectPurger(ctx context.Context, db *sql.DB, interval time.Duration) error {
+	purger, err := svc.NewProjectPurger(db)
+	if err != nil {
+		return err
+	}
+	go purger.Run(ctx, interval)
+	return nil
+}
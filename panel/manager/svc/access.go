@@ -0,0 +1,202 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	accountsvc "circle-center/panel/account/svc"
+	managerdb "circle-center/repository/sqlc/manager"
+)
+
+// Permission names an action on a project that some roles may perform.
+type Permission string
+
+const (
+	// PermAuthenticated only requires a valid token; the route is not
+	// scoped to a project.
+	PermAuthenticated   Permission = "authenticated"
+	PermProjectRead     Permission = "project:read"
+	PermProjectWrite    Permission = "project:write"
+	PermProjectDelete   Permission = "project:delete"
+	PermProjectTransfer Permission = "project:transfer"
+	PermProjectArchive  Permission = "project:archive"
+	PermProjectRestore  Permission = "project:restore"
+	PermMembersRead     Permission = "members:read"
+	PermMembersManage   Permission = "members:manage"
+	PermIconsRead       Permission = "icons:read"
+	PermIconsWrite      Permission = "icons:write"
+	PermTokensManage    Permission = "tokens:manage"
+)
+
+// permissionRoles maps each project permission to the lowest role granted it.
+var permissionRoles = map[Permission]managerdb.UserProjectRolesRole{
+	PermProjectRead:     managerdb.UserProjectRolesRoleViewer,
+	PermProjectWrite:    managerdb.UserProjectRolesRoleAdmin,
+	PermProjectDelete:   managerdb.UserProjectRolesRoleOwner,
+	PermProjectTransfer: managerdb.UserProjectRolesRoleOwner,
+	PermProjectArchive:  managerdb.UserProjectRolesRoleAdmin,
+	PermProjectRestore:  managerdb.UserProjectRolesRoleOwner,
+	PermMembersRead:     managerdb.UserProjectRolesRoleViewer,
+	PermMembersManage:   managerdb.UserProjectRolesRoleAdmin,
+	PermIconsRead:       managerdb.UserProjectRolesRoleViewer,
+	PermIconsWrite:      managerdb.UserProjectRolesRoleEditor,
+	PermTokensManage:    managerdb.UserProjectRolesRoleAdmin,
+}
+
+// archivedDenied lists the permissions refused while a project is archived,
+// which leaves it read-only.
+var archivedDenied = map[Permission]bool{
+	PermProjectWrite:  true,
+	PermMembersManage: true,
+	PermIconsWrite:    true,
+	PermTokensManage:  true,
+}
+
+// roleRanks orders roles from least to most privileged.
+var roleRanks = map[managerdb.UserProjectRolesRole]int{
+	managerdb.UserProjectRolesRoleViewer: 1,
+	managerdb.UserProjectRolesRoleEditor: 2,
+	managerdb.UserProjectRolesRoleAdmin:  3,
+	managerdb.UserProjectRolesRoleOwner:  4,
+}
+
+// RoleAtLeast reports whether role is min or a more privileged one.
+func RoleAtLeast(role, min managerdb.UserProjectRolesRole) bool {
+	return roleRanks[role] > 0 && roleRanks[role] >= roleRanks[min]
+}
+
+// RoleAllows reports whether role grants perm.
+func RoleAllows(role managerdb.UserProjectRolesRole, perm Permission) bool {
+	min, ok := permissionRoles[perm]
+	return ok && RoleAtLeast(role, min)
+}
+
+// Access errors; ErrForbidden is also returned to callers without a role.
+var (
+	ErrUnauthenticated = errors.New("invalid token")
+	ErrProjectNotFound = errors.New("project not found")
+	ErrForbidden       = errors.New("forbidden")
+	ErrProjectArchived = errors.New("project is archived")
+)
+
+// Caller is an authenticated user and, for project routes, their role in
+// the project.
+type Caller struct {
+	UserID    uint64
+	Username  string
+	Email     string
+	ProjectID uint64
+	Role      managerdb.UserProjectRolesRole
+}
+
+// Can reports whether the caller's role grants perm.
+func (c *Caller) Can(perm Permission) bool {
+	return RoleAllows(c.Role, perm)
+}
+
+// AccessService resolves callers and their project roles.
+type AccessService struct {
+	queries *managerdb.Queries
+	auth    *accountsvc.AuthClient
+}
+
+// NewAccessService constructs an AccessService.
+func NewAccessService(db *sql.DB, authClient *accountsvc.AuthClient) *AccessService {
+	return &AccessService{queries: managerdb.New(db), auth: authClient}
+}
+
+// Authenticate validates token and returns the caller without a project.
+func (s *AccessService) Authenticate(ctx context.Context, token string) (*Caller, error) {
+	return authenticate(ctx, s.auth, token)
+}
+
+// ProjectRole returns the role of userID in the project. The owner is taken
+// from projects.owner_user_id, everyone else from user_project_roles.
+func (s *AccessService) ProjectRole(ctx context.Context, userID, projectID uint64) (managerdb.UserProjectRolesRole, error) {
+	return projectRole(ctx, s.queries, userID, projectID)
+}
+
+// Authorize validates token and checks that the caller's role in the project
+// grants perm.
+func (s *AccessService) Authorize(ctx context.Context, token string, projectID uint64, perm Permission) (*Caller, error) {
+	if perm == PermAuthenticated {
+		return s.Authenticate(ctx, token)
+	}
+	return authorize(ctx, s.auth, s.queries, token, projectID, perm)
+}
+
+// authenticate validates token.
+func authenticate(ctx context.Context, auth *accountsvc.AuthClient, token string) (*Caller, error) {
+	if auth == nil {
+		return nil, fmt.Errorf("auth client not initialized")
+	}
+	claims, err := auth.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	return &Caller{UserID: claims.UserID, Username: claims.Username, Email: claims.Email}, nil
+}
+
+// authorize validates token and checks that the caller's role in the project
+// grants perm. Deleted projects are not found but for their restore, and
+// archived ones refuse the permissions in archivedDenied. Services that check
+// access themselves go through it, so they apply the same rules as the route
+// middleware.
+func authorize(ctx context.Context, auth *accountsvc.AuthClient, queries *managerdb.Queries, token string, projectID uint64, perm Permission) (*Caller, error) {
+	caller, err := authenticate(ctx, auth, token)
+	if err != nil {
+		return nil, err
+	}
+	project, role, err := projectAccess(ctx, queries, caller.UserID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	caller.ProjectID = projectID
+	caller.Role = role
+	// A deleted project only exists for its restore.
+	if project.DeletedAt.Valid && perm != PermProjectRestore {
+		return nil, ErrProjectNotFound
+	}
+	if !caller.Can(perm) {
+		return nil, ErrForbidden
+	}
+	if project.ArchivedAt.Valid && archivedDenied[perm] {
+		return nil, ErrProjectArchived
+	}
+	return caller, nil
+}
+
+// projectRole resolves the role of userID in the project.
+func projectRole(ctx context.Context, queries *managerdb.Queries, userID, projectID uint64) (managerdb.UserProjectRolesRole, error) {
+	_, role, err := projectAccess(ctx, queries, userID, projectID)
+	return role, err
+}
+
+// projectAccess loads the project and resolves the role of userID in it.
+func projectAccess(ctx context.Context, queries *managerdb.Queries, userID, projectID uint64) (managerdb.Project, managerdb.UserProjectRolesRole, error) {
+	p, err := queries.GetProjectByID(ctx, projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, "", ErrProjectNotFound
+	}
+	if err != nil {
+		return p, "", err
+	}
+	if p.OwnerUserID == userID {
+		return p, managerdb.UserProjectRolesRoleOwner, nil
+	}
+	upr, err := queries.GetUserProjectRole(ctx, managerdb.GetUserProjectRoleParams{UserID: userID, ProjectID: projectID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, "", ErrForbidden
+	}
+	if err != nil {
+		return p, "", err
+	}
+	// Ownership is projects.owner_user_id alone; an owner row of anyone
+	// else is left over and grants no more than admin.
+	if upr.Role == managerdb.UserProjectRolesRoleOwner {
+		return p, managerdb.UserProjectRolesRoleAdmin, nil
+	}
+	return p, upr.Role, nil
+}
@@ -0,0 +1,102 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"circle-center/processor/operation"
+	"circle-center/reader"
+	managerdb "circle-center/repository/sqlc/manager"
+)
+
+// componentsPerPackage caps how many stored components are read per package
+// when building an index.
+const componentsPerPackage = 500
+
+// ComponentService builds component indexes from the icons and request items
+// stored in the database, the only sources trusted for activity resolution.
+type ComponentService struct {
+	queries *managerdb.Queries
+}
+
+// NewComponentService constructs a new ComponentService
+func NewComponentService(db *sql.DB) *ComponentService {
+	return &ComponentService{queries: managerdb.New(db)}
+}
+
+// Index returns a reader.ComponentIndex covering the given packages, ready to
+// pass to operation.SyncIconsToAppFilter. It is built from the database on
+// every call, so updated or deleted icons are never served from a stale copy.
+func (s *ComponentService) Index(ctx context.Context, packages []string) (*reader.ComponentIndex, error) {
+	index := reader.NewComponentIndex(len(packages) * componentsPerPackage)
+	seen := make(map[string]struct{}, len(packages))
+	for _, pkg := range packages {
+		pkg = strings.TrimSpace(pkg)
+		if _, ok := seen[pkg]; ok || pkg == "" {
+			continue
+		}
+		seen[pkg] = struct{}{}
+
+		components, err := s.queries.ListComponentsByPackage(ctx, managerdb.ListComponentsByPackageParams{
+			Pkg:   pkg,
+			Pkg_2: pkg,
+			Limit: componentsPerPackage,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list components for %s: %w", pkg, err)
+		}
+		for _, component := range components {
+			index.Add(component)
+		}
+	}
+	return index, nil
+}
+
+// SyncAppFilter unpacks a ZIP of icons named by package and adds an
+// appfilter item for every icon appFilter has none for, as
+// operation.SyncIconsToAppFilter does. Activities are resolved from an index
+// of the missing packages only. It returns the updated appfilter.
+func (s *ComponentService) SyncAppFilter(ctx context.Context, icons io.ReaderAt, size int64, appFilter []byte) ([]byte, *operation.SyncReport, error) {
+	tmpDir, err := os.MkdirTemp("", "syncappfilter_*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	appFilterPath := filepath.Join(tmpDir, "appfilter.xml")
+	if err := os.WriteFile(appFilterPath, appFilter, 0o644); err != nil {
+		return nil, nil, fmt.Errorf("write appfilter: %w", err)
+	}
+	iconDir := filepath.Join(tmpDir, "icons")
+	if err := os.Mkdir(iconDir, 0o755); err != nil {
+		return nil, nil, fmt.Errorf("create icon dir: %w", err)
+	}
+	iconDir, err = operation.UnpackIconArchive(icons, size, iconDir, operation.DefaultUnpackLimits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	missing, err := operation.FindMissingIcons(iconDir, appFilterPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	index, err := s.Index(ctx, missing)
+	if err != nil {
+		return nil, nil, err
+	}
+	report, err := operation.SyncIconsToAppFilter(iconDir, appFilterPath, index)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updated, err := os.ReadFile(appFilterPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read appfilter: %w", err)
+	}
+	return updated, report, nil
+}
@@ -317,3 +317,25 @@ func (s *IconService) DeleteIcon(ctx context.Context, projectID, iconID uint64)
 func (s *IconService) GetIconStats(ctx context.Context, projectID uint64) (managerdb.GetIconStatsRow, error) {
 	return s.queries.GetIconStats(ctx, projectID)
 }
+
+// iconPageSize is how many icon rows listAllIcons reads per query.
+const iconPageSize = 500
+
+// listAllIcons returns every icon row of a project, newest first.
+func listAllIcons(ctx context.Context, queries *managerdb.Queries, projectID uint64) ([]managerdb.Icon, error) {
+	var icons []managerdb.Icon
+	for offset := int32(0); ; offset += iconPageSize {
+		page, err := queries.ListProjectIcons(ctx, managerdb.ListProjectIconsParams{
+			ProjectID: projectID,
+			Limit:     iconPageSize,
+			Offset:    offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list icons: %w", err)
+		}
+		icons = append(icons, page...)
+		if len(page) < iconPageSize {
+			return icons, nil
+		}
+	}
+}
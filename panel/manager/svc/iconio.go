@@ -3,20 +3,25 @@ package manager
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"path/filepath"
-	"strconv"
+	"os"
 	"strings"
 
 	"github.com/h2non/filetype"
 
+	"circle-center/globals"
 	"circle-center/globals/storage"
 	accountsvc "circle-center/panel/account/svc"
+	mutils "circle-center/panel/manager/utils"
+	"circle-center/processor/operation"
+	"circle-center/reader"
 	managerdb "circle-center/repository/sqlc/manager"
 )
 
 // IconIOService handles icon file upload and secure retrieval.
 type IconIOService struct {
+	db       *sql.DB
 	queries  *managerdb.Queries
 	auth     *accountsvc.AuthClient
 	storage  *storage.IconStorage
@@ -30,6 +35,7 @@ func NewIconIOService(db *sql.DB, authClient *accountsvc.AuthClient) (*IconIOSer
 		return nil, err
 	}
 	return &IconIOService{
+		db:       db,
 		queries:  managerdb.New(db),
 		auth:     authClient,
 		storage:  st,
@@ -40,9 +46,6 @@ func NewIconIOService(db *sql.DB, authClient *accountsvc.AuthClient) (*IconIOSer
 // ValidateAndSaveIcon validates auth, ensures the icon record exists, and saves file to storage.
 // Returns the stored relative path (e.g., "icons/{project_id}/{drawable}.png").
 func (s *IconIOService) ValidateAndSaveIcon(ctx context.Context, token string, projectID uint64, componentInfo string, fileBytes []byte) (string, error) {
-	if s.auth == nil {
-		return "", fmt.Errorf("auth client not initialized")
-	}
 	if len(fileBytes) == 0 {
 		return "", fmt.Errorf("empty file")
 	}
@@ -50,19 +53,9 @@ func (s *IconIOService) ValidateAndSaveIcon(ctx context.Context, token string, p
 		return "", fmt.Errorf("file too large: max %d bytes", s.maxBytes)
 	}
 
-	// Validate token and load claims
-	claims, err := s.auth.ValidateToken(ctx, token)
-	if err != nil {
-		return "", fmt.Errorf("invalid token: %w", err)
-	}
-
-	// Ensure project exists and is owned by current user
-	p, err := s.queries.GetProjectByID(ctx, projectID)
-	if err != nil {
-		return "", fmt.Errorf("project not found")
-	}
-	if p.OwnerUserID != claims.UserID {
-		return "", fmt.Errorf("forbidden")
+	// Editors and above may upload
+	if _, err := authorize(ctx, s.auth, s.queries, token, projectID, PermIconsWrite); err != nil {
+		return "", err
 	}
 
 	comp := strings.TrimSpace(componentInfo)
@@ -97,41 +90,119 @@ func (s *IconIOService) ValidateAndSaveIcon(ctx context.Context, token string, p
 	return rel, nil
 }
 
-// GetIconAbsolutePathSecure validates token and ownership based on relpath and returns absolute path.
-// relpath must be like: icons/{project_id}/...  This method ensures the requester owns the project.
+// GetIconAbsolutePathSecure validates token and project membership based on relpath and returns absolute path.
+// relpath must be like: icons/{project_id}/...  This method ensures the requester may read the project's icons.
 func (s *IconIOService) GetIconAbsolutePathSecure(ctx context.Context, token string, relpath string) (string, error) {
-	if s.auth == nil {
-		return "", fmt.Errorf("auth client not initialized")
+	// Clean the path and parse the project id from it
+	norm, pid, err := mutils.ParseIconPath(relpath)
+	if err != nil {
+		return "", err
 	}
 
-	claims, err := s.auth.ValidateToken(ctx, token)
-	if err != nil {
-		return "", fmt.Errorf("invalid token: %w", err)
+	// Any project member may read
+	if _, err := authorize(ctx, s.auth, s.queries, token, pid, PermIconsRead); err != nil {
+		return "", err
 	}
 
-	// Normalize and parse project id from path
-	norm := filepath.ToSlash(strings.TrimSpace(relpath))
-	if len(norm) > 0 && norm[0] == '/' {
-		norm = norm[1:]
+	return s.storage.AbsolutePath(norm)
+}
+
+// RenameDrawables applies rename rules to the drawables of a project. The
+// stored icon files and the drawable column of the icon rows change together:
+// rows are updated in a transaction that is committed only once the files are
+// in place, and the files are moved back if anything fails. With dryRun the
+// plan is returned without changing anything; a plan with collisions is
+// returned together with operation.ErrRenameCollision.
+func (s *IconIOService) RenameDrawables(ctx context.Context, token string, projectID uint64, rules []operation.RenameRule, dryRun bool) (*operation.RenamePlan, error) {
+	if _, err := authorize(ctx, s.auth, s.queries, token, projectID, PermIconsWrite); err != nil {
+		return nil, err
+	}
+
+	// Every icon row takes part in the rename, even without a file.
+	icons, err := listAllIcons(ctx, s.queries, projectID)
+	if err != nil {
+		return nil, err
 	}
-	parts := strings.Split(norm, "/")
-	if len(parts) < 2 || parts[0] != "icons" {
-		return "", fmt.Errorf("invalid icon path")
+	items := make([]globals.Item, 0, len(icons))
+	for _, icon := range icons {
+		items = append(items, globals.Item{
+			Component:   icon.ComponentInfo,
+			Drawable:    icon.Drawable,
+			AppName:     icon.Name,
+			PackageName: icon.Pkg,
+		})
 	}
-	pidStr := parts[1]
-	pid, err := strconv.ParseUint(pidStr, 10, 64)
+
+	dir, err := s.storage.ProjectDir(projectID)
 	if err != nil {
-		return "", fmt.Errorf("invalid project id in path")
+		return nil, err
+	}
+	plan, err := operation.PlanDrawableRename(operation.PackFiles{IconDir: dir, Items: items}, rules)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Collisions) > 0 {
+		return plan, operation.ErrRenameCollision
+	}
+	if dryRun || len(plan.Renames) == 0 {
+		return plan, nil
 	}
 
-	// Verify ownership
-	p, err := s.queries.GetProjectByID(ctx, pid)
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	qtx := s.queries.WithTx(tx)
+	mapping := plan.Mapping()
+	for _, icon := range icons {
+		to, ok := mapping[icon.Drawable]
+		if !ok {
+			continue
+		}
+		if err := qtx.UpdateIconDrawable(ctx, managerdb.UpdateIconDrawableParams{
+			Drawable:  to,
+			ID:        icon.ID,
+			ProjectID: projectID,
+		}); err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("update icon %d: %w", icon.ID, err)
+		}
+	}
+
+	txn, err := plan.Apply()
 	if err != nil {
-		return "", fmt.Errorf("project not found")
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("rename files: %w", err)
 	}
-	if p.OwnerUserID != claims.UserID {
-		return "", fmt.Errorf("forbidden")
+	if err := tx.Commit(); err != nil {
+		if rerr := txn.Rollback(); rerr != nil {
+			err = errors.Join(err, rerr)
+		}
+		return nil, err
+	}
+	if err := txn.Commit(); err != nil {
+		return nil, err
 	}
+	return plan, nil
+}
 
-	return s.storage.AbsolutePath(norm)
+// ProjectThumbnails returns a ThumbnailFunc over the project's stored icons,
+// for embedding in diff reports. A project without stored files has none.
+func (s *IconIOService) ProjectThumbnails(ctx context.Context, token string, projectID uint64) (operation.ThumbnailFunc, error) {
+	if _, err := authorize(ctx, s.auth, s.queries, token, projectID, PermIconsRead); err != nil {
+		return nil, err
+	}
+
+	dir, err := s.storage.ProjectDir(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return operation.LocalThumbnails(nil), nil
+	}
+	icons, err := reader.ParseIconDirectory(dir)
+	if err != nil {
+		return nil, err
+	}
+	return operation.LocalThumbnails(icons), nil
 }
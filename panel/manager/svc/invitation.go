@@ -0,0 +1,401 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	configure "circle-center/globals/configure"
+	"circle-center/globals/mail"
+	mutils "circle-center/panel/manager/utils"
+	managerdb "circle-center/repository/sqlc/manager"
+)
+
+// invitationTTL is how long an accept link stays valid; resending renews it.
+const invitationTTL = 7 * 24 * time.Hour
+
+// Invitation errors
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvitationClosed   = errors.New("invitation is no longer pending")
+	ErrInvitationExpired  = errors.New("invitation has expired")
+)
+
+// InvitationService invites people to projects by email. Invitees accept or
+// decline once signed in, so accounts registered after the invite work too.
+type InvitationService struct {
+	queries     *managerdb.Queries
+	db          *sql.DB
+	mailService *mail.MailService
+}
+
+// NewInvitationService constructs an InvitationService
+func NewInvitationService(db *sql.DB, mailService *mail.MailService) *InvitationService {
+	return &InvitationService{queries: managerdb.New(db), db: db, mailService: mailService}
+}
+
+// CreateInvitationRequest represents inviting an email address to a project
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"` // admin|editor|viewer
+}
+
+// RespondInvitationRequest names the invitation to accept or decline, either
+// by the token of the emailed link or by id for the signed-in invitee.
+type RespondInvitationRequest struct {
+	Token string `json:"token,omitempty"`
+	ID    uint64 `json:"id,omitempty"`
+}
+
+// InvitationInfo is a safe projection of an invitation without its token hash
+type InvitationInfo struct {
+	ID              uint64 `json:"id"`
+	ProjectID       uint64 `json:"project_id"`
+	Email           string `json:"email"`
+	Role            string `json:"role"`
+	Status          string `json:"status"`
+	InvitedByUserID uint64 `json:"invited_by_user_id"`
+	ExpiresAt       string `json:"expires_at"`
+	SentAt          string `json:"sent_at"`
+	CreatedAt       string `json:"created_at"`
+	EmailSent       bool   `json:"email_sent"`
+	EmailError      string `json:"email_error,omitempty"`
+}
+
+// PendingInvitationInfo is an invitation waiting for the signed-in user
+type PendingInvitationInfo struct {
+	ID          uint64 `json:"id"`
+	ProjectID   uint64 `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	ProjectSlug string `json:"project_slug"`
+	Role        string `json:"role"`
+	InvitedBy   string `json:"invited_by"`
+	ExpiresAt   string `json:"expires_at"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// InvitationResult is the project membership an accepted invitation led to
+type InvitationResult struct {
+	ProjectID uint64 `json:"project_id"`
+	Role      string `json:"role"`
+}
+
+// CreateInvitation invites an email address to the caller's project with a
+// role below the caller's own, and emails the accept link.
+func (s *InvitationService) CreateInvitation(ctx context.Context, caller *Caller, req *CreateInvitationRequest) (*InvitationInfo, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	var role managerdb.ProjectInvitationsRole
+	switch strings.ToLower(strings.TrimSpace(req.Role)) {
+	case "admin":
+		role = managerdb.ProjectInvitationsRoleAdmin
+	case "editor":
+		role = managerdb.ProjectInvitationsRoleEditor
+	case "viewer":
+		role = managerdb.ProjectInvitationsRoleViewer
+	default:
+		return nil, fmt.Errorf("invalid role: %s", req.Role)
+	}
+	if !outranks(caller.Role, managerdb.UserProjectRolesRole(role)) {
+		return nil, fmt.Errorf("%w: cannot invite as %s", ErrForbidden, role)
+	}
+	if email == strings.ToLower(caller.Email) {
+		return nil, fmt.Errorf("cannot invite yourself")
+	}
+
+	_, err := s.queries.GetPendingProjectInvitation(ctx, managerdb.GetPendingProjectInvitationParams{
+		ProjectID: caller.ProjectID,
+		Email:     email,
+	})
+	if err == nil {
+		return nil, fmt.Errorf("an invitation for %s is already pending; resend it instead", email)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	token, err := mutils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("generate token: %w", err)
+	}
+	res, err := s.queries.CreateProjectInvitation(ctx, managerdb.CreateProjectInvitationParams{
+		ProjectID:       caller.ProjectID,
+		Email:           email,
+		Role:            role,
+		TokenHash:       mutils.HashSHA256Hex(token),
+		InvitedByUserID: caller.UserID,
+		ExpiresAt:       time.Now().Add(invitationTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	id, _ := res.LastInsertId()
+
+	inv, err := s.queries.GetProjectInvitationByID(ctx, uint64(id))
+	if err != nil {
+		return nil, err
+	}
+	info := toInvitationInfo(inv)
+	s.sendInvitation(ctx, caller, inv, token, info)
+	return info, nil
+}
+
+// ListInvitations returns the pending invitations of a project
+func (s *InvitationService) ListInvitations(ctx context.Context, projectID uint64) ([]*InvitationInfo, error) {
+	rows, err := s.queries.ListProjectInvitations(ctx, managerdb.ListProjectInvitationsParams{
+		ProjectID: projectID,
+		Status:    managerdb.ProjectInvitationsStatusPending,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*InvitationInfo, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, toInvitationInfo(r))
+	}
+	return out, nil
+}
+
+// ResendInvitation issues a new accept link for a pending invitation, which
+// invalidates the previous one and renews the expiry.
+func (s *InvitationService) ResendInvitation(ctx context.Context, caller *Caller, inviteID uint64) (*InvitationInfo, error) {
+	inv, err := s.managedInvitation(ctx, caller, inviteID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := mutils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("generate token: %w", err)
+	}
+	if err := s.queries.UpdateProjectInvitationToken(ctx, managerdb.UpdateProjectInvitationTokenParams{
+		TokenHash: mutils.HashSHA256Hex(token),
+		ExpiresAt: time.Now().Add(invitationTTL),
+		ID:        inv.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	inv, err = s.queries.GetProjectInvitationByID(ctx, inv.ID)
+	if err != nil {
+		return nil, err
+	}
+	info := toInvitationInfo(inv)
+	s.sendInvitation(ctx, caller, inv, token, info)
+	return info, nil
+}
+
+// RevokeInvitation withdraws a pending invitation
+func (s *InvitationService) RevokeInvitation(ctx context.Context, caller *Caller, inviteID uint64) error {
+	inv, err := s.managedInvitation(ctx, caller, inviteID)
+	if err != nil {
+		return err
+	}
+	return s.closeInvitation(ctx, s.queries, inv.ID, managerdb.ProjectInvitationsStatusRevoked, caller.UserID)
+}
+
+// ListMyInvitations returns the unexpired pending invitations addressed to
+// the caller's email.
+func (s *InvitationService) ListMyInvitations(ctx context.Context, caller *Caller) ([]*PendingInvitationInfo, error) {
+	rows, err := s.queries.ListPendingInvitationsByEmail(ctx, strings.ToLower(caller.Email))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*PendingInvitationInfo, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, &PendingInvitationInfo{
+			ID:          r.ID,
+			ProjectID:   r.ProjectID,
+			ProjectName: r.Name,
+			ProjectSlug: r.Slug,
+			Role:        string(r.Role),
+			InvitedBy:   r.InvitedByUsername,
+			ExpiresAt:   r.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+			CreatedAt:   r.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+	return out, nil
+}
+
+// AcceptInvitation makes the caller a member of the invited project. An
+// existing membership is only ever raised to the invited role, never lowered.
+func (s *InvitationService) AcceptInvitation(ctx context.Context, caller *Caller, req *RespondInvitationRequest) (*InvitationResult, error) {
+	inv, err := s.respondableInvitation(ctx, caller, req)
+	if err != nil {
+		return nil, err
+	}
+	project, err := s.queries.GetProjectByID(ctx, inv.ProjectID)
+	if err != nil || project.DeletedAt.Valid {
+		return nil, ErrProjectNotFound
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	qtx := s.queries.WithTx(tx)
+
+	role := managerdb.UserProjectRolesRole(inv.Role)
+	if project.OwnerUserID == caller.UserID {
+		role = managerdb.UserProjectRolesRoleOwner
+	} else {
+		existing, err := qtx.GetUserProjectRole(ctx, managerdb.GetUserProjectRoleParams{UserID: caller.UserID, ProjectID: inv.ProjectID})
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			_, err = qtx.CreateUserProjectRole(ctx, managerdb.CreateUserProjectRoleParams{
+				UserID:    caller.UserID,
+				ProjectID: inv.ProjectID,
+				Role:      role,
+			})
+		case err != nil:
+		case roleRanks[existing.Role] >= roleRanks[role]:
+			role = existing.Role
+		default:
+			err = qtx.UpdateUserProjectRole(ctx, managerdb.UpdateUserProjectRoleParams{
+				Role:      role,
+				UserID:    caller.UserID,
+				ProjectID: inv.ProjectID,
+			})
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := s.closeInvitation(ctx, qtx, inv.ID, managerdb.ProjectInvitationsStatusAccepted, caller.UserID); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &InvitationResult{ProjectID: inv.ProjectID, Role: string(role)}, nil
+}
+
+// DeclineInvitation turns an invitation down
+func (s *InvitationService) DeclineInvitation(ctx context.Context, caller *Caller, req *RespondInvitationRequest) error {
+	inv, err := s.respondableInvitation(ctx, caller, req)
+	if err != nil {
+		return err
+	}
+	return s.closeInvitation(ctx, s.queries, inv.ID, managerdb.ProjectInvitationsStatusDeclined, caller.UserID)
+}
+
+// managedInvitation loads a pending invitation of the caller's project that
+// the caller may resend or revoke: one for a role below their own.
+func (s *InvitationService) managedInvitation(ctx context.Context, caller *Caller, inviteID uint64) (managerdb.ProjectInvitation, error) {
+	inv, err := s.queries.GetProjectInvitationByID(ctx, inviteID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && inv.ProjectID != caller.ProjectID) {
+		return inv, ErrInvitationNotFound
+	}
+	if err != nil {
+		return inv, err
+	}
+	if inv.Status != managerdb.ProjectInvitationsStatusPending {
+		return inv, ErrInvitationClosed
+	}
+	if !outranks(caller.Role, managerdb.UserProjectRolesRole(inv.Role)) {
+		return inv, fmt.Errorf("%w: cannot manage a %s invitation", ErrForbidden, inv.Role)
+	}
+	return inv, nil
+}
+
+// respondableInvitation loads the pending, unexpired invitation the caller
+// answers. The emailed token is proof of the address by itself; by id, the
+// invitation must be addressed to the caller's email.
+func (s *InvitationService) respondableInvitation(ctx context.Context, caller *Caller, req *RespondInvitationRequest) (managerdb.ProjectInvitation, error) {
+	var inv managerdb.ProjectInvitation
+	var err error
+	switch token := strings.TrimSpace(req.Token); {
+	case token != "":
+		inv, err = s.queries.GetProjectInvitationByTokenHash(ctx, mutils.HashSHA256Hex(token))
+	case req.ID != 0:
+		inv, err = s.queries.GetProjectInvitationByID(ctx, req.ID)
+		if err == nil && inv.Email != strings.ToLower(caller.Email) {
+			err = sql.ErrNoRows
+		}
+	default:
+		return inv, fmt.Errorf("token or id is required")
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return inv, ErrInvitationNotFound
+	}
+	if err != nil {
+		return inv, err
+	}
+	if inv.Status != managerdb.ProjectInvitationsStatusPending {
+		return inv, ErrInvitationClosed
+	}
+	if time.Now().After(inv.ExpiresAt) {
+		return inv, ErrInvitationExpired
+	}
+	return inv, nil
+}
+
+// closeInvitation moves a pending invitation to status. It fails when the
+// invitation was answered or revoked concurrently.
+func (s *InvitationService) closeInvitation(ctx context.Context, q *managerdb.Queries, inviteID uint64, status managerdb.ProjectInvitationsStatus, userID uint64) error {
+	res, err := q.UpdateProjectInvitationStatus(ctx, managerdb.UpdateProjectInvitationStatusParams{
+		Status:            status,
+		RespondedByUserID: sql.NullInt64{Int64: int64(userID), Valid: true},
+		ID:                inviteID,
+	})
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrInvitationClosed
+	}
+	return nil
+}
+
+// sendInvitation emails the accept link of inv and records the outcome in info.
+func (s *InvitationService) sendInvitation(ctx context.Context, caller *Caller, inv managerdb.ProjectInvitation, token string, info *InvitationInfo) {
+	if s.mailService == nil {
+		info.EmailError = "mail service not configured"
+		return
+	}
+	projectName := fmt.Sprintf("#%d", inv.ProjectID)
+	if p, err := s.queries.GetProjectByID(ctx, inv.ProjectID); err == nil {
+		projectName = p.Name
+	}
+	inviter := caller.Username
+	if inviter == "" {
+		inviter = "A Circle Center user"
+	}
+
+	config := configure.GetConfig()
+	acceptURL := fmt.Sprintf("%s/invitations/accept?token=%s", config.Frontend.BaseURL, url.QueryEscape(token))
+	err := s.mailService.SendInvitationEmail(mail.InvitationTemplateData{
+		Email:       inv.Email,
+		InviterName: inviter,
+		ProjectName: projectName,
+		Role:        string(inv.Role),
+		AcceptURL:   acceptURL,
+		ExpiresAt:   inv.ExpiresAt.UTC().Format("2006-01-02 15:04 UTC"),
+	})
+	if err != nil {
+		info.EmailError = err.Error()
+		return
+	}
+	info.EmailSent = true
+}
+
+// toInvitationInfo converts a DB invitation to InvitationInfo
+func toInvitationInfo(inv managerdb.ProjectInvitation) *InvitationInfo {
+	return &InvitationInfo{
+		ID:              inv.ID,
+		ProjectID:       inv.ProjectID,
+		Email:           inv.Email,
+		Role:            string(inv.Role),
+		Status:          string(inv.Status),
+		InvitedByUserID: inv.InvitedByUserID,
+		ExpiresAt:       inv.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		SentAt:          inv.SentAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:       inv.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
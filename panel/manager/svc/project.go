@@ -72,6 +72,10 @@ type CreateProjectResponse struct {
 	Visibility  string `json:"visibility"`
 	Description string `json:"description,omitempty"`
 	IconCount   uint32 `json:"icon_count"`
+	Role        string `json:"role,omitempty"` // the caller's role in the project
+	ArchivedAt  string `json:"archived_at,omitempty"`
+	DeletedAt   string `json:"deleted_at,omitempty"`
+	PurgeAfter  string `json:"purge_after,omitempty"` // when a deleted project stops being restorable
 	CreatedAt   string `json:"created_at"`
 	UpdatedAt   string `json:"updated_at"`
 }
@@ -182,6 +186,7 @@ func (s *ProjectService) CreateProject(ctx context.Context, token string, req *C
 		Visibility:  string(project.Visibility),
 		Description: mutils.NullString(project.Description),
 		IconCount:   project.IconCount,
+		Role:        string(managerdb.UserProjectRolesRoleOwner),
 		CreatedAt:   project.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   project.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -263,6 +268,10 @@ func (s *ProjectService) ListProjects(ctx context.Context, token string, limit,
 		if err != nil {
 			continue
 		}
+		role, err := projectRole(ctx, s.queries, claims.UserID, pid)
+		if err != nil {
+			continue
+		}
 		list = append(list, &CreateProjectResponse{
 			ID:          p.ID,
 			OwnerUserID: p.OwnerUserID,
@@ -272,6 +281,8 @@ func (s *ProjectService) ListProjects(ctx context.Context, token string, limit,
 			Visibility:  string(p.Visibility),
 			Description: mutils.NullString(p.Description),
 			IconCount:   p.IconCount,
+			Role:        string(role),
+			ArchivedAt:  mutils.NullTime(p.ArchivedAt),
 			CreatedAt:   p.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt:   p.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
 		})
@@ -279,23 +290,20 @@ func (s *ProjectService) ListProjects(ctx context.Context, token string, limit,
 	return list, nil
 }
 
-// GetProject returns a single project by id if owned by current user
+// GetProject returns a single project by id if the current user is a member
 func (s *ProjectService) GetProject(ctx context.Context, token string, projectID uint64) (*CreateProjectResponse, error) {
 	if s.authClient == nil {
 		return nil, fmt.Errorf("auth client not initialized")
 	}
-	claims, err := s.authClient.ValidateToken(ctx, token)
+	caller, err := authorize(ctx, s.authClient, s.queries, token, projectID, PermProjectRead)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token: %w", err)
+		return nil, err
 	}
 
 	p, err := s.queries.GetProjectByID(ctx, projectID)
 	if err != nil {
 		return nil, fmt.Errorf("project not found")
 	}
-	if p.OwnerUserID != claims.UserID {
-		return nil, fmt.Errorf("forbidden")
-	}
 
 	resp := &CreateProjectResponse{
 		ID:          p.ID,
@@ -306,29 +314,28 @@ func (s *ProjectService) GetProject(ctx context.Context, token string, projectID
 		Visibility:  string(p.Visibility),
 		Description: mutils.NullString(p.Description),
 		IconCount:   p.IconCount,
+		Role:        string(caller.Role),
+		ArchivedAt:  mutils.NullTime(p.ArchivedAt),
 		CreatedAt:   p.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   p.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
 	}
 	return resp, nil
 }
 
-// UpdateProject updates editable fields for an owner's project
+// UpdateProject updates editable fields of a project; admins and the owner only
 func (s *ProjectService) UpdateProject(ctx context.Context, token string, projectID uint64, req *UpdateProjectRequest) (*CreateProjectResponse, error) {
 	if s.authClient == nil {
 		return nil, fmt.Errorf("auth client not initialized")
 	}
-	claims, err := s.authClient.ValidateToken(ctx, token)
+	caller, err := authorize(ctx, s.authClient, s.queries, token, projectID, PermProjectWrite)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token: %w", err)
+		return nil, err
 	}
 
 	project, err := s.queries.GetProjectByID(ctx, projectID)
 	if err != nil {
 		return nil, fmt.Errorf("project not found")
 	}
-	if project.OwnerUserID != claims.UserID {
-		return nil, fmt.Errorf("forbidden")
-	}
 
 	// resolve fields (use existing when not provided)
 	name := project.Name
@@ -411,53 +418,147 @@ func (s *ProjectService) UpdateProject(ctx context.Context, token string, projec
 		Visibility:  string(updated.Visibility),
 		Description: mutils.NullString(updated.Description),
 		IconCount:   updated.IconCount,
+		Role:        string(caller.Role),
 		CreatedAt:   updated.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   updated.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
 	}
 	return resp, nil
 }
 
-// DeleteProject deletes a project owned by the authenticated user
+// DeleteProject moves a project to the trash. It stays restorable by the
+// owner for the retention period, after which the purge job removes its
+// rows and stored icons.
 func (s *ProjectService) DeleteProject(ctx context.Context, token string, projectID uint64) error {
 	if s.authClient == nil {
 		return fmt.Errorf("auth client not initialized")
 	}
+	if _, err := authorize(ctx, s.authClient, s.queries, token, projectID, PermProjectDelete); err != nil {
+		return err
+	}
+	return s.queries.SoftDeleteProject(ctx, projectID)
+}
+
+// RestoreProject takes a deleted project out of the trash
+func (s *ProjectService) RestoreProject(ctx context.Context, token string, projectID uint64) (*CreateProjectResponse, error) {
+	if s.authClient == nil {
+		return nil, fmt.Errorf("auth client not initialized")
+	}
+	if _, err := authorize(ctx, s.authClient, s.queries, token, projectID, PermProjectRestore); err != nil {
+		return nil, err
+	}
+
+	p, err := s.queries.GetProjectByID(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found")
+	}
+	if !p.DeletedAt.Valid {
+		return nil, fmt.Errorf("project is not deleted")
+	}
+	// Deleted projects do not count towards the quota, so a restore takes a
+	// free slot like a new project would.
+	quota, err := s.queries.CheckUserQuota(ctx, managerdb.CheckUserQuotaParams{
+		OwnerUserID: p.OwnerUserID,
+		UserID:      p.OwnerUserID,
+	})
+	if err == nil {
+		if can, convErr := mutils.AsBool(quota.CanCreateProject); convErr == nil && !can {
+			return nil, fmt.Errorf("project limit reached for user")
+		}
+	}
+	if err := s.queries.RestoreProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	return s.GetProject(ctx, token, projectID)
+}
+
+// ListDeletedProjects returns the current user's projects in the trash
+func (s *ProjectService) ListDeletedProjects(ctx context.Context, token string) ([]*CreateProjectResponse, error) {
+	if s.authClient == nil {
+		return nil, fmt.Errorf("auth client not initialized")
+	}
 	claims, err := s.authClient.ValidateToken(ctx, token)
 	if err != nil {
-		return fmt.Errorf("invalid token: %w", err)
+		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
-	project, err := s.queries.GetProjectByID(ctx, projectID)
+	rows, err := s.queries.ListDeletedProjectsByOwner(ctx, claims.UserID)
 	if err != nil {
-		return fmt.Errorf("project not found")
+		return nil, err
+	}
+	retention := projectRetention()
+	list := make([]*CreateProjectResponse, 0, len(rows))
+	for _, p := range rows {
+		list = append(list, &CreateProjectResponse{
+			ID:          p.ID,
+			OwnerUserID: p.OwnerUserID,
+			Name:        p.Name,
+			Slug:        p.Slug,
+			PackageName: mutils.NullString(p.PackageName),
+			Visibility:  string(p.Visibility),
+			Description: mutils.NullString(p.Description),
+			IconCount:   p.IconCount,
+			Role:        string(managerdb.UserProjectRolesRoleOwner),
+			ArchivedAt:  mutils.NullTime(p.ArchivedAt),
+			DeletedAt:   mutils.NullTime(p.DeletedAt),
+			PurgeAfter:  p.DeletedAt.Time.Add(retention).UTC().Format("2006-01-02T15:04:05Z07:00"),
+			CreatedAt:   p.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+			UpdatedAt:   p.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		})
 	}
-	if project.OwnerUserID != claims.UserID {
-		return fmt.Errorf("forbidden")
+	return list, nil
+}
+
+// ArchiveProject makes a project read-only until it is unarchived
+func (s *ProjectService) ArchiveProject(ctx context.Context, token string, projectID uint64) error {
+	if s.authClient == nil {
+		return fmt.Errorf("auth client not initialized")
 	}
+	if _, err := authorize(ctx, s.authClient, s.queries, token, projectID, PermProjectArchive); err != nil {
+		return err
+	}
+	p, err := s.queries.GetProjectByID(ctx, projectID)
+	if err != nil {
+		return ErrProjectNotFound
+	}
+	if p.ArchivedAt.Valid {
+		return fmt.Errorf("project is already archived")
+	}
+	return s.queries.ArchiveProject(ctx, projectID)
+}
 
-	return s.queries.DeleteProject(ctx, managerdb.DeleteProjectParams{
-		ID:          projectID,
-		OwnerUserID: project.OwnerUserID,
-	})
+// UnarchiveProject makes an archived project writable again
+func (s *ProjectService) UnarchiveProject(ctx context.Context, token string, projectID uint64) error {
+	if s.authClient == nil {
+		return fmt.Errorf("auth client not initialized")
+	}
+	if _, err := authorize(ctx, s.authClient, s.queries, token, projectID, PermProjectArchive); err != nil {
+		return err
+	}
+	p, err := s.queries.GetProjectByID(ctx, projectID)
+	if err != nil {
+		return ErrProjectNotFound
+	}
+	if !p.ArchivedAt.Valid {
+		return fmt.Errorf("project is not archived")
+	}
+	return s.queries.UnarchiveProject(ctx, projectID)
 }
 
-// AssignProjectRole creates or updates a collaborator role; owner-only operation
+// AssignProjectRole creates or updates a collaborator role. Admins and the
+// owner may assign roles below their own, to members below them.
 func (s *ProjectService) AssignProjectRole(ctx context.Context, token string, projectID uint64, req *AssignRoleRequest) error {
 	if s.authClient == nil {
 		return fmt.Errorf("auth client not initialized")
 	}
-	claims, err := s.authClient.ValidateToken(ctx, token)
+	caller, err := authorize(ctx, s.authClient, s.queries, token, projectID, PermMembersManage)
 	if err != nil {
-		return fmt.Errorf("invalid token: %w", err)
+		return err
 	}
 
 	project, err := s.queries.GetProjectByID(ctx, projectID)
 	if err != nil {
 		return fmt.Errorf("project not found")
 	}
-	if project.OwnerUserID != claims.UserID {
-		return fmt.Errorf("forbidden")
-	}
 
 	if req.TargetUserID == project.OwnerUserID {
 		return fmt.Errorf("cannot modify project owner role")
@@ -473,17 +574,24 @@ func (s *ProjectService) AssignProjectRole(ctx context.Context, token string, pr
 	case "viewer":
 		role = managerdb.UserProjectRolesRoleViewer
 	case "owner":
-		return fmt.Errorf("changing owner is not supported")
+		return fmt.Errorf("the owner can only be changed by an ownership transfer")
 	default:
 		return fmt.Errorf("invalid role: %s", req.Role)
 	}
 
+	if !outranks(caller.Role, role) {
+		return fmt.Errorf("%w: cannot grant %s role", ErrForbidden, role)
+	}
+
 	// if exists update, else create
-	_, err = s.queries.GetUserProjectRole(ctx, managerdb.GetUserProjectRoleParams{
+	existing, err := s.queries.GetUserProjectRole(ctx, managerdb.GetUserProjectRoleParams{
 		UserID:    req.TargetUserID,
 		ProjectID: projectID,
 	})
 	if err == nil {
+		if !outranks(caller.Role, existing.Role) {
+			return fmt.Errorf("%w: cannot change a %s's role", ErrForbidden, existing.Role)
+		}
 		return s.queries.UpdateUserProjectRole(ctx, managerdb.UpdateUserProjectRoleParams{
 			Role:      role,
 			UserID:    req.TargetUserID,
@@ -505,23 +613,13 @@ func (s *ProjectService) GetProjectMembersRoles(ctx context.Context, token strin
 	if s.authClient == nil {
 		return nil, fmt.Errorf("auth client not initialized")
 	}
-	claims, err := s.authClient.ValidateToken(ctx, token)
+	caller, err := authorize(ctx, s.authClient, s.queries, token, projectID, PermMembersRead)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token: %w", err)
-	}
-
-	// Determine caller role (treat project owner as owner if role row missing)
-	callerRole := ""
-	var callerAddedAt string
-	if upr, err := s.queries.GetUserProjectRole(ctx, managerdb.GetUserProjectRoleParams{UserID: claims.UserID, ProjectID: projectID}); err == nil {
-		callerRole = string(upr.Role)
-		callerAddedAt = upr.AddedAt.UTC().Format("2006-01-02T15:04:05Z07:00")
-	} else if p, perr := s.queries.GetProjectByID(ctx, projectID); perr == nil && p.OwnerUserID == claims.UserID {
-		callerRole = "owner"
+		return nil, err
 	}
 
 	// Owner/Admin: return full collaborator list
-	if callerRole == string(managerdb.UserProjectRolesRoleOwner) || callerRole == string(managerdb.UserProjectRolesRoleAdmin) || callerRole == "owner" {
+	if caller.Can(PermMembersManage) {
 		rows, err := s.queries.ListProjectCollaborators(ctx, projectID)
 		if err != nil {
 			return nil, err
@@ -541,39 +639,39 @@ func (s *ProjectService) GetProjectMembersRoles(ctx context.Context, token strin
 	}
 
 	// Non-admin member: return only caller's own role
-	if callerRole != "" {
-		return []*CollaboratorInfo{{
-			UserID:  claims.UserID,
-			Role:    callerRole,
-			AddedAt: callerAddedAt,
-		}}, nil
+	var callerAddedAt string
+	if upr, err := s.queries.GetUserProjectRole(ctx, managerdb.GetUserProjectRoleParams{UserID: caller.UserID, ProjectID: projectID}); err == nil {
+		callerAddedAt = upr.AddedAt.UTC().Format("2006-01-02T15:04:05Z07:00")
 	}
-
-	// Not a member of the project
-	return nil, fmt.Errorf("forbidden")
+	return []*CollaboratorInfo{{
+		UserID:  caller.UserID,
+		Role:    string(caller.Role),
+		AddedAt: callerAddedAt,
+	}}, nil
 }
 
-// RemoveProjectCollaborator removes a collaborator from a project. Owner-only.
+// RemoveProjectCollaborator removes a collaborator from a project. Admins and
+// the owner may remove members below them.
 func (s *ProjectService) RemoveProjectCollaborator(ctx context.Context, token string, projectID uint64, userID uint64) error {
     if s.authClient == nil {
         return fmt.Errorf("auth client not initialized")
     }
-    claims, err := s.authClient.ValidateToken(ctx, token)
+    caller, err := authorize(ctx, s.authClient, s.queries, token, projectID, PermMembersManage)
     if err != nil {
-        return fmt.Errorf("invalid token: %w", err)
+        return err
     }
 
     project, err := s.queries.GetProjectByID(ctx, projectID)
     if err != nil {
         return fmt.Errorf("project not found")
     }
-    if project.OwnerUserID != claims.UserID {
-        return fmt.Errorf("forbidden")
-    }
 
     if userID == project.OwnerUserID {
         return fmt.Errorf("cannot remove project owner")
     }
+    if upr, err := s.queries.GetUserProjectRole(ctx, managerdb.GetUserProjectRoleParams{UserID: userID, ProjectID: projectID}); err == nil && !outranks(caller.Role, upr.Role) {
+        return fmt.Errorf("%w: cannot remove a %s", ErrForbidden, upr.Role)
+    }
 
     // Perform deletion (no-op if not exists)
     return s.queries.DeleteUserProjectRole(ctx, managerdb.DeleteUserProjectRoleParams{
@@ -581,3 +679,9 @@ func (s *ProjectService) RemoveProjectCollaborator(ctx context.Context, token st
         ProjectID: projectID,
     })
 }
+
+// outranks reports whether a member with role may grant or manage role other:
+// only roles below one's own.
+func outranks(role, other managerdb.UserProjectRolesRole) bool {
+	return roleRanks[role] > roleRanks[other]
+}
@@ -0,0 +1,99 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"time"
+
+	configure "circle-center/globals/configure"
+	"circle-center/globals/storage"
+	managerdb "circle-center/repository/sqlc/manager"
+)
+
+const (
+	defaultRetentionPeriod = 30 * 24 * time.Hour
+	purgeBatchSize         = 100
+)
+
+// projectRetention returns how long a deleted project stays restorable.
+func projectRetention() time.Duration {
+	cfg := configure.GetConfig()
+	if cfg != nil && cfg.Projects.RetentionPeriod > 0 {
+		return cfg.Projects.RetentionPeriod
+	}
+	return defaultRetentionPeriod
+}
+
+// ProjectPurger removes projects whose retention period has ended, together
+// with their icons/{project_id} storage directory.
+type ProjectPurger struct {
+	queries *managerdb.Queries
+	storage *storage.IconStorage
+}
+
+// NewProjectPurger constructs a ProjectPurger.
+func NewProjectPurger(db *sql.DB) (*ProjectPurger, error) {
+	st, err := storage.NewIconStorage()
+	if err != nil {
+		return nil, err
+	}
+	return &ProjectPurger{queries: managerdb.New(db), storage: st}, nil
+}
+
+// Run purges expired projects every interval until ctx is done.
+func (p *ProjectPurger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := p.PurgeOnce(ctx); err != nil {
+			log.Printf("Warning: project purge failed: %v", err)
+		} else if n > 0 {
+			log.Printf("Purged %d deleted project(s)", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce removes every project deleted longer than the retention period
+// ago and returns how many were removed. A project restored meanwhile is
+// skipped, as the delete only matches rows still past the cutoff.
+func (p *ProjectPurger) PurgeOnce(ctx context.Context) (int, error) {
+	cutoff := sql.NullTime{Time: time.Now().Add(-projectRetention()), Valid: true}
+	purged := 0
+	for {
+		ids, err := p.queries.ListProjectsDeletedBefore(ctx, managerdb.ListProjectsDeletedBeforeParams{
+			DeletedAt: cutoff,
+			Limit:     purgeBatchSize,
+		})
+		if err != nil {
+			return purged, err
+		}
+		for _, id := range ids {
+			res, err := p.queries.PurgeProject(ctx, managerdb.PurgeProjectParams{ID: id, DeletedAt: cutoff})
+			if err != nil {
+				return purged, err
+			}
+			if n, err := res.RowsAffected(); err == nil && n == 0 {
+				continue
+			}
+			purged++
+			dir, err := p.storage.ProjectDir(id)
+			if err != nil {
+				log.Printf("Warning: resolve icon directory of purged project %d: %v", id, err)
+				continue
+			}
+			if err := os.RemoveAll(dir); err != nil {
+				log.Printf("Warning: remove icon directory of purged project %d: %v", id, err)
+			}
+		}
+		if len(ids) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
@@ -52,6 +52,14 @@ func (s *RequestService) ProcessUpload(ctx context.Context, token string, appsJS
 	if err != nil {
 		return &RequestManagerResponse{Status: "error", Message: "invalid token"}, fmt.Errorf("invalid token")
 	}
+	// Deleted projects take no requests, archived ones are read-only
+	project, err := s.queries.GetProjectByID(ctx, key.ProjectID)
+	if err != nil || project.DeletedAt.Valid {
+		return &RequestManagerResponse{Status: "error", Message: "invalid token"}, fmt.Errorf("invalid token")
+	}
+	if project.ArchivedAt.Valid {
+		return &RequestManagerResponse{Status: "error", Message: "project is archived"}, ErrProjectArchived
+	}
 	_ = s.queries.UpdateAPIKeyLastUsed(ctx, key.ID)
 
 	// Validate apps JSON and parse components
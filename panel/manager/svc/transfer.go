@@ -0,0 +1,281 @@
+package manager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	mutils "circle-center/panel/manager/utils"
+	managerdb "circle-center/repository/sqlc/manager"
+)
+
+// ErrNoPendingTransfer is returned when a project has no transfer to act on.
+var ErrNoPendingTransfer = errors.New("no pending ownership transfer")
+
+// TransferService moves project ownership: the owner proposes a member as
+// the new owner, and the transfer happens when that member accepts.
+type TransferService struct {
+	queries *managerdb.Queries
+	db      *sql.DB
+}
+
+// NewTransferService constructs a TransferService
+func NewTransferService(db *sql.DB) *TransferService {
+	return &TransferService{queries: managerdb.New(db), db: db}
+}
+
+// ProposeTransferRequest names the member who should become the owner
+type ProposeTransferRequest struct {
+	TargetUserID uint64 `json:"target_user_id" binding:"required"`
+}
+
+// TransferInfo represents an ownership transfer
+type TransferInfo struct {
+	ID          uint64 `json:"id"`
+	ProjectID   uint64 `json:"project_id"`
+	FromUserID  uint64 `json:"from_user_id"`
+	ToUserID    uint64 `json:"to_user_id"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+	RespondedAt string `json:"responded_at,omitempty"`
+}
+
+// ProposeTransfer offers ownership of the caller's project to one of its
+// members. Only one transfer may be pending per project; the project row is
+// locked while that is checked, so concurrent proposals cannot both succeed.
+func (s *TransferService) ProposeTransfer(ctx context.Context, caller *Caller, req *ProposeTransferRequest) (*TransferInfo, error) {
+	if req.TargetUserID == caller.UserID {
+		return nil, fmt.Errorf("you already own this project")
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err := proposeTransfer(ctx, s.queries.WithTx(tx), caller, req.TargetUserID); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetTransfer(ctx, caller.ProjectID)
+}
+
+// proposeTransfer records a proposal with the queries of a transaction.
+func proposeTransfer(ctx context.Context, qtx *managerdb.Queries, caller *Caller, targetUserID uint64) error {
+	if _, err := qtx.LockProject(ctx, caller.ProjectID); errors.Is(err, sql.ErrNoRows) {
+		return ErrProjectNotFound
+	} else if err != nil {
+		return err
+	}
+	if _, err := qtx.GetPendingOwnershipTransfer(ctx, caller.ProjectID); err == nil {
+		return fmt.Errorf("an ownership transfer is already pending; cancel it first")
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	_, err := qtx.GetUserProjectRole(ctx, managerdb.GetUserProjectRoleParams{UserID: targetUserID, ProjectID: caller.ProjectID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("the new owner must be a project member")
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = qtx.CreateProjectOwnershipTransfer(ctx, managerdb.CreateProjectOwnershipTransferParams{
+		ProjectID:  caller.ProjectID,
+		FromUserID: caller.UserID,
+		ToUserID:   targetUserID,
+	})
+	return err
+}
+
+// GetTransfer returns the pending transfer of a project
+func (s *TransferService) GetTransfer(ctx context.Context, projectID uint64) (*TransferInfo, error) {
+	t, err := s.queries.GetPendingOwnershipTransfer(ctx, projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoPendingTransfer
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toTransferInfo(t), nil
+}
+
+// CancelTransfer withdraws the pending transfer of the caller's project
+func (s *TransferService) CancelTransfer(ctx context.Context, caller *Caller) error {
+	t, err := s.queries.GetPendingOwnershipTransfer(ctx, caller.ProjectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoPendingTransfer
+	}
+	if err != nil {
+		return err
+	}
+	return closeTransfer(ctx, s.queries, t.ID, managerdb.ProjectOwnershipTransfersStatusCancelled)
+}
+
+// DeclineTransfer turns down a transfer proposed to the caller
+func (s *TransferService) DeclineTransfer(ctx context.Context, caller *Caller) error {
+	t, err := s.proposedTo(ctx, caller)
+	if err != nil {
+		return err
+	}
+	return closeTransfer(ctx, s.queries, t.ID, managerdb.ProjectOwnershipTransfersStatusDeclined)
+}
+
+// AcceptTransfer makes the caller the owner of the project. In one
+// transaction, owner_user_id moves to the caller and the two members swap
+// their role rows, so the previous owner takes the caller's former role. The
+// caller's project quota must allow one more project, and they must not own
+// a project with the same slug already.
+func (s *TransferService) AcceptTransfer(ctx context.Context, caller *Caller) (*TransferInfo, error) {
+	t, err := s.proposedTo(ctx, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err := acceptTransfer(ctx, s.queries.WithTx(tx), t); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	info := toTransferInfo(t)
+	info.Status = string(managerdb.ProjectOwnershipTransfersStatusAccepted)
+	return info, nil
+}
+
+// acceptTransfer performs an accepted transfer with the queries of a transaction.
+func acceptTransfer(ctx context.Context, qtx *managerdb.Queries, t managerdb.ProjectOwnershipTransfer) error {
+	project, err := qtx.GetProjectByID(ctx, t.ProjectID)
+	if err != nil {
+		return ErrProjectNotFound
+	}
+	if project.OwnerUserID != t.FromUserID {
+		return fmt.Errorf("the project owner has changed since the transfer was proposed")
+	}
+	target, err := qtx.GetUserProjectRole(ctx, managerdb.GetUserProjectRoleParams{UserID: t.ToUserID, ProjectID: t.ProjectID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("the new owner is no longer a project member")
+	}
+	if err != nil {
+		return err
+	}
+
+	quota, err := qtx.CheckUserQuota(ctx, managerdb.CheckUserQuotaParams{
+		OwnerUserID: t.ToUserID,
+		UserID:      t.ToUserID,
+	})
+	if err == nil {
+		if can, convErr := mutils.AsBool(quota.CanCreateProject); convErr == nil && !can {
+			return fmt.Errorf("project limit reached for the new owner")
+		}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if _, err := qtx.GetProjectBySlug(ctx, managerdb.GetProjectBySlugParams{OwnerUserID: t.ToUserID, Slug: project.Slug}); err == nil {
+		return fmt.Errorf("the new owner already has a project with slug %q", project.Slug)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	res, err := qtx.UpdateProjectOwner(ctx, managerdb.UpdateProjectOwnerParams{
+		NewOwnerUserID: t.ToUserID,
+		ID:             t.ProjectID,
+		OwnerUserID:    t.FromUserID,
+	})
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("the project owner has changed since the transfer was proposed")
+	}
+
+	if err := qtx.UpdateUserProjectRole(ctx, managerdb.UpdateUserProjectRoleParams{
+		Role:      managerdb.UserProjectRolesRoleOwner,
+		UserID:    t.ToUserID,
+		ProjectID: t.ProjectID,
+	}); err != nil {
+		return err
+	}
+	// A leftover owner row grants admin; swap that rather than ownership.
+	previous := target.Role
+	if previous == managerdb.UserProjectRolesRoleOwner {
+		previous = managerdb.UserProjectRolesRoleAdmin
+	}
+	_, err = qtx.GetUserProjectRole(ctx, managerdb.GetUserProjectRoleParams{UserID: t.FromUserID, ProjectID: t.ProjectID})
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = qtx.CreateUserProjectRole(ctx, managerdb.CreateUserProjectRoleParams{
+			UserID:    t.FromUserID,
+			ProjectID: t.ProjectID,
+			Role:      previous,
+		})
+	case err == nil:
+		err = qtx.UpdateUserProjectRole(ctx, managerdb.UpdateUserProjectRoleParams{
+			Role:      previous,
+			UserID:    t.FromUserID,
+			ProjectID: t.ProjectID,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	return closeTransfer(ctx, qtx, t.ID, managerdb.ProjectOwnershipTransfersStatusAccepted)
+}
+
+// proposedTo loads the pending transfer of the caller's project and checks
+// that it was proposed to the caller.
+func (s *TransferService) proposedTo(ctx context.Context, caller *Caller) (managerdb.ProjectOwnershipTransfer, error) {
+	t, err := s.queries.GetPendingOwnershipTransfer(ctx, caller.ProjectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return t, ErrNoPendingTransfer
+	}
+	if err != nil {
+		return t, err
+	}
+	if t.ToUserID != caller.UserID {
+		return t, fmt.Errorf("%w: the transfer was proposed to another member", ErrForbidden)
+	}
+	return t, nil
+}
+
+// closeTransfer moves a pending transfer to status. It fails when the
+// transfer was answered or cancelled concurrently.
+func closeTransfer(ctx context.Context, q *managerdb.Queries, transferID uint64, status managerdb.ProjectOwnershipTransfersStatus) error {
+	res, err := q.UpdateOwnershipTransferStatus(ctx, managerdb.UpdateOwnershipTransferStatusParams{
+		Status: status,
+		ID:     transferID,
+	})
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNoPendingTransfer
+	}
+	return nil
+}
+
+// toTransferInfo converts a DB transfer to TransferInfo
+func toTransferInfo(t managerdb.ProjectOwnershipTransfer) *TransferInfo {
+	info := &TransferInfo{
+		ID:         t.ID,
+		ProjectID:  t.ProjectID,
+		FromUserID: t.FromUserID,
+		ToUserID:   t.ToUserID,
+		Status:     string(t.Status),
+		CreatedAt:  t.CreatedAt.UTC().Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if t.RespondedAt.Valid {
+		info.RespondedAt = t.RespondedAt.Time.UTC().Format("2006-01-02T15:04:05Z07:00")
+	}
+	return info
+}
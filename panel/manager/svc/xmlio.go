@@ -1,12 +1,18 @@
 package manager
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 
+	"circle-center/globals"
 	mutils "circle-center/panel/manager/utils"
+	"circle-center/processor/operation"
+	"circle-center/reader"
 	managerdb "circle-center/repository/sqlc/manager"
 )
 
@@ -116,3 +122,155 @@ func (s *XMLIOService) SaveIcons(ctx context.Context, projectID uint64, componen
 	}
 	return summary, nil
 }
+
+// defaultIconCategory titles the drawable.xml category of icons whose
+// metadata names no category. Converted to icon_pack.xml it matches the all
+// array, so such icons are only listed there.
+const defaultIconCategory = "All"
+
+// ExportPackFile generates icon_pack.xml or drawable.xml (target "icon_pack"
+// or "drawable") from the project's icons. Rejected icons are left out. Icons
+// are grouped by the "category" key of their metadata, in order of first
+// appearance, oldest first.
+func (s *XMLIOService) ExportPackFile(ctx context.Context, projectID uint64, target string, opts operation.ConvertOptions) (string, error) {
+	icons, err := listAllIcons(ctx, s.queries, projectID)
+	if err != nil {
+		return "", err
+	}
+
+	var res globals.DrawableResources
+	byTitle := make(map[string]int)
+	listed := make(map[string]bool)
+	for i := len(icons) - 1; i >= 0; i-- {
+		icon := icons[i]
+		if icon.Status == managerdb.IconsStatusRejected {
+			continue
+		}
+		title := defaultIconCategory
+		if icon.Metadata.Valid {
+			var meta struct {
+				Category string `json:"category"`
+			}
+			if json.Unmarshal([]byte(icon.Metadata.String), &meta) == nil && strings.TrimSpace(meta.Category) != "" {
+				title = strings.TrimSpace(meta.Category)
+			}
+		}
+		idx, ok := byTitle[title]
+		if !ok {
+			res.Categories = append(res.Categories, globals.DrawableCategory{Title: title})
+			idx = len(res.Categories) - 1
+			byTitle[title] = idx
+		}
+		// Several components often share a drawable; list it once per category.
+		if key := title + "\x00" + icon.Drawable; !listed[key] {
+			listed[key] = true
+			res.Categories[idx].Drawables = append(res.Categories[idx].Drawables, icon.Drawable)
+		}
+	}
+
+	var out strings.Builder
+	switch target {
+	case "drawable":
+		res.Version = opts.Version
+		err = operation.WriteDrawable(&out, res, opts)
+	case "icon_pack":
+		err = operation.WriteIconPack(&out, operation.DrawableToIconPack(res, opts), opts)
+	default:
+		return "", fmt.Errorf("unsupported target %q", target)
+	}
+	if err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// ProjectDiff compares an uploaded pack file with a project's icons.
+type ProjectDiff struct {
+	FileType     reader.FileType
+	FileCount    int
+	ProjectCount int
+	Diff         *operation.AppFilterDiff
+}
+
+// DiffProject compares an appfilter.xml, appmap.xml or theme_resources.xml
+// with the project's icons; the file is the first side of the diff and the
+// project the second. Rejected icons are left out. appmap.xml names no
+// package, so its items are matched by activity class, relative classes of
+// the project expanded with their package.
+func (s *XMLIOService) DiffProject(ctx context.Context, projectID uint64, data []byte, opts operation.DiffOptions) (*ProjectDiff, error) {
+	fileType, err := reader.DetectFileType(bytes.NewReader(data))
+	if errors.Is(err, reader.ErrInconclusiveType) {
+		// An appfilter without items is still an appfilter.
+		fileType, err = reader.FileTypeAppFilter, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fileItems []globals.Item
+	switch fileType {
+	case reader.FileTypeAppFilter:
+		if fileItems, err = reader.ParseFromReader(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	case reader.FileTypeAppMap:
+		res, err := reader.ParseAppMapFromReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range res.Items {
+			fileItems = append(fileItems, globals.Item{Component: item.Class, Drawable: item.Drawable, AppName: item.AppName})
+		}
+		// Classes are not components.
+		opts.NormalizeComponents = false
+	case reader.FileTypeTheme:
+		res, err := reader.ParseThemeFromReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		for _, icon := range res.Icons {
+			fileItems = append(fileItems, globals.Item{
+				Component:    "ComponentInfo{" + icon.Component + "}",
+				Drawable:     icon.Drawable,
+				AppName:      icon.AppName,
+				PackageName:  icon.PackageName,
+				ActivityName: icon.ActivityName,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file type %q: expected appfilter, appmap or theme_resources", fileType)
+	}
+
+	icons, err := listAllIcons(ctx, s.queries, projectID)
+	if err != nil {
+		return nil, err
+	}
+	projectItems := make([]globals.Item, 0, len(icons))
+	for _, icon := range icons {
+		if icon.Status == managerdb.IconsStatusRejected {
+			continue
+		}
+		comp := mutils.TrimComponentInfoWrapper(icon.ComponentInfo)
+		item := globals.Item{
+			Component:   "ComponentInfo{" + comp + "}",
+			Drawable:    icon.Drawable,
+			AppName:     icon.Name,
+			PackageName: icon.Pkg,
+		}
+		if fileType == reader.FileTypeAppMap {
+			class := mutils.ExtractRightClassFromComponent(comp)
+			if strings.HasPrefix(class, ".") {
+				class = mutils.InferPackageFromComponent(comp) + class
+			}
+			item.Component = class
+		}
+		projectItems = append(projectItems, item)
+	}
+
+	return &ProjectDiff{
+		FileType:     fileType,
+		FileCount:    len(fileItems),
+		ProjectCount: len(projectItems),
+		Diff:         operation.DiffItems(fileItems, projectItems, opts),
+	}, nil
+}
@@ -4,6 +4,10 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -27,3 +31,32 @@ func GenerateSecureToken(byteLength int) (string, error) {
 	}
 	return hex.EncodeToString(buf), nil
 }
+
+// ErrInvalidIconPath is returned for icon paths outside icons/{project_id}/.
+var ErrInvalidIconPath = errors.New("invalid icon path")
+
+// ParseIconPath cleans a stored icon path like icons/{project_id}/{file} and
+// returns it with its project id. Paths with a ".." segment are rejected, so
+// the project id that access is checked against is the one whose directory
+// the path resolves into.
+func ParseIconPath(relpath string) (string, uint64, error) {
+	norm := strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(relpath)), "/")
+	for _, seg := range strings.Split(norm, "/") {
+		if seg == ".." {
+			return "", 0, ErrInvalidIconPath
+		}
+	}
+	clean := path.Clean(norm)
+	parts := strings.SplitN(clean, "/", 3)
+	if len(parts) < 3 || parts[0] != "icons" {
+		return "", 0, ErrInvalidIconPath
+	}
+	pid, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || pid == 0 {
+		return "", 0, ErrInvalidIconPath
+	}
+	if !strings.HasPrefix(clean, "icons/"+strconv.FormatUint(pid, 10)+"/") {
+		return "", 0, ErrInvalidIconPath
+	}
+	return clean, pid, nil
+}
@@ -0,0 +1,43 @@
+package utils
+
+import "testing"
+
+func TestParseIconPath(t *testing.T) {
+	valid := map[string]struct {
+		clean string
+		pid   uint64
+	}{
+		"icons/1/browser.png":       {"icons/1/browser.png", 1},
+		"/icons/12/a/./camera.png":  {"icons/12/a/camera.png", 12},
+		"icons/7//clock.webp":       {"icons/7/clock.webp", 7},
+		" icons/3/2024/01/mail.png": {"icons/3/2024/01/mail.png", 3},
+	}
+	for in, want := range valid {
+		clean, pid, err := ParseIconPath(in)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", in, err)
+			continue
+		}
+		if clean != want.clean || pid != want.pid {
+			t.Errorf("%q: got (%q, %d), want (%q, %d)", in, clean, pid, want.clean, want.pid)
+		}
+	}
+
+	invalid := []string{
+		"icons/1/../2/secret.png",
+		"icons/1/a/../../2/secret.png",
+		"../icons/1/browser.png",
+		"icons/../etc/passwd",
+		"icons/1",
+		"icons/1/",
+		"icons/0/browser.png",
+		"icons/01/browser.png",
+		"icons/x/browser.png",
+		"avatars/1/me.png",
+	}
+	for _, in := range invalid {
+		if _, _, err := ParseIconPath(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
@@ -55,3 +55,12 @@ func NullString(ns sql.NullString) string {
 	}
 	return ""
 }
+
+// NullTime returns the time in RFC 3339 UTC, or empty string if the
+// sql.NullTime is invalid
+func NullTime(nt sql.NullTime) string {
+	if nt.Valid {
+		return nt.Time.UTC().Format("2006-01-02T15:04:05Z07:00")
+	}
+	return ""
+}
@@ -2,15 +2,17 @@ package operation
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"circle-center/globals"
 	"circle-center/reader"
 )
 
 // FindMissingIcons compares the icons present in a directory with the entries
-// listed inside an icon_pack.xml file. It returns a slice of package/icon names
-// that are present in the directory (icons) but absent from any <string-array>
-// in the icon pack definition.
+// listed inside an appfilter.xml file. It returns the sorted icon names
+// (file base names, which are expected to be package names) that are present
+// in the directory but have no appfilter item for that package.
 func FindMissingIcons(iconDir string, appFilterPath string) ([]string, error) {
 	// Parse local icons
 	localIcons, err := reader.ReadLocalIcons(iconDir)
@@ -24,21 +26,12 @@ func FindMissingIcons(iconDir string, appFilterPath string) ([]string, error) {
 		return nil, fmt.Errorf("read appfilter: %w", err)
 	}
 
-	// Print all local icon package names
-	// fmt.Println("[DEBUG] local icons package names:")
-	for _, icon := range localIcons {
-		fmt.Println(icon.PackageName)
-	}
-
-	// Print all appfilter.xml package names
-	// fmt.Println("[DEBUG] appfilter.xml package names:")
-	for _, item := range items {
-		fmt.Println(item.PackageName)
-	}
-
-	// fmt.Println("[DEBUG] local icons count: ", len(localIcons))
-	// fmt.Println("[DEBUG] appfilter.xml count: ", len(items))
+	return missingIcons(localIcons, items), nil
+}
 
+// missingIcons returns the sorted names of icons whose package has no item
+// in items.
+func missingIcons(localIcons []globals.LocalIcon, items []globals.Item) []string {
 	// Build a set of all package names present in appfilter.xml
 	appFilterSet := make(map[string]struct{})
 	for _, item := range items {
@@ -54,30 +47,153 @@ func FindMissingIcons(iconDir string, appFilterPath string) ([]string, error) {
 	}
 
 	// Convert set to slice
-	var missing []string
+	missing := make([]string, 0, len(missingSet))
 	for name := range missingSet {
 		missing = append(missing, name)
 	}
+	sort.Strings(missing)
+	return missing
+}
+
+// IconDiffReport compares a set of icon files with the entries that should
+// reference them.
+type IconDiffReport struct {
+	// MissingIcons lists icons that no entry covers. Against an appfilter
+	// this compares the icon name with each item's package, as
+	// FindMissingIcons does; against an icon pack, whose entries carry no
+	// package, it compares with the listed drawable names.
+	MissingIcons []string
+	// OrphanDrawables lists the drawable names of icons that no entry
+	// references.
+	OrphanDrawables []string
+	// UnusedEntries lists entries whose drawable has no icon file.
+	UnusedEntries []globals.Item
+}
+
+// iconDrawableName returns the drawable resource name for an icon, with dots
+// replaced the same way CleanIconFileNames does.
+func iconDrawableName(icon globals.LocalIcon) string {
+	return strings.ReplaceAll(icon.PackageName, ".", "_")
+}
+
+// DiffIconsAgainstAppFilter compares local icons with appfilter items.
+func DiffIconsAgainstAppFilter(localIcons []globals.LocalIcon, items []globals.Item) IconDiffReport {
+	referenced := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		referenced[item.Drawable] = struct{}{}
+	}
+
+	report := iconDiff(localIcons, referenced, items)
+	report.MissingIcons = missingIcons(localIcons, items)
+	return report
+}
+
+// DiffIconsAgainstIconPack compares local icons with the drawables listed in
+// any <string-array> of an icon_pack.xml.
+func DiffIconsAgainstIconPack(localIcons []globals.LocalIcon, res globals.IconPackResources) IconDiffReport {
+	referenced := make(map[string]struct{})
+	var entries []globals.Item
+	for _, arr := range res.Arrays {
+		for _, name := range arr.Items {
+			if _, ok := referenced[name]; ok {
+				continue
+			}
+			referenced[name] = struct{}{}
+			entries = append(entries, globals.Item{Drawable: name})
+		}
+	}
+
+	report := iconDiff(localIcons, referenced, entries)
+	report.MissingIcons = append([]string(nil), report.OrphanDrawables...)
+	return report
+}
+
+// iconDiff fills OrphanDrawables and UnusedEntries.
+func iconDiff(localIcons []globals.LocalIcon, referenced map[string]struct{}, entries []globals.Item) IconDiffReport {
+	report := IconDiffReport{
+		MissingIcons:    make([]string, 0),
+		OrphanDrawables: make([]string, 0),
+		UnusedEntries:   make([]globals.Item, 0),
+	}
+
+	available := make(map[string]struct{}, len(localIcons))
+	for _, icon := range localIcons {
+		name := iconDrawableName(icon)
+		available[name] = struct{}{}
+		if _, ok := referenced[name]; !ok {
+			report.OrphanDrawables = append(report.OrphanDrawables, name)
+		}
+	}
+	sort.Strings(report.OrphanDrawables)
+
+	for _, entry := range entries {
+		if _, ok := available[entry.Drawable]; !ok {
+			report.UnusedEntries = append(report.UnusedEntries, entry)
+		}
+	}
+	return report
+}
 
-	return missing, nil
+// DiffOptions controls how appfilter items are matched by DiffItems.
+type DiffOptions struct {
+	// NormalizeComponents matches components by their reader.NormalizeComponent
+	// form, so relative and fully-qualified activity names, stray whitespace
+	// and the case of the ComponentInfo wrapper do not cause false differences.
+	NormalizeComponents bool
+	// IgnoreAppNames compares drawables only, so items whose app names
+	// differ still count as common.
+	IgnoreAppNames bool
+}
+
+// ItemChange describes an item present in both files whose mapping differs.
+// Fields lists the names of the globals.Item fields that changed.
+type ItemChange struct {
+	Component string
+	Before    globals.Item
+	After     globals.Item
+	Fields    []string
+}
+
+// AppFilterDiff is the result of comparing two appfilter item lists.
+type AppFilterDiff struct {
+	// OnlyInFirst holds items that exist only in the first list.
+	OnlyInFirst []globals.Item
+	// OnlyInSecond holds items that exist only in the second list.
+	OnlyInSecond []globals.Item
+	// Common holds identical items present in both lists (taken from the first).
+	Common []globals.Item
+	// Changed holds items present in both lists with a different drawable or
+	// app name.
+	Changed []ItemChange
 }
 
 // DiffAppFilters compares two appfilter.xml files and returns the differences.
-// It returns three slices:
-// - onlyInFirst: items that exist only in the first appfilter.xml
-// - onlyInSecond: items that exist only in the second appfilter.xml
-// - common: items that exist in both files
-func DiffAppFilters(firstPath, secondPath string) (onlyInFirst, onlyInSecond, common []globals.Item, err error) {
+// Items are matched by component and sorted into four categories: only in the
+// first file, only in the second file, common (identical) and changed (same
+// component, different drawable or app name).
+func DiffAppFilters(firstPath, secondPath string, opts DiffOptions) (*AppFilterDiff, error) {
 	// Parse first appfilter.xml
 	firstItems, err := reader.ReadAppFilter(firstPath)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("read first appfilter: %w", err)
+		return nil, fmt.Errorf("read first appfilter: %w", err)
 	}
 
 	// Parse second appfilter.xml
 	secondItems, err := reader.ReadAppFilter(secondPath)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("read second appfilter: %w", err)
+		return nil, fmt.Errorf("read second appfilter: %w", err)
+	}
+
+	return DiffItems(firstItems, secondItems, opts), nil
+}
+
+// DiffItems compares two lists of appfilter items. See DiffAppFilters.
+func DiffItems(firstItems, secondItems []globals.Item, opts DiffOptions) *AppFilterDiff {
+	key := func(item globals.Item) string {
+		if opts.NormalizeComponents {
+			return reader.NormalizeComponent(item.Component)
+		}
+		return item.Component
 	}
 
 	// Build sets for efficient lookup
@@ -85,35 +201,48 @@ func DiffAppFilters(firstPath, secondPath string) (onlyInFirst, onlyInSecond, co
 	secondSet := make(map[string]globals.Item)
 
 	for _, item := range firstItems {
-		firstSet[item.Component] = item
+		firstSet[key(item)] = item
 	}
 
 	for _, item := range secondItems {
-		secondSet[item.Component] = item
+		secondSet[key(item)] = item
 	}
 
-	// Find items only in first
+	diff := &AppFilterDiff{}
+
+	// Find items only in first, common and changed items
 	for _, item := range firstItems {
-		if _, exists := secondSet[item.Component]; !exists {
-			onlyInFirst = append(onlyInFirst, item)
+		secondItem, exists := secondSet[key(item)]
+		if !exists {
+			diff.OnlyInFirst = append(diff.OnlyInFirst, item)
+			continue
 		}
-	}
 
-	// Find items only in second
-	for _, item := range secondItems {
-		if _, exists := firstSet[item.Component]; !exists {
-			onlyInSecond = append(onlyInSecond, item)
+		var fields []string
+		if item.Drawable != secondItem.Drawable {
+			fields = append(fields, "Drawable")
+		}
+		if !opts.IgnoreAppNames && item.AppName != secondItem.AppName {
+			fields = append(fields, "AppName")
+		}
+		if len(fields) == 0 {
+			diff.Common = append(diff.Common, item)
+			continue
 		}
+		diff.Changed = append(diff.Changed, ItemChange{
+			Component: item.Component,
+			Before:    item,
+			After:     secondItem,
+			Fields:    fields,
+		})
 	}
 
-	// Find common items
-	for _, item := range firstItems {
-		if secondItem, exists := secondSet[item.Component]; exists {
-			common = append(common, item)
-			// Note: we use the first file's item for common items
-			_ = secondItem // avoid unused variable warning
+	// Find items only in second
+	for _, item := range secondItems {
+		if _, exists := firstSet[key(item)]; !exists {
+			diff.OnlyInSecond = append(diff.OnlyInSecond, item)
 		}
 	}
 
-	return onlyInFirst, onlyInSecond, common, nil
+	return diff
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"testing"
+
+	"circle-center/globals"
 )
 
 // TestFindMissingIcons tests FindMissingIcons by reading ICON_DIR_PATH and
@@ -38,10 +40,11 @@ func TestDiffAppFilters(t *testing.T) {
 		t.Skip("APPFILTER_PATH or APPFILTER_SECOND_PATH env vars not set; skipping test")
 	}
 
-	onlyInFirst, onlyInSecond, common, err := DiffAppFilters(firstPath, secondPath)
+	diff, err := DiffAppFilters(firstPath, secondPath, DiffOptions{})
 	if err != nil {
 		t.Fatalf("diffing appfilters failed: %v", err)
 	}
+	onlyInFirst, onlyInSecond, common := diff.OnlyInFirst, diff.OnlyInSecond, diff.Common
 
 	fmt.Printf("=== Items only in first file (%s) ===\n", firstPath)
 	for _, item := range onlyInFirst {
@@ -55,9 +58,57 @@ func TestDiffAppFilters(t *testing.T) {
 			item.Component, item.Drawable, item.PackageName, item.ActivityName)
 	}
 
+	fmt.Printf("\n=== Changed items (count: %d) ===\n", len(diff.Changed))
+	for _, change := range diff.Changed {
+		fmt.Printf("component: %s drawable: %s -> %s\n",
+			change.Component, change.Before.Drawable, change.After.Drawable)
+	}
+
 	fmt.Printf("\n=== Common items (count: %d) ===\n", len(common))
 	for _, item := range common {
 		fmt.Printf("component: %s drawable: %s package: %s activity: %s\n",
 			item.Component, item.Drawable, item.PackageName, item.ActivityName)
 	}
 }
+
+func TestDiffItemsChanged(t *testing.T) {
+	first := []globals.Item{
+		{Component: "ComponentInfo{com.example.calc/com.example.calc.Main}", Drawable: "calc", AppName: "Calculator"},
+		{Component: "ComponentInfo{com.example.cam/com.example.cam.Main}", Drawable: "camera", AppName: "Camera"},
+		{Component: "ComponentInfo{com.example.clock/.Main}", Drawable: "clock"},
+	}
+	second := []globals.Item{
+		{Component: "ComponentInfo{com.example.calc/com.example.calc.Main}", Drawable: "calc", AppName: "Calculator"},
+		{Component: "ComponentInfo{com.example.cam/com.example.cam.Main}", Drawable: "camera_alt", AppName: "Camera"},
+		{Component: "componentinfo{ com.example.clock/com.example.clock.Main }", Drawable: "clock"},
+	}
+
+	diff := DiffItems(first, second, DiffOptions{})
+	if len(diff.Common) != 1 || len(diff.Changed) != 1 || len(diff.OnlyInFirst) != 1 || len(diff.OnlyInSecond) != 1 {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+	change := diff.Changed[0]
+	if change.Before.Drawable != "camera" || change.After.Drawable != "camera_alt" {
+		t.Errorf("unexpected change: %+v", change)
+	}
+	if len(change.Fields) != 1 || change.Fields[0] != "Drawable" {
+		t.Errorf("unexpected changed fields: %v", change.Fields)
+	}
+
+	diff = DiffItems(first, second, DiffOptions{NormalizeComponents: true})
+	if len(diff.Common) != 2 || len(diff.OnlyInFirst) != 0 || len(diff.OnlyInSecond) != 0 {
+		t.Fatalf("normalised components should match: %+v", diff)
+	}
+}
+
+func TestDiffItemsIgnoreAppNames(t *testing.T) {
+	first := []globals.Item{{Component: "ComponentInfo{com.calc/com.calc.Main}", Drawable: "calc"}}
+	second := []globals.Item{{Component: "ComponentInfo{com.calc/com.calc.Main}", Drawable: "calc", AppName: "Calculator"}}
+
+	if diff := DiffItems(first, second, DiffOptions{}); len(diff.Changed) != 1 || diff.Changed[0].Fields[0] != "AppName" {
+		t.Fatalf("expected an app name change: %+v", diff)
+	}
+	if diff := DiffItems(first, second, DiffOptions{IgnoreAppNames: true}); len(diff.Common) != 1 {
+		t.Fatalf("app names should be ignored: %+v", diff)
+	}
+}
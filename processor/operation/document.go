@@ -0,0 +1,312 @@
+package operation
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"circle-center/globals"
+	"circle-center/reader"
+)
+
+// AppFilterDocument is an editable appfilter.xml that keeps the source
+// formatting. Comments, blank-line grouping, attribute order, quoting and
+// indentation of untouched items are written back exactly as read, and new
+// items copy the style of the existing ones, so diffs only show the lines
+// that actually changed.
+//
+// Items are addressed by their component attribute. An item's "block" is the
+// item element together with the app name comment directly above it; all
+// operations move or remove blocks as a whole.
+type AppFilterDocument struct {
+	doc   *rawDoc
+	root  *rawNode
+	style docStyle
+}
+
+// OpenAppFilter parses an appfilter.xml from r into an editable document.
+func OpenAppFilter(r io.Reader) (*AppFilterDocument, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read xml: %w", err)
+	}
+
+	doc, err := parseRawDoc(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	root := doc.root("resources")
+	if root == nil {
+		return nil, fmt.Errorf("missing <resources> root element")
+	}
+
+	return &AppFilterDocument{
+		doc:   doc,
+		root:  root,
+		style: detectStyle(src, root, "item"),
+	}, nil
+}
+
+// OpenAppFilterFile opens the appfilter.xml at path for editing.
+func OpenAppFilterFile(path string) (*AppFilterDocument, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return OpenAppFilter(f)
+}
+
+// Items returns the current items in document order. AppName is taken from
+// the comment attached to each item, as reader.ParseFromReader does.
+func (d *AppFilterDocument) Items() []globals.Item {
+	var items []globals.Item
+	for i, n := range d.root.children {
+		if n.kind != nodeElement || n.name != "item" {
+			continue
+		}
+		comp := n.attr("component")
+		pkg, act := reader.ParseComponentInfo(comp)
+		item := globals.Item{
+			Component:    comp,
+			Drawable:     n.attr("drawable"),
+			PackageName:  pkg,
+			ActivityName: act,
+		}
+		if b := blockAt(d.root, i); b.comment != nil {
+			item.AppName = reader.ParseCommentText(b.comment.commentText())
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// Has reports whether an item with the given component exists.
+func (d *AppFilterDocument) Has(component string) bool {
+	return d.indexOf(component) >= 0
+}
+
+// Add appends item after the last existing <item>. It fails if an item with
+// the same component is already present.
+func (d *AppFilterDocument) Add(item globals.Item) error {
+	if d.Has(item.Component) {
+		return fmt.Errorf("component already exists: %s", item.Component)
+	}
+	insertNodes(d.root, d.appendPos(), d.itemNodes(item), d.style)
+	return nil
+}
+
+// InsertBefore inserts item directly above the item with component anchor.
+func (d *AppFilterDocument) InsertBefore(anchor string, item globals.Item) error {
+	if d.Has(item.Component) {
+		return fmt.Errorf("component already exists: %s", item.Component)
+	}
+	i := d.indexOf(anchor)
+	if i < 0 {
+		return fmt.Errorf("component not found: %s", anchor)
+	}
+	insertBlockBefore(d.root, i, d.itemNodes(item), d.style)
+	return nil
+}
+
+// InsertAfter inserts item directly below the item with component anchor.
+func (d *AppFilterDocument) InsertAfter(anchor string, item globals.Item) error {
+	if d.Has(item.Component) {
+		return fmt.Errorf("component already exists: %s", item.Component)
+	}
+	i := d.indexOf(anchor)
+	if i < 0 {
+		return fmt.Errorf("component not found: %s", anchor)
+	}
+	insertNodes(d.root, i+1, d.itemNodes(item), d.style)
+	return nil
+}
+
+// Remove deletes the item with the given component along with its app name
+// comment. When the item heads a group whose next item has no comment of its
+// own (another activity of the same app), the comment stays and names that
+// item instead.
+func (d *AppFilterDocument) Remove(component string) error {
+	i := d.indexOf(component)
+	if i < 0 {
+		return fmt.Errorf("component not found: %s", component)
+	}
+	if d.inheritsComment(i) {
+		cut := i
+		if ch := d.root.children; ch[i-1].isBlank() {
+			cut = i - 1
+		}
+		d.root.children = append(append([]*rawNode{}, d.root.children[:cut]...), d.root.children[i+1:]...)
+		d.root.touch()
+		return nil
+	}
+	removeBlock(d.root, i)
+	return nil
+}
+
+// inheritsComment reports whether the item at index i has an app name
+// comment and is directly followed, within the same line group, by an item
+// without one.
+func (d *AppFilterDocument) inheritsComment(i int) bool {
+	ch := d.root.children
+	if blockAt(d.root, i).comment == nil || i+2 >= len(ch) {
+		return false
+	}
+	sep, next := ch[i+1], ch[i+2]
+	if !sep.isBlank() || newlineCount(sep) > 1 {
+		return false
+	}
+	return next.kind == nodeElement && next.name == "item" && blockAt(d.root, i+2).comment == nil
+}
+
+// Replace rewrites the item with the given component so that it matches
+// item. Only the attribute values and the comment text that differ are
+// touched; attribute order and quoting stay as they were.
+func (d *AppFilterDocument) Replace(component string, item globals.Item) error {
+	i := d.indexOf(component)
+	if i < 0 {
+		return fmt.Errorf("component not found: %s", component)
+	}
+	if item.Component != component && d.Has(item.Component) {
+		return fmt.Errorf("component already exists: %s", item.Component)
+	}
+
+	el := d.root.children[i]
+	el.setAttr("component", item.Component, d.style)
+	el.setAttr("drawable", item.Drawable, d.style)
+
+	b := blockAt(d.root, i)
+	switch {
+	case b.comment != nil && item.AppName == "":
+		// Drop the comment and the whitespace between it and the item.
+		cut := i
+		for cut > 0 && d.root.children[cut-1] != b.comment {
+			cut--
+		}
+		d.root.children = append(d.root.children[:cut-1], d.root.children[i:]...)
+		d.root.touch()
+	case b.comment != nil:
+		if reader.ParseCommentText(b.comment.commentText()) != item.AppName {
+			pad := d.style
+			pad.commentPad = ""
+			if text := b.comment.commentText(); len(text) > 0 && text[0] == ' ' {
+				pad.commentPad = " "
+			}
+			*b.comment = *newCommentNode(item.AppName, pad)
+			b.comment.parent = d.root
+			d.root.touch()
+		}
+	case item.AppName != "":
+		nodes := []*rawNode{newCommentNode(item.AppName, d.style), newTextNode(d.style.newline + d.style.indent)}
+		adopt(d.root, nodes)
+		ch := d.root.children
+		d.root.children = append(append(append([]*rawNode{}, ch[:i]...), nodes...), ch[i:]...)
+		d.root.touch()
+	}
+	return nil
+}
+
+// Move relocates the item with the given component so that it sits directly
+// above the item with component before. An empty before moves the item to
+// the end of the item list.
+func (d *AppFilterDocument) Move(component, before string) error {
+	if component == before {
+		return nil
+	}
+	i := d.indexOf(component)
+	if i < 0 {
+		return fmt.Errorf("component not found: %s", component)
+	}
+	if before != "" && !d.Has(before) {
+		return fmt.Errorf("component not found: %s", before)
+	}
+
+	nodes := removeBlock(d.root, i)
+	if before == "" {
+		insertNodes(d.root, d.appendPos(), nodes, d.style)
+		return nil
+	}
+	insertBlockBefore(d.root, d.indexOf(before), nodes, d.style)
+	return nil
+}
+
+// WriteTo writes the document to w.
+func (d *AppFilterDocument) WriteTo(w io.Writer) (int64, error) {
+	return d.doc.WriteTo(w)
+}
+
+// Bytes returns the serialised document.
+func (d *AppFilterDocument) Bytes() []byte {
+	var buf bytes.Buffer
+	_, _ = d.doc.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// SaveFile writes the document to path, creating parent directories.
+func (d *AppFilterDocument) SaveFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+	if err := os.WriteFile(path, d.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write appfilter xml: %w", err)
+	}
+	return nil
+}
+
+// indexOf returns the child index of the item with the given component, or -1.
+func (d *AppFilterDocument) indexOf(component string) int {
+	for i, n := range d.root.children {
+		if n.kind == nodeElement && n.name == "item" && n.attr("component") == component {
+			return i
+		}
+	}
+	return -1
+}
+
+// appendPos returns the child index right after the last <item>, falling back
+// to after the last element of any kind and finally to the end of the
+// container (before its trailing whitespace).
+func (d *AppFilterDocument) appendPos() int {
+	lastItem, lastEl := -1, -1
+	for i, n := range d.root.children {
+		if n.kind != nodeElement {
+			continue
+		}
+		lastEl = i
+		if n.name == "item" {
+			lastItem = i
+		}
+	}
+	switch {
+	case lastItem >= 0:
+		return lastItem + 1
+	case lastEl >= 0:
+		return lastEl + 1
+	}
+	n := len(d.root.children)
+	if n > 0 && d.root.children[n-1].isBlank() {
+		return n - 1
+	}
+	return n
+}
+
+// itemNodes renders item as an optional app name comment followed by the
+// <item> element, in the document's style.
+func (d *AppFilterDocument) itemNodes(item globals.Item) []*rawNode {
+	el := newEmptyElement("item", [][2]string{
+		{"component", item.Component},
+		{"drawable", item.Drawable},
+	}, d.style)
+	if item.AppName == "" {
+		return []*rawNode{el}
+	}
+	return []*rawNode{
+		newCommentNode(item.AppName, d.style),
+		newTextNode(d.style.newline + d.style.indent),
+		el,
+	}
+}
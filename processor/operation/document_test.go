@@ -0,0 +1,274 @@
+package operation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"circle-center/globals"
+)
+
+const documentSample = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+  <iconback img1="iconback"/>
+  <scale factor="0.8"/>
+
+  <!-- Calculator -->
+  <item component='ComponentInfo{com.example.calc/com.example.calc.Main}' drawable='calc' />
+
+  <!-- Camera -->
+  <item component='ComponentInfo{com.example.cam/com.example.cam.Main}' drawable='camera' />
+  <!-- Clock -->
+  <item component='ComponentInfo{com.example.clock/com.example.clock.Main}' drawable='clock' />
+</resources>
+`
+
+func openSample(t *testing.T) *AppFilterDocument {
+	t.Helper()
+	doc, err := OpenAppFilter(strings.NewReader(documentSample))
+	if err != nil {
+		t.Fatalf("OpenAppFilter: %v", err)
+	}
+	return doc
+}
+
+func TestAppFilterDocumentRoundTrip(t *testing.T) {
+	doc := openSample(t)
+	if got := string(doc.Bytes()); got != documentSample {
+		t.Fatalf("round trip changed the document:\n%s", got)
+	}
+
+	items := doc.Items()
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+	if items[1].AppName != "Camera" || items[1].PackageName != "com.example.cam" {
+		t.Errorf("unexpected item: %+v", items[1])
+	}
+}
+
+func TestAppFilterDocumentAdd(t *testing.T) {
+	doc := openSample(t)
+	item := globals.Item{
+		Component: "ComponentInfo{com.example.maps/com.example.maps.Main}",
+		Drawable:  "maps",
+		AppName:   "Maps",
+	}
+	if err := doc.Add(item); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := doc.Add(item); err == nil {
+		t.Error("expected duplicate Add to fail")
+	}
+
+	want := strings.Replace(documentSample, "drawable='clock' />\n", "drawable='clock' />\n"+
+		"  <!-- Maps -->\n"+
+		"  <item component='ComponentInfo{com.example.maps/com.example.maps.Main}' drawable='maps' />\n", 1)
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestAppFilterDocumentRemove(t *testing.T) {
+	doc := openSample(t)
+	if err := doc.Remove("ComponentInfo{com.example.calc/com.example.calc.Main}"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	// The Camera group keeps the blank line that separated it from the header.
+	want := strings.Replace(documentSample,
+		"  <!-- Calculator -->\n  <item component='ComponentInfo{com.example.calc/com.example.calc.Main}' drawable='calc' />\n\n", "", 1)
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+const groupedSample = `<resources>
+    <!-- A -->
+    <item component="ComponentInfo{a/a.X1}" drawable="x1"/>
+    <item component="ComponentInfo{a/a.X2}" drawable="x2"/>
+
+    <!-- B -->
+    <item component="ComponentInfo{b/b.Y}" drawable="y"/>
+</resources>
+`
+
+func TestAppFilterDocumentRemoveLastItem(t *testing.T) {
+	doc, err := OpenAppFilter(strings.NewReader(groupedSample))
+	if err != nil {
+		t.Fatalf("OpenAppFilter: %v", err)
+	}
+	if err := doc.Remove("ComponentInfo{b/b.Y}"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	want := `<resources>
+    <!-- A -->
+    <item component="ComponentInfo{a/a.X1}" drawable="x1"/>
+    <item component="ComponentInfo{a/a.X2}" drawable="x2"/>
+</resources>
+`
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestAppFilterDocumentMoveLastItem(t *testing.T) {
+	doc, err := OpenAppFilter(strings.NewReader(groupedSample))
+	if err != nil {
+		t.Fatalf("OpenAppFilter: %v", err)
+	}
+	if err := doc.Move("ComponentInfo{b/b.Y}", "ComponentInfo{a/a.X1}"); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+
+	want := `<resources>
+    <!-- B -->
+    <item component="ComponentInfo{b/b.Y}" drawable="y"/>
+    <!-- A -->
+    <item component="ComponentInfo{a/a.X1}" drawable="x1"/>
+    <item component="ComponentInfo{a/a.X2}" drawable="x2"/>
+</resources>
+`
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestAppFilterDocumentRemoveGroupHead(t *testing.T) {
+	doc, err := OpenAppFilter(strings.NewReader(groupedSample))
+	if err != nil {
+		t.Fatalf("OpenAppFilter: %v", err)
+	}
+	if err := doc.Remove("ComponentInfo{a/a.X1}"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	want := strings.Replace(groupedSample, `    <item component="ComponentInfo{a/a.X1}" drawable="x1"/>`+"\n", "", 1)
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+	if items := doc.Items(); items[0].Drawable != "x2" || items[0].AppName != "A" {
+		t.Errorf("expected x2 to keep the app name, got %+v", items[0])
+	}
+}
+
+func TestAppFilterDocumentReplace(t *testing.T) {
+	doc := openSample(t)
+	err := doc.Replace("ComponentInfo{com.example.cam/com.example.cam.Main}", globals.Item{
+		Component: "ComponentInfo{com.example.cam/com.example.cam.Main}",
+		Drawable:  "camera_alt",
+		AppName:   "Camera",
+	})
+	if err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+
+	want := strings.Replace(documentSample, "drawable='camera'", "drawable='camera_alt'", 1)
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	err = doc.Replace("ComponentInfo{com.example.cam/com.example.cam.Main}", globals.Item{
+		Component: "ComponentInfo{com.example.cam/com.example.cam.Main}",
+		Drawable:  "camera_alt",
+		AppName:   "Camera Pro",
+	})
+	if err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	want = strings.Replace(want, "<!-- Camera -->", "<!-- Camera Pro -->", 1)
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestAppFilterDocumentMove(t *testing.T) {
+	doc := openSample(t)
+	clock := "ComponentInfo{com.example.clock/com.example.clock.Main}"
+	if err := doc.Move(clock, "ComponentInfo{com.example.calc/com.example.calc.Main}"); err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+
+	items := doc.Items()
+	if items[0].Component != clock || items[0].AppName != "Clock" {
+		t.Fatalf("expected clock first, got %+v", items[0])
+	}
+	if _, err := OpenAppFilter(strings.NewReader(string(doc.Bytes()))); err != nil {
+		t.Fatalf("output is not valid xml: %v", err)
+	}
+
+	want := strings.Replace(documentSample,
+		"  <!-- Clock -->\n  <item component='ComponentInfo{com.example.clock/com.example.clock.Main}' drawable='clock' />\n", "", 1)
+	want = strings.Replace(want, "\n\n  <!-- Calculator -->", "\n\n  <!-- Clock -->\n"+
+		"  <item component='ComponentInfo{com.example.clock/com.example.clock.Main}' drawable='clock' />\n"+
+		"  <!-- Calculator -->", 1)
+	if got := string(doc.Bytes()); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestInsertItemsToIconPackPreservesLayout(t *testing.T) {
+	const src = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <!-- all icons -->
+    <string-array name="all">
+        <item>calc</item>
+        <item>camera</item>
+    </string-array>
+</resources>
+`
+	path := filepath.Join(t.TempDir(), "icon_pack.xml")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := InsertItemsToIconPack(path, "all", []string{"maps", "calc", "clock"}); err != nil {
+		t.Fatalf("InsertItemsToIconPack: %v", err)
+	}
+	if err := InsertItemsToIconPack(path, "new", []string{"maps"}); err != nil {
+		t.Fatalf("InsertItemsToIconPack: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string-array name="new">
+        <item>maps</item>
+    </string-array>
+    <!-- all icons -->
+    <string-array name="all">
+        <item>maps</item>
+        <item>clock</item>
+        <item>calc</item>
+        <item>camera</item>
+    </string-array>
+</resources>
+`
+	if string(got) != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestSetAttrIgnoresLookalikesInOtherValues(t *testing.T) {
+	src := `<resources><item hint=' drawable="old"' drawable = "old" extra="x"/></resources>`
+	doc, err := parseRawDoc(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("parseRawDoc: %v", err)
+	}
+	item := doc.root("resources").children[0]
+	item.setAttr("drawable", `new "one"`, docStyle{quote: `"`})
+
+	var out strings.Builder
+	if _, err := doc.WriteTo(&out); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	want := `<resources><item hint=' drawable="old"' drawable = "new &quot;one&quot;" extra="x"/></resources>`
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
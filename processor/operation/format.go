@@ -0,0 +1,271 @@
+package operation
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"circle-center/globals"
+	"circle-center/reader"
+)
+
+// FormatSort selects the order of item groups in a formatted appfilter.
+type FormatSort string
+
+const (
+	// SortByAppName orders groups by their app name comment, case-insensitively.
+	SortByAppName FormatSort = "app_name"
+	// SortByPackage orders groups by the smallest package name they contain.
+	SortByPackage FormatSort = "package"
+)
+
+// defaultFormatIndent is the indentation used when FormatOptions.Indent is
+// empty.
+const defaultFormatIndent = "    "
+
+// FormatOptions controls FormatAppFilter.
+type FormatOptions struct {
+	// Sort is the group order; SortByAppName when empty.
+	Sort FormatSort
+	// Indent is the indentation of one level; four spaces when empty.
+	Indent string
+}
+
+// FormatResult summarises a formatting run.
+type FormatResult struct {
+	// Items is the number of items written.
+	Items int
+	// Groups is the number of app name groups written.
+	Groups int
+	// DuplicatesRemoved counts items dropped because an earlier item had the
+	// same component and drawable.
+	DuplicatesRemoved int
+}
+
+// formatGroup is a run of items sharing one app name.
+type formatGroup struct {
+	appName string
+	items   []globals.Item
+}
+
+// FormatAppFilter rewrites the appfilter.xml read from r into one canonical
+// layout and writes it to w:
+//
+//   - <iconback>, <iconmask>, <iconupon> and <scale> come first;
+//   - items are grouped by app name, groups separated by a blank line and
+//     ordered by opts.Sort, items within a group ordered by component and
+//     each preceded by its app name comment;
+//   - items without an app name form a final group without comments;
+//   - <calendar> and <dynamic-clock> elements follow the items;
+//   - exact duplicates (same component and drawable) are dropped;
+//   - components are rewritten with reader.NormalizeComponent.
+//
+// Formatting is idempotent: formatting the output again yields the same bytes.
+// Comments that are not app names and unknown elements are not kept.
+func FormatAppFilter(r io.Reader, w io.Writer, opts FormatOptions) (*FormatResult, error) {
+	af, err := reader.ParseAppFilterDocument(r)
+	if err != nil {
+		return nil, err
+	}
+
+	indent := opts.Indent
+	if indent == "" {
+		indent = defaultFormatIndent
+	}
+
+	result := &FormatResult{}
+	groups := groupItems(af.Items, result)
+	sortGroups(groups, opts.Sort)
+
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	buf.WriteString("<resources>\n")
+
+	section := false
+	startSection := func() {
+		if section {
+			buf.WriteString("\n")
+		}
+		section = true
+	}
+
+	// Header elements
+	if len(af.IconBack)+len(af.IconMask)+len(af.IconUpon) > 0 || af.Scale != 0 {
+		startSection()
+		writeImageElement(&buf, indent, "iconback", af.IconBack)
+		writeImageElement(&buf, indent, "iconmask", af.IconMask)
+		writeImageElement(&buf, indent, "iconupon", af.IconUpon)
+		if af.Scale != 0 {
+			writeElement(&buf, indent, "scale", [][2]string{{"factor", strconv.FormatFloat(af.Scale, 'f', -1, 64)}})
+		}
+	}
+
+	// Item groups
+	for _, g := range groups {
+		startSection()
+		for _, item := range g.items {
+			// Every item carries its own comment so the app name survives
+			// re-parsing; the reader only attaches a comment to the next item.
+			if g.appName != "" {
+				writeComment(&buf, indent, g.appName)
+			}
+			writeElement(&buf, indent, "item", [][2]string{
+				{"component", item.Component},
+				{"drawable", item.Drawable},
+			})
+		}
+		result.Items += len(g.items)
+	}
+	result.Groups = len(groups)
+
+	// Calendars and dynamic clocks
+	if len(af.Calendars) > 0 {
+		startSection()
+		for _, cal := range af.Calendars {
+			if cal.AppName != "" {
+				writeComment(&buf, indent, cal.AppName)
+			}
+			writeElement(&buf, indent, "calendar", [][2]string{
+				{"component", reader.NormalizeComponent(cal.Component)},
+				{"prefix", cal.Prefix},
+			})
+		}
+	}
+	if len(af.DynamicClocks) > 0 {
+		startSection()
+		for _, clock := range af.DynamicClocks {
+			attrs := [][2]string{{"drawable", clock.Drawable}}
+			for _, a := range []struct {
+				name  string
+				value *int
+			}{
+				{"hourLayerIndex", clock.HourLayerIndex},
+				{"minuteLayerIndex", clock.MinuteLayerIndex},
+				{"secondLayerIndex", clock.SecondLayerIndex},
+				{"defaultHour", clock.DefaultHour},
+				{"defaultMinute", clock.DefaultMinute},
+				{"defaultSecond", clock.DefaultSecond},
+			} {
+				if a.value != nil {
+					attrs = append(attrs, [2]string{a.name, strconv.Itoa(*a.value)})
+				}
+			}
+			writeElement(&buf, indent, "dynamic-clock", attrs)
+		}
+	}
+
+	buf.WriteString("</resources>\n")
+
+	if _, err := buf.WriteTo(w); err != nil {
+		return nil, fmt.Errorf("write formatted content: %w", err)
+	}
+	return result, nil
+}
+
+// groupItems normalises components, drops exact duplicates and groups the
+// remaining items by app name. Unnamed items end up in a trailing group.
+func groupItems(items []globals.Item, result *FormatResult) []formatGroup {
+	seen := make(map[string]struct{})
+	index := make(map[string]int)
+	var groups []formatGroup
+	var unnamed []globals.Item
+
+	for _, item := range items {
+		item.Component = reader.NormalizeComponent(item.Component)
+		item.Drawable = strings.TrimSpace(item.Drawable)
+		item.PackageName, item.ActivityName = reader.ParseComponentInfo(item.Component)
+
+		key := item.Component + "\x00" + item.Drawable
+		if _, dup := seen[key]; dup {
+			result.DuplicatesRemoved++
+			continue
+		}
+		seen[key] = struct{}{}
+
+		if item.AppName == "" {
+			unnamed = append(unnamed, item)
+			continue
+		}
+		i, ok := index[item.AppName]
+		if !ok {
+			i = len(groups)
+			index[item.AppName] = i
+			groups = append(groups, formatGroup{appName: item.AppName})
+		}
+		groups[i].items = append(groups[i].items, item)
+	}
+
+	if len(unnamed) > 0 {
+		groups = append(groups, formatGroup{items: unnamed})
+	}
+
+	for _, g := range groups {
+		sort.SliceStable(g.items, func(i, j int) bool {
+			if g.items[i].Component != g.items[j].Component {
+				return g.items[i].Component < g.items[j].Component
+			}
+			return g.items[i].Drawable < g.items[j].Drawable
+		})
+	}
+	return groups
+}
+
+// sortGroups orders named groups by the requested key, keeping the unnamed
+// group last.
+func sortGroups(groups []formatGroup, order FormatSort) {
+	key := func(g formatGroup) string {
+		if order == SortByPackage {
+			smallest := strings.ToLower(g.items[0].PackageName)
+			for _, item := range g.items[1:] {
+				if pkg := strings.ToLower(item.PackageName); pkg < smallest {
+					smallest = pkg
+				}
+			}
+			return smallest
+		}
+		return strings.ToLower(g.appName)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.appName == "") != (b.appName == "") {
+			return b.appName == ""
+		}
+		if ka, kb := key(a), key(b); ka != kb {
+			return ka < kb
+		}
+		return a.appName < b.appName
+	})
+}
+
+// writeElement writes one self-closing element on its own line.
+func writeElement(buf *bytes.Buffer, indent, name string, attrs [][2]string) {
+	buf.WriteString(indent + "<" + name)
+	for _, a := range attrs {
+		buf.WriteString(" " + a[0] + `="` + escapeAttr(a[1], `"`) + `"`)
+	}
+	buf.WriteString(" />\n")
+}
+
+// writeImageElement writes an <iconback>-style element with img1..imgN
+// attributes, or nothing when imgs is empty.
+func writeImageElement(buf *bytes.Buffer, indent, name string, imgs []string) {
+	if len(imgs) == 0 {
+		return
+	}
+	attrs := make([][2]string, len(imgs))
+	for i, img := range imgs {
+		attrs[i] = [2]string{"img" + strconv.Itoa(i+1), img}
+	}
+	writeElement(buf, indent, name, attrs)
+}
+
+// writeComment writes an app name comment on its own line.
+func writeComment(buf *bytes.Buffer, indent, text string) {
+	buf.WriteString(indent)
+	buf.Write(newCommentNode(text, docStyle{commentPad: " "}).raw)
+	buf.WriteString("\n")
+}
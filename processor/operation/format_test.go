@@ -0,0 +1,75 @@
+package operation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const formatInput = `<resources>
+<!-- Zoom -->
+<item component="componentinfo{ us.zoom.videomeetings/.Launcher }" drawable="zoom"/>
+	<item component="ComponentInfo{com.example.orphan/com.example.orphan.Main}" drawable="orphan"/>
+  <scale factor="0.80"/>
+  <!-- Calculator -->
+  <item component="ComponentInfo{com.example.calc/com.example.calc.Main}" drawable="calc"/>
+  <!-- Calculator -->
+  <item component="ComponentInfo{com.example.calc/com.example.calc.Main}" drawable="calc"/>
+  <iconback img1="iconback" img2="iconback_alt"/>
+  <!-- Calculator -->
+  <item component="ComponentInfo{com.example.calc/com.example.calc.Alt}" drawable="calc"/>
+</resources>`
+
+const formatWant = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+  <iconback img1="iconback" img2="iconback_alt" />
+  <scale factor="0.8" />
+
+  <!-- Calculator -->
+  <item component="ComponentInfo{com.example.calc/com.example.calc.Alt}" drawable="calc" />
+  <!-- Calculator -->
+  <item component="ComponentInfo{com.example.calc/com.example.calc.Main}" drawable="calc" />
+
+  <!-- Zoom -->
+  <item component="ComponentInfo{us.zoom.videomeetings/us.zoom.videomeetings.Launcher}" drawable="zoom" />
+
+  <item component="ComponentInfo{com.example.orphan/com.example.orphan.Main}" drawable="orphan" />
+</resources>
+`
+
+func TestFormatAppFilter(t *testing.T) {
+	var out bytes.Buffer
+	result, err := FormatAppFilter(strings.NewReader(formatInput), &out, FormatOptions{Indent: "  "})
+	if err != nil {
+		t.Fatalf("FormatAppFilter: %v", err)
+	}
+	if out.String() != formatWant {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+	if result.Items != 4 || result.Groups != 3 || result.DuplicatesRemoved != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// Formatting is idempotent.
+	var again bytes.Buffer
+	if _, err := FormatAppFilter(strings.NewReader(out.String()), &again, FormatOptions{Indent: "  "}); err != nil {
+		t.Fatalf("FormatAppFilter: %v", err)
+	}
+	if again.String() != out.String() {
+		t.Fatalf("second pass changed the output:\n%s", again.String())
+	}
+}
+
+func TestFormatAppFilterSortByPackage(t *testing.T) {
+	var out bytes.Buffer
+	if _, err := FormatAppFilter(strings.NewReader(formatInput), &out, FormatOptions{Sort: SortByPackage}); err != nil {
+		t.Fatalf("FormatAppFilter: %v", err)
+	}
+	s := out.String()
+	if strings.Index(s, "com.example.calc") > strings.Index(s, "us.zoom") {
+		t.Errorf("expected com.example.calc before us.zoom:\n%s", s)
+	}
+	if !strings.Contains(s, "\n    <!-- Zoom -->\n") {
+		t.Errorf("expected default four-space indent:\n%s", s)
+	}
+}
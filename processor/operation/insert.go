@@ -1,136 +1,182 @@
 package operation
 
 import (
+	"bytes"
+	"encoding/xml"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/beevik/etree"
-
 	"circle-center/globals"
+	"circle-center/reader"
 )
 
 // InsertItemsToIconPack inserts the provided drawable names into the specified
 // <string-array name="arrayName"> inside the icon_pack.xml file located at
 // iconPackPath. If the array does not exist, it will be created. Duplicate
-// items are ignored.
+// items are ignored. New items are placed at the top of the array in the given
+// order; the rest of the file keeps its original formatting.
 func InsertItemsToIconPack(appFilterPath string, arrayName string, newItems []string) error {
-	doc := etree.NewDocument()
-	if err := doc.ReadFromFile(appFilterPath); err != nil {
+	src, err := os.ReadFile(appFilterPath)
+	if err != nil {
+		return fmt.Errorf("read icon pack xml: %w", err)
+	}
+	doc, err := parseRawDoc(bytes.NewReader(src))
+	if err != nil {
 		return fmt.Errorf("read icon pack xml: %w", err)
 	}
 
-	root := doc.SelectElement("resources")
+	root := doc.root("resources")
 	if root == nil {
 		return fmt.Errorf("no <resources> root in %s", appFilterPath)
 	}
+	rootStyle := detectStyle(src, root, "string-array")
 
 	// Locate string-array
-	var target *etree.Element
-	for _, arr := range root.SelectElements("string-array") {
-		if arr.SelectAttrValue("name", "") == arrayName {
-			target = arr
+	var target *rawNode
+	for _, n := range root.children {
+		if n.kind == nodeElement && n.name == "string-array" && n.attr("name") == arrayName {
+			target = n
 			break
 		}
 	}
 
 	// Create if not exists and insert at beginning of root children
 	if target == nil {
-		target = etree.NewElement("string-array")
-		target.CreateAttr("name", arrayName)
-		// Prepend to root children
-		root.InsertChildAt(0, target)
+		target = &rawNode{
+			kind:     nodeElement,
+			name:     "string-array",
+			attrs:    []xml.Attr{{Name: xml.Name{Local: "name"}, Value: arrayName}},
+			startTag: []byte("<string-array name=" + rootStyle.quote + escapeAttr(arrayName, rootStyle.quote) + rootStyle.quote + ">"),
+			endTag:   []byte("</string-array>"),
+		}
+		insertNodes(root, 0, []*rawNode{target}, rootStyle)
+	}
+	if len(target.children) == 0 {
+		// Put the closing tag on its own line.
+		target.children = []*rawNode{newTextNode(rootStyle.newline + rootStyle.indent)}
+		target.touch()
 	}
 
-	// Build set of existing items texts
+	// Build set of existing items texts and find the first one
 	existing := make(map[string]struct{})
-	for _, item := range target.SelectElements("item") {
-		existing[item.Text()] = struct{}{}
+	var first *rawNode
+	for _, n := range target.children {
+		if n.kind == nodeElement && n.name == "item" {
+			existing[n.text()] = struct{}{}
+			if first == nil {
+				first = n
+			}
+		}
 	}
 
-	// Insert new items at beginning of target's child list in given order
-	for i := len(newItems) - 1; i >= 0; i-- { // reverse to keep original order at top
-		it := newItems[i]
+	style := detectStyle(src, target, "item")
+	if first == nil {
+		style.indent = rootStyle.indent + rootStyle.indent
+	}
+
+	// Insert new items at the top of the array in the given order
+	pos := len(target.children)
+	if target.children[pos-1].isBlank() {
+		pos--
+	}
+	for _, it := range newItems {
 		if _, present := existing[it]; present {
 			continue
 		}
-		node := etree.NewElement("item")
-		node.SetText(it)
-		target.InsertChildAt(0, node)
+		existing[it] = struct{}{}
+		node := newTextElement("item", it)
+		if first != nil {
+			insertBlockBefore(target, childIndex(target, first), []*rawNode{node}, style)
+			continue
+		}
+		insertNodes(target, pos, []*rawNode{node}, style)
+		pos += 2
 	}
 
-	doc.Indent(4)
-	if err := doc.WriteToFile(appFilterPath); err != nil {
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return fmt.Errorf("write icon pack xml: %w", err)
+	}
+	if err := os.WriteFile(appFilterPath, buf.Bytes(), 0o644); err != nil {
 		return fmt.Errorf("write icon pack xml: %w", err)
 	}
 
 	return nil
 }
 
+// SyncReport summarises a SyncIconsToAppFilter run.
+type SyncReport struct {
+	// Resolved maps each package whose activities were found in the
+	// component index to the activities that were written.
+	Resolved map[string][]string
+	// Unresolved lists packages nothing is known about; they were written
+	// with a TODO activity.
+	Unresolved []string
+	// Added is the number of <item> elements added.
+	Added int
+}
+
 // SyncIconsToAppFilter finds icons that exist in iconDir but are missing from
 // appfilter.xml (comparing via FindMissingIcons), sanitises filenames via
-// CleanIconFileNames into a "renamed" subdirectory, and inserts corresponding
-// <item> elements into appfilter.xml.
-func SyncIconsToAppFilter(iconDir, appFilterPath string) error {
+// CleanIconFileNames into a "renamed" subdirectory, and appends corresponding
+// <item> elements to appfilter.xml. Activities are looked up in index: one
+// item is written per known activity of a package, and packages the index
+// knows nothing about (or every package, when index is nil) fall back to
+// "ComponentInfo{pkg/TODO}".
+func SyncIconsToAppFilter(iconDir, appFilterPath string, index *reader.ComponentIndex) (*SyncReport, error) {
+	report := &SyncReport{Resolved: make(map[string][]string), Unresolved: make([]string, 0)}
+
 	missingPkgs, err := FindMissingIcons(iconDir, appFilterPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(missingPkgs) == 0 {
-		return nil // nothing to do
+		return report, nil // nothing to do
 	}
 
 	renamedDir := filepath.Join(iconDir, "renamed")
 	// CleanIconFileNames copies **all** icons; we'll filter after copy
-	cleanedIcons, err := CleanIconFileNames(iconDir, renamedDir)
-	if err != nil {
-		return err
-	}
-
-	// Build map for quick lookup of cleaned icons by package name
-	cleanMap := make(map[string]globals.LocalIcon)
-	for _, ic := range cleanedIcons {
-		cleanMap[ic.PackageName] = ic
-	}
-
-	doc := etree.NewDocument()
-	if err := doc.ReadFromFile(appFilterPath); err != nil {
-		return fmt.Errorf("read appfilter xml: %w", err)
-	}
-
-	root := doc.SelectElement("resources")
-	if root == nil {
-		return fmt.Errorf("no <resources> root in appfilter")
+	if _, err := CleanIconFileNames(iconDir, renamedDir); err != nil {
+		return nil, err
 	}
 
-	// Build set of existing components to avoid duplicates
-	existing := make(map[string]struct{})
-	for _, item := range root.SelectElements("item") {
-		comp := item.SelectAttrValue("component", "")
-		existing[comp] = struct{}{}
+	doc, err := OpenAppFilterFile(appFilterPath)
+	if err != nil {
+		return nil, fmt.Errorf("read appfilter xml: %w", err)
 	}
 
-	// Insert new items at beginning (reverse iteration to preserve order)
-	for i := len(missingPkgs) - 1; i >= 0; i-- {
-		pkg := missingPkgs[i]
+	// Append new items after the existing ones, skipping duplicates
+	for _, pkg := range missingPkgs {
 		drawableName := strings.ReplaceAll(pkg, ".", "_")
-		componentStr := fmt.Sprintf("ComponentInfo{%s/TODO}", pkg)
 
-		if _, ok := existing[componentStr]; ok {
-			continue
+		var activities []string
+		if index != nil {
+			activities = index.Activities(pkg)
+		}
+		if len(activities) == 0 {
+			report.Unresolved = append(report.Unresolved, pkg)
+			activities = []string{"TODO"}
+		} else {
+			report.Resolved[pkg] = activities
 		}
 
-		newItem := etree.NewElement("item")
-		newItem.CreateAttr("component", componentStr)
-		newItem.CreateAttr("drawable", drawableName)
-
-		root.InsertChildAt(0, newItem)
+		for _, act := range activities {
+			componentStr := fmt.Sprintf("ComponentInfo{%s/%s}", pkg, act)
+			if doc.Has(componentStr) {
+				continue
+			}
+			if err := doc.Add(globals.Item{Component: componentStr, Drawable: drawableName}); err != nil {
+				return nil, fmt.Errorf("add item: %w", err)
+			}
+			report.Added++
+		}
 	}
 
-	doc.Indent(4)
-	if err := doc.WriteToFile(appFilterPath); err != nil {
-		return fmt.Errorf("write appfilter xml: %w", err)
+	if err := doc.SaveFile(appFilterPath); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return report, nil
 }
@@ -2,7 +2,11 @@ package operation
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"circle-center/reader"
 )
 
 // TestSyncIconsToAppFilter orchestrates the full sync flow using environment
@@ -16,7 +20,57 @@ func TestSyncIconsToAppFilter(t *testing.T) {
 		t.Skip("Required environment variables not set; skipping test")
 	}
 
-	if err := SyncIconsToAppFilter(iconDir, appFilter); err != nil {
+	if _, err := SyncIconsToAppFilter(iconDir, appFilter, nil); err != nil {
 		t.Fatalf("sync icons to appfilter failed: %v", err)
 	}
 }
+
+func TestSyncIconsToAppFilterResolvesActivities(t *testing.T) {
+	dir := t.TempDir()
+	iconDir := filepath.Join(dir, "icons")
+	if err := os.MkdirAll(iconDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"com.example.cam.png", "com.example.notes.png"} {
+		if err := os.WriteFile(filepath.Join(iconDir, name), []byte("png"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	appFilter := filepath.Join(dir, "appfilter.xml")
+	if err := os.WriteFile(appFilter, []byte("<resources>\n</resources>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	index := reader.NewComponentIndex(0)
+	index.Add("ComponentInfo{com.example.cam/.Main}")
+	index.Add("com.example.cam/com.example.cam.Shortcut")
+	index.Add("com.example.cam/com.example.cam.Main")
+
+	report, err := SyncIconsToAppFilter(iconDir, appFilter, index)
+	if err != nil {
+		t.Fatalf("sync icons to appfilter failed: %v", err)
+	}
+	if got := strings.Join(report.Resolved["com.example.cam"], ","); got != "com.example.cam.Main,com.example.cam.Shortcut" {
+		t.Errorf("unexpected resolved activities: %q", got)
+	}
+	if len(report.Unresolved) != 1 || report.Unresolved[0] != "com.example.notes" || report.Added != 3 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	items, err := reader.ParseAppFilterFile(appFilter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var components []string
+	for _, item := range items {
+		components = append(components, item.Component)
+	}
+	want := []string{
+		"ComponentInfo{com.example.cam/com.example.cam.Main}",
+		"ComponentInfo{com.example.cam/com.example.cam.Shortcut}",
+		"ComponentInfo{com.example.notes/TODO}",
+	}
+	if strings.Join(components, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected components:\n%s", strings.Join(components, "\n"))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"circle-center/globals"
@@ -51,6 +52,34 @@ func CleanIconFileNames(srcDir, dstDir string) ([]globals.LocalIcon, error) {
 	return results, nil
 }
 
+// illegalResChars matches runs of characters that may not appear in an
+// Android file-based resource name.
+var illegalResChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// resourceNamePattern matches names aapt accepts for file-based resources:
+// lowercase letters, digits and underscores, starting with a letter.
+var resourceNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// SanitizeResourceName turns s into a legal drawable name: lower case, every
+// run of other characters replaced by '_', and "ic_" prepended when the result
+// would not start with a letter. Dots in package names become underscores, so
+// "com.example.App" yields "com_example_app".
+func SanitizeResourceName(s string) string {
+	name := strings.Trim(illegalResChars.ReplaceAllString(strings.ToLower(s), "_"), "_")
+	if name == "" {
+		return ""
+	}
+	if name[0] < 'a' || name[0] > 'z' {
+		name = "ic_" + name
+	}
+	return name
+}
+
+// IsResourceName reports whether name is a valid file-based resource name.
+func IsResourceName(name string) bool {
+	return resourceNamePattern.MatchString(name)
+}
+
 // copyFile copies a file from src to dst paths.
 func copyFile(src, dst string) error {
 	in, err := os.Open(src)
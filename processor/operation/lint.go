@@ -0,0 +1,164 @@
+package operation
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"circle-center/globals"
+	"circle-center/reader"
+)
+
+// Severity classifies how serious a lint finding is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+	// SeverityOff disables a rule when used in LintConfig.Rules.
+	SeverityOff Severity = "off"
+)
+
+// Lint rule identifiers.
+const (
+	RuleDuplicateComponent  = "duplicate-component"
+	RuleMalformedComponent  = "malformed-component"
+	RuleInvalidDrawableName = "invalid-drawable-name"
+	RuleMissingAppName      = "missing-app-name"
+	RuleMissingFromIconPack = "missing-from-icon-pack"
+)
+
+// DefaultLintRules holds the severity every rule runs with unless overridden.
+var DefaultLintRules = map[string]Severity{
+	RuleDuplicateComponent:  SeverityError,
+	RuleMalformedComponent:  SeverityError,
+	RuleInvalidDrawableName: SeverityError,
+	RuleMissingAppName:      SeverityWarning,
+	RuleMissingFromIconPack: SeverityWarning,
+}
+
+// LintConfig controls which rules run and with which severity. Rules maps a
+// rule identifier to a severity; SeverityOff disables the rule. Rules not
+// listed keep their DefaultLintRules severity.
+type LintConfig struct {
+	Rules map[string]Severity
+	// IconPack, when set, enables the missing-from-icon-pack rule.
+	IconPack *globals.IconPackResources
+}
+
+// LintFinding is a single diagnostic produced by LintAppFilter.
+type LintFinding struct {
+	Rule      string
+	Severity  Severity
+	Line      int
+	Component string
+	Drawable  string
+	Message   string
+}
+
+// LintAppFilter streams an appfilter.xml from r and checks every item against
+// the configured rules. Findings are ordered by line number. An error is only
+// returned when the XML itself cannot be read or the configuration is invalid.
+func LintAppFilter(r io.Reader, cfg LintConfig) ([]LintFinding, error) {
+	rules, err := resolveLintRules(cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	var packDrawables map[string]struct{}
+	if cfg.IconPack != nil {
+		packDrawables = make(map[string]struct{})
+		for _, arr := range cfg.IconPack.Arrays {
+			for _, d := range arr.Items {
+				packDrawables[d] = struct{}{}
+			}
+		}
+	}
+
+	findings := make([]LintFinding, 0)
+	report := func(rule string, line int, it globals.Item, msg string) {
+		sev := rules[rule]
+		if sev == SeverityOff {
+			return
+		}
+		findings = append(findings, LintFinding{
+			Rule:      rule,
+			Severity:  sev,
+			Line:      line,
+			Component: it.Component,
+			Drawable:  it.Drawable,
+			Message:   msg,
+		})
+	}
+
+	firstSeen := make(map[string]int)
+	err = reader.StreamAppFilterLines(r, func(it globals.Item, line int) error {
+		if first, ok := firstSeen[it.Component]; ok {
+			report(RuleDuplicateComponent, line, it, fmt.Sprintf("component already declared on line %d", first))
+		} else {
+			firstSeen[it.Component] = line
+		}
+
+		if it.PackageName == "" || it.ActivityName == "" {
+			report(RuleMalformedComponent, line, it, "component is not a valid ComponentInfo{package/activity} string")
+		}
+
+		switch {
+		case it.Drawable == "":
+			report(RuleInvalidDrawableName, line, it, "drawable attribute is empty")
+		case !IsResourceName(it.Drawable):
+			report(RuleInvalidDrawableName, line, it, fmt.Sprintf("drawable %q is not a valid Android resource name ([a-z][a-z0-9_]*)", it.Drawable))
+		}
+
+		if strings.TrimSpace(it.AppName) == "" {
+			report(RuleMissingAppName, line, it, "item has no preceding app name comment")
+		}
+
+		if packDrawables != nil && it.Drawable != "" {
+			if _, ok := packDrawables[it.Drawable]; !ok {
+				report(RuleMissingFromIconPack, line, it, fmt.Sprintf("drawable %q is not listed in icon_pack.xml", it.Drawable))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Line < findings[j].Line })
+	return findings, nil
+}
+
+// resolveLintRules merges user overrides onto DefaultLintRules and rejects
+// unknown rules or severities.
+func resolveLintRules(overrides map[string]Severity) (map[string]Severity, error) {
+	rules := make(map[string]Severity, len(DefaultLintRules))
+	for k, v := range DefaultLintRules {
+		rules[k] = v
+	}
+	for rule, sev := range overrides {
+		if _, ok := rules[rule]; !ok {
+			return nil, fmt.Errorf("unknown lint rule: %s", rule)
+		}
+		switch sev {
+		case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+			rules[rule] = sev
+		default:
+			return nil, fmt.Errorf("invalid severity %q for rule %s", sev, rule)
+		}
+	}
+	return rules, nil
+}
+
+// CountFindings returns how many findings have the given severity.
+func CountFindings(findings []LintFinding, sev Severity) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity == sev {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,84 @@
+package operation
+
+import (
+	"strings"
+	"testing"
+
+	"circle-center/globals"
+)
+
+const lintSample = `<resources>
+    <!-- Browser -->
+    <item component="ComponentInfo{com.android.browser/com.android.browser.BrowserActivity}" drawable="browser"/>
+    <!-- Browser again -->
+    <item component="ComponentInfo{com.android.browser/com.android.browser.BrowserActivity}" drawable="browser"/>
+    <!-- Broken -->
+    <item component="com.broken/.Main" drawable="Broken.Icon"/>
+    <item component="ComponentInfo{com.camera/.Camera}" drawable="camera"/>
+</resources>`
+
+func TestLintAppFilter(t *testing.T) {
+	pack := &globals.IconPackResources{Arrays: []globals.StringArray{{Name: "all", Items: []string{"browser"}}}}
+
+	findings, err := LintAppFilter(strings.NewReader(lintSample), LintConfig{IconPack: pack})
+	if err != nil {
+		t.Fatalf("lint appfilter: %v", err)
+	}
+
+	got := make(map[string][]int)
+	for _, f := range findings {
+		got[f.Rule] = append(got[f.Rule], f.Line)
+	}
+
+	expect := map[string][]int{
+		RuleDuplicateComponent:  {5},
+		RuleMalformedComponent:  {7},
+		RuleInvalidDrawableName: {7},
+		RuleMissingAppName:      {8},
+		RuleMissingFromIconPack: {7, 8},
+	}
+	for rule, lines := range expect {
+		if len(got[rule]) != len(lines) {
+			t.Errorf("%s: expected lines %v, got %v", rule, lines, got[rule])
+			continue
+		}
+		for i := range lines {
+			if got[rule][i] != lines[i] {
+				t.Errorf("%s: expected lines %v, got %v", rule, lines, got[rule])
+				break
+			}
+		}
+	}
+
+	for i := 1; i < len(findings); i++ {
+		if findings[i].Line < findings[i-1].Line {
+			t.Fatalf("findings not sorted by line: %+v", findings)
+		}
+	}
+}
+
+func TestLintAppFilterRuleConfig(t *testing.T) {
+	findings, err := LintAppFilter(strings.NewReader(lintSample), LintConfig{Rules: map[string]Severity{
+		RuleMissingAppName:     SeverityOff,
+		RuleDuplicateComponent: SeverityInfo,
+	}})
+	if err != nil {
+		t.Fatalf("lint appfilter: %v", err)
+	}
+
+	for _, f := range findings {
+		if f.Rule == RuleMissingAppName {
+			t.Errorf("disabled rule reported: %+v", f)
+		}
+		if f.Rule == RuleDuplicateComponent && f.Severity != SeverityInfo {
+			t.Errorf("expected overridden severity info, got %s", f.Severity)
+		}
+		if f.Rule == RuleMissingFromIconPack {
+			t.Errorf("icon pack rule should not run without icon pack: %+v", f)
+		}
+	}
+
+	if _, err := LintAppFilter(strings.NewReader(lintSample), LintConfig{Rules: map[string]Severity{"nope": SeverityError}}); err == nil {
+		t.Error("expected error for unknown rule")
+	}
+}
@@ -1,13 +1,12 @@
 package operation
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
-	"github.com/beevik/etree"
-
 	"circle-center/globals"
 	"circle-center/reader"
 )
@@ -49,33 +48,83 @@ type MergeResult struct {
 }
 
 // MergeAppFilters merges two appfilter.xml files according to the specified mode
-// and selection criteria. It preserves the XML structure and comments of the
-// target file while adding new items from the source file.
+// and selection criteria. The target file is edited as an AppFilterDocument, so
+// its comments, grouping and indentation are kept and new items are appended
+// after the existing ones. The output file is only written once the merge has
+// succeeded.
 func MergeAppFilters(req MergeRequest) (*MergeResult, error) {
-	// Read both files
-	firstItems, err := reader.ReadAppFilter(req.FirstFile)
+	var buf bytes.Buffer
+	result, err := mergeFiles(req, &buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(req.OutputFile), 0o755); err != nil {
+		return nil, fmt.Errorf("create output directory: %w", err)
+	}
+	if err := os.WriteFile(req.OutputFile, buf.Bytes(), 0o644); err != nil {
+		return nil, fmt.Errorf("write merged content: %w", err)
+	}
+
+	return result, nil
+}
+
+// MergeAppFiltersInMemory performs the merge operation in memory without writing
+// to a file. This is useful for API responses where we want to return the merged
+// content directly. req.OutputFile is ignored.
+func MergeAppFiltersInMemory(req MergeRequest) (*MergeResult, string, error) {
+	var buf bytes.Buffer
+	result, err := mergeFiles(req, &buf)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, buf.String(), nil
+}
+
+// mergeFiles opens req.FirstFile and req.SecondFile and merges them into w.
+func mergeFiles(req MergeRequest, w io.Writer) (*MergeResult, error) {
+	first, err := os.Open(req.FirstFile)
 	if err != nil {
 		return nil, fmt.Errorf("read first file: %w", err)
 	}
+	defer first.Close()
 
-	secondItems, err := reader.ReadAppFilter(req.SecondFile)
+	second, err := os.Open(req.SecondFile)
 	if err != nil {
 		return nil, fmt.Errorf("read second file: %w", err)
 	}
+	defer second.Close()
+
+	return MergeAppFilterReaders(first, second, w, req)
+}
 
-	// Determine source and target items based on merge direction
-	var sourceItems, targetItems []globals.Item
+// MergeAppFilterReaders merges the appfilter.xml read from first and second
+// and writes the merged document to w. Only Mode, SelectedComponents and
+// MergeIntoFirst of req are used; the file paths are ignored. Nothing is
+// written to w if the merge fails.
+func MergeAppFilterReaders(first, second io.Reader, w io.Writer, req MergeRequest) (*MergeResult, error) {
+	// Determine source and target based on merge direction
+	source, target := first, second
+	sourceName, targetName := "first", "second"
 	if req.MergeIntoFirst {
-		sourceItems = secondItems
-		targetItems = firstItems
-	} else {
-		sourceItems = firstItems
-		targetItems = secondItems
+		source, target = second, first
+		sourceName, targetName = "second", "first"
+	}
+
+	sourceItems, err := reader.ParseFromReader(source)
+	if err != nil {
+		return nil, fmt.Errorf("read %s file: %w", sourceName, err)
+	}
+
+	doc, err := OpenAppFilter(target)
+	if err != nil {
+		return nil, fmt.Errorf("read %s file: %w", targetName, err)
 	}
 
 	// Build set of existing components in target
 	existingComponents := make(map[string]struct{})
-	for _, item := range targetItems {
+	for _, item := range doc.Items() {
 		existingComponents[item.Component] = struct{}{}
 	}
 
@@ -85,21 +134,6 @@ func MergeAppFilters(req MergeRequest) (*MergeResult, error) {
 		FailedItems: make([]globals.Item, 0),
 	}
 
-	// Create new XML document
-	doc := etree.NewDocument()
-	targetFile := req.SecondFile
-	if req.MergeIntoFirst {
-		targetFile = req.FirstFile
-	}
-	if err := doc.ReadFromFile(targetFile); err != nil {
-		return nil, fmt.Errorf("read target file: %w", err)
-	}
-
-	root := doc.SelectElement("resources")
-	if root == nil {
-		return nil, fmt.Errorf("missing resources element in target file")
-	}
-
 	// Process source items
 	for _, item := range sourceItems {
 		// Skip if component already exists in target
@@ -126,67 +160,21 @@ func MergeAppFilters(req MergeRequest) (*MergeResult, error) {
 			continue
 		}
 
-		// Create new item element
-		newItem := etree.NewElement("item")
-		newItem.CreateAttr("component", item.Component)
-		newItem.CreateAttr("drawable", item.Drawable)
-
-		// If there's an app name, add it as a comment before the item
-		if item.AppName != "" {
-			comment := etree.NewComment(" " + item.AppName + " ")
-			root.InsertChild(root.SelectElement("item"), comment)
+		// New items go after the last existing item, in source order, with
+		// their app name comment.
+		if err := doc.Add(item); err != nil {
+			return nil, fmt.Errorf("add item: %w", err)
 		}
-
-		// Add the new item at the beginning of resources
-		root.InsertChild(root.SelectElement("item"), newItem)
 		result.ItemsMerged++
 		existingComponents[item.Component] = struct{}{}
 	}
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(req.OutputFile), 0o755); err != nil {
-		return nil, fmt.Errorf("create output directory: %w", err)
-	}
-
-	// Write the merged file
-	f, err := os.Create(req.OutputFile)
-	if err != nil {
-		return nil, fmt.Errorf("create output file: %w", err)
-	}
-	defer f.Close()
-
-	// Write XML declaration
-	if _, err := io.WriteString(f, `<?xml version="1.0" encoding="utf-8"?>`+"\n"); err != nil {
-		return nil, fmt.Errorf("write xml declaration: %w", err)
-	}
-
-	// Write the document with indentation
-	doc.Indent(2)
-	if _, err := doc.WriteTo(f); err != nil {
+	// Keep the target's own declaration; only add one if it had none.
+	doc.doc.ensureDeclaration()
+	if _, err := doc.WriteTo(w); err != nil {
 		return nil, fmt.Errorf("write merged content: %w", err)
 	}
 
 	result.TotalItems = len(existingComponents)
 	return result, nil
 }
-
-// MergeAppFiltersInMemory performs the merge operation in memory without writing
-// to a file. This is useful for API responses where we want to return the merged
-// content directly.
-func MergeAppFiltersInMemory(req MergeRequest) (*MergeResult, string, error) {
-	result, err := MergeAppFilters(req)
-	if err != nil {
-		return nil, "", err
-	}
-
-	// Read the output file
-	content, err := os.ReadFile(req.OutputFile)
-	if err != nil {
-		return nil, "", fmt.Errorf("read merged file: %w", err)
-	}
-
-	// Clean up the temporary output file
-	os.Remove(req.OutputFile)
-
-	return result, string(content), nil
-}
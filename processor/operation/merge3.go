@@ -0,0 +1,288 @@
+package operation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"circle-center/globals"
+	"circle-center/reader"
+)
+
+// ConflictKind describes why a component could not be merged automatically.
+type ConflictKind string
+
+const (
+	// ConflictModifyModify means both sides changed the drawable of an item
+	// that exists in the base.
+	ConflictModifyModify ConflictKind = "modify/modify"
+	// ConflictAddAdd means both sides added the same component with different
+	// drawables.
+	ConflictAddAdd ConflictKind = "add/add"
+	// ConflictDeleteModify means ours deleted an item that theirs changed.
+	ConflictDeleteModify ConflictKind = "delete/modify"
+	// ConflictModifyDelete means ours changed an item that theirs deleted.
+	ConflictModifyDelete ConflictKind = "modify/delete"
+)
+
+// Conflict markers written around unresolved items when Merge3Options.Markers
+// is set, in the same layout git uses.
+const (
+	markerOurs   = "<<<<<<< ours"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>> theirs"
+)
+
+// Merge3Options controls MergeThreeWay.
+type Merge3Options struct {
+	// Markers writes both versions of every conflicting item into the output,
+	// surrounded by git-style conflict markers. The output is then no longer
+	// valid XML and must be resolved by hand. When false, conflicts keep the
+	// "ours" version.
+	Markers bool
+}
+
+// Merge3Conflict is one component that changed incompatibly on both sides.
+// Base, Ours and Theirs are nil when the item is absent from that version.
+type Merge3Conflict struct {
+	Component string
+	Kind      ConflictKind
+	Base      *globals.Item
+	Ours      *globals.Item
+	Theirs    *globals.Item
+}
+
+// Merge3Result summarises a three-way merge. Added, Removed and Changed list
+// the changes from "theirs" that were applied on top of "ours".
+type Merge3Result struct {
+	Added      []globals.Item
+	Removed    []globals.Item
+	Changed    []globals.Item
+	Conflicts  []Merge3Conflict
+	TotalItems int
+}
+
+// MergeThreeWay merges two appfilter.xml versions (ours and theirs) that were
+// both derived from base. Items are keyed by component. A change made on only
+// one side is taken as is; identical changes on both sides are taken once.
+// The same component mapped to different drawables, or deleted on one side and
+// changed (drawable or app name) on the other, is reported as a conflict.
+//
+// The merged document is built by editing ours in place, so its comments and
+// layout are kept and items added by theirs are placed next to their
+// neighbours from theirs.
+func MergeThreeWay(base, ours, theirs io.Reader, opts Merge3Options) (*Merge3Result, []byte, error) {
+	baseItems, err := reader.ParseFromReader(base)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read base: %w", err)
+	}
+	theirItems, err := reader.ParseFromReader(theirs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read theirs: %w", err)
+	}
+	doc, err := OpenAppFilter(ours)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read ours: %w", err)
+	}
+	ourItems := doc.Items()
+
+	baseMap := indexItems(baseItems)
+	ourMap := indexItems(ourItems)
+	theirMap := indexItems(theirItems)
+
+	result := &Merge3Result{
+		Added:     make([]globals.Item, 0),
+		Removed:   make([]globals.Item, 0),
+		Changed:   make([]globals.Item, 0),
+		Conflicts: make([]Merge3Conflict, 0),
+	}
+	conflicted := make(map[string]bool)
+	addConflict := func(comp string, b, o, t *globals.Item) {
+		conflict := Merge3Conflict{Component: comp, Base: b, Ours: o, Theirs: t}
+		switch {
+		case b == nil:
+			conflict.Kind = ConflictAddAdd
+		case o == nil:
+			conflict.Kind = ConflictDeleteModify
+		case t == nil:
+			conflict.Kind = ConflictModifyDelete
+		default:
+			conflict.Kind = ConflictModifyModify
+		}
+		result.Conflicts = append(result.Conflicts, conflict)
+		conflicted[comp] = true
+	}
+
+	// Walk every component once, ours first so the report follows the
+	// layout of the merged file.
+	seen := make(map[string]struct{})
+	var order []string
+	for _, list := range [][]globals.Item{ourItems, theirItems, baseItems} {
+		for _, it := range list {
+			if _, ok := seen[it.Component]; ok {
+				continue
+			}
+			seen[it.Component] = struct{}{}
+			order = append(order, it.Component)
+		}
+	}
+
+	for _, comp := range order {
+		b, o, t := baseMap[comp], ourMap[comp], theirMap[comp]
+		switch {
+		case sameMapping(o, t):
+			// Both sides map the component the same way. Take a renamed
+			// app name from theirs if ours left it untouched.
+			baseName := ""
+			if b != nil {
+				baseName = b.AppName
+			}
+			if o != nil && o.AppName == baseName && t.AppName != o.AppName {
+				merged := *o
+				merged.AppName = t.AppName
+				if err := doc.Replace(comp, merged); err != nil {
+					return nil, nil, err
+				}
+				result.Changed = append(result.Changed, merged)
+			}
+		case sameMapping(o, b):
+			// Only theirs changed the mapping of this component.
+			switch {
+			case t == nil && o.AppName != b.AppName:
+				// Ours renamed what theirs deleted.
+				addConflict(comp, b, o, t)
+			case t == nil:
+				if err := doc.Remove(comp); err != nil {
+					return nil, nil, err
+				}
+				result.Removed = append(result.Removed, *o)
+			case o == nil:
+				// Added by theirs; placed below.
+			default:
+				merged := *t
+				if o.AppName != b.AppName {
+					// Keep the app name ours renamed.
+					merged.AppName = o.AppName
+				}
+				if err := doc.Replace(comp, merged); err != nil {
+					return nil, nil, err
+				}
+				result.Changed = append(result.Changed, merged)
+			}
+		case sameMapping(t, b):
+			// Only ours changed this component; ours is already in place,
+			// unless ours deleted what theirs renamed.
+			if o == nil && t.AppName != b.AppName {
+				addConflict(comp, b, o, t)
+			}
+		default:
+			addConflict(comp, b, o, t)
+		}
+	}
+
+	// Insert items added by theirs after the closest preceding item from
+	// theirs that is already present, so new items land in the same group.
+	prev := ""
+	for _, it := range theirItems {
+		if doc.Has(it.Component) {
+			prev = it.Component
+			continue
+		}
+		if _, inBase := baseMap[it.Component]; inBase || conflicted[it.Component] {
+			continue
+		}
+		var err error
+		if prev != "" {
+			err = doc.InsertAfter(prev, it)
+		} else {
+			err = doc.Add(it)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		result.Added = append(result.Added, it)
+		prev = it.Component
+	}
+
+	result.TotalItems = len(doc.Items())
+
+	if opts.Markers {
+		for _, c := range result.Conflicts {
+			doc.markConflict(c.Component, c.Ours, c.Theirs)
+		}
+	}
+
+	return result, doc.Bytes(), nil
+}
+
+// indexItems maps each component to its first item.
+func indexItems(items []globals.Item) map[string]*globals.Item {
+	m := make(map[string]*globals.Item, len(items))
+	for i := range items {
+		if _, ok := m[items[i].Component]; !ok {
+			m[items[i].Component] = &items[i]
+		}
+	}
+	return m
+}
+
+// sameMapping reports whether a and b map to the same drawable (or are both
+// absent). App names are not compared: a comment renamed on both sides is
+// never a conflict, but MergeThreeWay still checks them for delete/modify.
+func sameMapping(a, b *globals.Item) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Drawable == b.Drawable
+}
+
+// markConflict replaces the block of component (or, if ours no longer has it,
+// appends a new block) with both versions wrapped in conflict markers.
+func (d *AppFilterDocument) markConflict(component string, ours, theirs *globals.Item) {
+	nl := d.style.newline
+	var sb strings.Builder
+	sb.WriteString(markerOurs + nl)
+	d.writeMarkerSide(&sb, ours)
+	sb.WriteString(markerSep + nl)
+	d.writeMarkerSide(&sb, theirs)
+	sb.WriteString(markerTheirs)
+	node := &rawNode{kind: nodeOther, raw: []byte(sb.String()), parent: d.root}
+
+	i := d.indexOf(component)
+	if i < 0 {
+		// Keep appended conflicts in report order at the end of the file.
+		ch := d.root.children
+		pos := len(ch)
+		if pos > 0 && ch[pos-1].isBlank() {
+			pos--
+		}
+		d.root.children = append(append(append([]*rawNode{}, ch[:pos]...), newTextNode(nl), node), ch[pos:]...)
+		d.root.touch()
+		return
+	}
+
+	// Markers start at column 0, so drop the indentation of the lead.
+	b := blockAt(d.root, i)
+	lead := nl
+	if b.lead != nil {
+		lead = strings.TrimRight(string(b.lead.raw), " \t")
+	}
+	ch := d.root.children
+	d.root.children = append(append(append([]*rawNode{}, ch[:b.start]...), newTextNode(lead), node), ch[b.end+1:]...)
+	d.root.touch()
+}
+
+// writeMarkerSide writes one side of a conflict, one node per line.
+func (d *AppFilterDocument) writeMarkerSide(sb *strings.Builder, item *globals.Item) {
+	if item == nil {
+		return
+	}
+	for _, n := range d.itemNodes(*item) {
+		if n.kind == nodeText {
+			continue
+		}
+		sb.WriteString(d.style.indent)
+		sb.Write(n.raw)
+		sb.WriteString(d.style.newline)
+	}
+}
@@ -0,0 +1,127 @@
+package operation
+
+import (
+	"strings"
+	"testing"
+)
+
+const merge3Base = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <!-- Calculator -->
+    <item component="ComponentInfo{com.example.calc/com.example.calc.Main}" drawable="calc" />
+    <!-- Camera -->
+    <item component="ComponentInfo{com.example.cam/com.example.cam.Main}" drawable="camera" />
+    <!-- Clock -->
+    <item component="ComponentInfo{com.example.clock/com.example.clock.Main}" drawable="clock" />
+    <!-- Maps -->
+    <item component="ComponentInfo{com.example.maps/com.example.maps.Main}" drawable="maps" />
+</resources>
+`
+
+func TestMergeThreeWayAutoResolves(t *testing.T) {
+	// Ours renames the clock drawable; theirs removes maps and adds a
+	// gallery after the camera.
+	ours := strings.Replace(merge3Base, `drawable="clock"`, `drawable="clock_alt"`, 1)
+	theirs := strings.Replace(merge3Base,
+		"    <!-- Maps -->\n    <item component=\"ComponentInfo{com.example.maps/com.example.maps.Main}\" drawable=\"maps\" />\n", "", 1)
+	theirs = strings.Replace(theirs, `drawable="camera" />`+"\n", `drawable="camera" />`+"\n"+
+		`    <!-- Gallery -->`+"\n"+
+		`    <item component="ComponentInfo{com.example.gallery/com.example.gallery.Main}" drawable="gallery" />`+"\n", 1)
+
+	result, content, err := MergeThreeWay(strings.NewReader(merge3Base), strings.NewReader(ours), strings.NewReader(theirs), Merge3Options{})
+	if err != nil {
+		t.Fatalf("MergeThreeWay: %v", err)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %+v", result.Conflicts)
+	}
+	if len(result.Added) != 1 || len(result.Removed) != 1 || result.TotalItems != 4 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	want := strings.Replace(theirs, `drawable="clock"`, `drawable="clock_alt"`, 1)
+	if string(content) != want {
+		t.Fatalf("unexpected output:\n%s", content)
+	}
+}
+
+func TestMergeThreeWayConflicts(t *testing.T) {
+	ours := strings.Replace(merge3Base, `drawable="camera"`, `drawable="camera_ours"`, 1)
+	ours = strings.Replace(ours,
+		"    <!-- Maps -->\n    <item component=\"ComponentInfo{com.example.maps/com.example.maps.Main}\" drawable=\"maps\" />\n", "", 1)
+	theirs := strings.Replace(merge3Base, `drawable="camera"`, `drawable="camera_theirs"`, 1)
+	theirs = strings.Replace(theirs, `drawable="maps"`, `drawable="maps_v2"`, 1)
+
+	result, content, err := MergeThreeWay(strings.NewReader(merge3Base), strings.NewReader(ours), strings.NewReader(theirs), Merge3Options{})
+	if err != nil {
+		t.Fatalf("MergeThreeWay: %v", err)
+	}
+	if len(result.Conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", result.Conflicts)
+	}
+	if result.Conflicts[0].Kind != ConflictModifyModify || result.Conflicts[1].Kind != ConflictDeleteModify {
+		t.Errorf("unexpected conflict kinds: %s, %s", result.Conflicts[0].Kind, result.Conflicts[1].Kind)
+	}
+	if string(content) != ours {
+		t.Errorf("conflicts should keep ours without markers:\n%s", content)
+	}
+
+	_, content, err = MergeThreeWay(strings.NewReader(merge3Base), strings.NewReader(ours), strings.NewReader(theirs), Merge3Options{Markers: true})
+	if err != nil {
+		t.Fatalf("MergeThreeWay: %v", err)
+	}
+	want := strings.Replace(ours,
+		"    <!-- Camera -->\n    <item component=\"ComponentInfo{com.example.cam/com.example.cam.Main}\" drawable=\"camera_ours\" />\n",
+		"<<<<<<< ours\n"+
+			"    <!-- Camera -->\n    <item component=\"ComponentInfo{com.example.cam/com.example.cam.Main}\" drawable=\"camera_ours\" />\n"+
+			"=======\n"+
+			"    <!-- Camera -->\n    <item component=\"ComponentInfo{com.example.cam/com.example.cam.Main}\" drawable=\"camera_theirs\" />\n"+
+			">>>>>>> theirs\n", 1)
+	want = strings.Replace(want, "</resources>",
+		"<<<<<<< ours\n"+
+			"=======\n"+
+			"    <!-- Maps -->\n    <item component=\"ComponentInfo{com.example.maps/com.example.maps.Main}\" drawable=\"maps_v2\" />\n"+
+			">>>>>>> theirs\n</resources>", 1)
+	if string(content) != want {
+		t.Fatalf("unexpected output with markers:\n%s", content)
+	}
+}
+
+func TestMergeThreeWayRenameAgainstDelete(t *testing.T) {
+	const maps = "    <!-- Maps -->\n    <item component=\"ComponentInfo{com.example.maps/com.example.maps.Main}\" drawable=\"maps\" />\n"
+	renamed := strings.Replace(merge3Base, "<!-- Maps -->", "<!-- Maps renamed -->", 1)
+	deleted := strings.Replace(merge3Base, maps, "", 1)
+
+	result, _, err := MergeThreeWay(strings.NewReader(merge3Base), strings.NewReader(renamed), strings.NewReader(deleted), Merge3Options{})
+	if err != nil {
+		t.Fatalf("MergeThreeWay: %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Kind != ConflictModifyDelete || len(result.Removed) != 0 {
+		t.Fatalf("expected a modify/delete conflict, got %+v", result)
+	}
+
+	result, _, err = MergeThreeWay(strings.NewReader(merge3Base), strings.NewReader(deleted), strings.NewReader(renamed), Merge3Options{})
+	if err != nil {
+		t.Fatalf("MergeThreeWay: %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Kind != ConflictDeleteModify {
+		t.Fatalf("expected a delete/modify conflict, got %+v", result)
+	}
+}
+
+func TestMergeThreeWayKeepsOurRename(t *testing.T) {
+	ours := strings.Replace(merge3Base, "<!-- Camera -->", "<!-- Camera Pro -->", 1)
+	theirs := strings.Replace(merge3Base, `drawable="camera"`, `drawable="camera_v2"`, 1)
+
+	result, content, err := MergeThreeWay(strings.NewReader(merge3Base), strings.NewReader(ours), strings.NewReader(theirs), Merge3Options{})
+	if err != nil {
+		t.Fatalf("MergeThreeWay: %v", err)
+	}
+	if len(result.Conflicts) != 0 || len(result.Changed) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	want := strings.Replace(ours, `drawable="camera"`, `drawable="camera_v2"`, 1)
+	if string(content) != want {
+		t.Fatalf("unexpected output:\n%s", content)
+	}
+}
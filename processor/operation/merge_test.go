@@ -1,8 +1,10 @@
 package operation
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -118,3 +120,53 @@ func TestMergeAppFiltersInMemory(t *testing.T) {
 		t.Error("Content does not start with XML declaration")
 	}
 }
+
+func TestMergeAppFilterReaders(t *testing.T) {
+	const first = `<resources>
+  <!-- Calculator -->
+  <item component="ComponentInfo{com.example.calc/com.example.calc.Main}" drawable="calc"/>
+</resources>
+`
+	const second = `<resources>
+    <!-- Camera -->
+    <item component="ComponentInfo{com.example.cam/com.example.cam.Main}" drawable="camera" />
+    <!-- Calculator -->
+    <item component="ComponentInfo{com.example.calc/com.example.calc.Main}" drawable="calc" />
+    <item component="ComponentInfo{com.example.clock/com.example.clock.Main}" drawable="clock" />
+</resources>
+`
+
+	var out bytes.Buffer
+	result, err := MergeAppFilterReaders(strings.NewReader(first), strings.NewReader(second), &out, MergeRequest{
+		Mode:           MergeAll,
+		MergeIntoFirst: true,
+	})
+	if err != nil {
+		t.Fatalf("MergeAppFilterReaders() error = %v", err)
+	}
+	if result.ItemsMerged != 2 || len(result.FailedItems) != 1 || result.TotalItems != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+  <!-- Calculator -->
+  <item component="ComponentInfo{com.example.calc/com.example.calc.Main}" drawable="calc"/>
+  <!-- Camera -->
+  <item component="ComponentInfo{com.example.cam/com.example.cam.Main}" drawable="camera"/>
+  <item component="ComponentInfo{com.example.clock/com.example.clock.Main}" drawable="clock"/>
+</resources>
+`
+	if out.String() != want {
+		t.Fatalf("unexpected merged content:\n%s", out.String())
+	}
+
+	// A failed merge must not write anything.
+	out.Reset()
+	if _, err := MergeAppFilterReaders(strings.NewReader(first), strings.NewReader("<resources>"), &out, MergeRequest{}); err == nil {
+		t.Fatal("expected error for malformed input")
+	}
+	if out.Len() != 0 {
+		t.Errorf("output written on failure: %q", out.String())
+	}
+}
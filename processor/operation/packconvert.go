@@ -0,0 +1,249 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"circle-center/globals"
+)
+
+// DefaultAllArray is the name of the array that lists every drawable of a
+// pack, as most dashboards expect it.
+const DefaultAllArray = "all"
+
+// uncategorized names the array built from a drawable.xml category without a
+// title.
+const uncategorized = "uncategorized"
+
+// ConvertOptions controls the conversion between icon_pack.xml and
+// drawable.xml.
+type ConvertOptions struct {
+	// AllArray names the array listing every drawable once. DrawableToIconPack
+	// writes it before the category arrays; IconPackToDrawable does not turn
+	// it into a category, but keeps drawables listed only there in a final
+	// category titled after it. Empty disables both.
+	AllArray string
+	// Version is written as <version> of a drawable.xml; "1" by default.
+	Version string
+	// Indent is the indentation of one level; four spaces by default.
+	Indent string
+}
+
+// PackDrift lists the drawables that appear in only one of icon_pack.xml and
+// drawable.xml, each in file order.
+type PackDrift struct {
+	OnlyInIconPack []string `json:"only_in_icon_pack"`
+	OnlyInDrawable []string `json:"only_in_drawable"`
+}
+
+// DrawableToIconPack builds icon_pack.xml arrays from drawable.xml categories,
+// one array per category in category order. Array names are the titles made
+// resource-safe ("Google Apps" becomes "google_apps"); categories that end up
+// with the same name are merged.
+func DrawableToIconPack(res globals.DrawableResources, opts ConvertOptions) globals.IconPackResources {
+	var out globals.IconPackResources
+	byName := make(map[string]int)
+	if opts.AllArray != "" {
+		out.Arrays = append(out.Arrays, globals.StringArray{Name: opts.AllArray, Items: allDrawables(res)})
+		byName[opts.AllArray] = 0
+	}
+
+	for _, cat := range res.Categories {
+		name := SanitizeResourceName(cat.Title)
+		if name == "" {
+			name = uncategorized
+		}
+		if name == opts.AllArray {
+			// Already part of the all array.
+			continue
+		}
+		i, ok := byName[name]
+		if !ok {
+			out.Arrays = append(out.Arrays, globals.StringArray{Name: name})
+			i = len(out.Arrays) - 1
+			byName[name] = i
+		}
+		out.Arrays[i].Items = appendUnique(out.Arrays[i].Items, cat.Drawables...)
+	}
+	return out
+}
+
+// IconPackToDrawable builds drawable.xml categories from icon_pack.xml
+// arrays, one category per array in file order, titled after the array name
+// ("google_apps" becomes "Google Apps"). See ConvertOptions.AllArray for how
+// the all array is handled.
+func IconPackToDrawable(res globals.IconPackResources, opts ConvertOptions) globals.DrawableResources {
+	out := globals.DrawableResources{Version: opts.Version}
+	if out.Version == "" {
+		out.Version = "1"
+	}
+
+	var all *globals.StringArray
+	categorized := make(map[string]bool)
+	for i, arr := range res.Arrays {
+		if opts.AllArray != "" && arr.Name == opts.AllArray {
+			all = &res.Arrays[i]
+			continue
+		}
+		out.Categories = append(out.Categories, globals.DrawableCategory{
+			Title:     titleFromName(arr.Name),
+			Drawables: appendUnique(nil, arr.Items...),
+		})
+		for _, d := range arr.Items {
+			categorized[d] = true
+		}
+	}
+
+	if all != nil {
+		var rest []string
+		for _, d := range all.Items {
+			if !categorized[d] {
+				rest = append(rest, d)
+			}
+		}
+		rest = appendUnique(nil, rest...)
+		if len(rest) > 0 {
+			out.Categories = append(out.Categories, globals.DrawableCategory{
+				Title:     titleFromName(all.Name),
+				Drawables: rest,
+			})
+		}
+	}
+	return out
+}
+
+// ComparePackFiles reports the drawables listed in only one of the two files.
+func ComparePackFiles(iconPack globals.IconPackResources, drawable globals.DrawableResources) PackDrift {
+	var packNames []string
+	for _, arr := range iconPack.Arrays {
+		packNames = appendUnique(packNames, arr.Items...)
+	}
+	drawableNames := allDrawables(drawable)
+
+	drift := PackDrift{OnlyInIconPack: make([]string, 0), OnlyInDrawable: make([]string, 0)}
+	inDrawable := make(map[string]bool, len(drawableNames))
+	for _, d := range drawableNames {
+		inDrawable[d] = true
+	}
+	inPack := make(map[string]bool, len(packNames))
+	for _, d := range packNames {
+		inPack[d] = true
+		if !inDrawable[d] {
+			drift.OnlyInIconPack = append(drift.OnlyInIconPack, d)
+		}
+	}
+	for _, d := range drawableNames {
+		if !inPack[d] {
+			drift.OnlyInDrawable = append(drift.OnlyInDrawable, d)
+		}
+	}
+	return drift
+}
+
+// WriteIconPack writes res as an icon_pack.xml file.
+func WriteIconPack(w io.Writer, res globals.IconPackResources, opts ConvertOptions) error {
+	indent := opts.Indent
+	if indent == "" {
+		indent = "    "
+	}
+	var buf bytes.Buffer
+	buf.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources>\n")
+	for i, arr := range res.Arrays {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(indent)
+		writeElementOpen(&buf, "string-array", [][2]string{{"name", arr.Name}})
+		for _, item := range arr.Items {
+			buf.WriteString(indent + indent + "<item>")
+			_ = xml.EscapeText(&buf, []byte(item))
+			buf.WriteString("</item>\n")
+		}
+		buf.WriteString(indent + "</string-array>\n")
+	}
+	buf.WriteString("</resources>\n")
+	if _, err := buf.WriteTo(w); err != nil {
+		return fmt.Errorf("write icon_pack: %w", err)
+	}
+	return nil
+}
+
+// WriteDrawable writes res as a drawable.xml file: the version, then every
+// category followed by its items, separated by blank lines.
+func WriteDrawable(w io.Writer, res globals.DrawableResources, opts ConvertOptions) error {
+	indent := opts.Indent
+	if indent == "" {
+		indent = "    "
+	}
+	version := res.Version
+	if version == "" {
+		version = "1"
+	}
+	var buf bytes.Buffer
+	buf.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources>\n")
+	buf.WriteString(indent + "<version>")
+	_ = xml.EscapeText(&buf, []byte(version))
+	buf.WriteString("</version>\n")
+	for _, cat := range res.Categories {
+		buf.WriteString("\n")
+		if cat.Title != "" {
+			writeElement(&buf, indent, "category", [][2]string{{"title", cat.Title}})
+		}
+		for _, d := range cat.Drawables {
+			writeElement(&buf, indent, "item", [][2]string{{"drawable", d}})
+		}
+	}
+	buf.WriteString("</resources>\n")
+	if _, err := buf.WriteTo(w); err != nil {
+		return fmt.Errorf("write drawable: %w", err)
+	}
+	return nil
+}
+
+// writeElementOpen writes a start tag with attributes followed by a newline.
+func writeElementOpen(buf *bytes.Buffer, name string, attrs [][2]string) {
+	buf.WriteString("<" + name)
+	for _, a := range attrs {
+		buf.WriteString(" " + a[0] + `="` + escapeAttr(a[1], `"`) + `"`)
+	}
+	buf.WriteString(">\n")
+}
+
+// allDrawables returns every drawable of res once, in category order.
+func allDrawables(res globals.DrawableResources) []string {
+	var names []string
+	for _, cat := range res.Categories {
+		names = appendUnique(names, cat.Drawables...)
+	}
+	return names
+}
+
+// appendUnique appends the non-empty values not yet in list.
+func appendUnique(list []string, values ...string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, v := range list {
+		seen[v] = true
+	}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		list = append(list, v)
+	}
+	return list
+}
+
+// titleFromName turns an array name such as "google_apps" into a category
+// title ("Google Apps").
+func titleFromName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == ' ' })
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,106 @@
+package operation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"circle-center/globals"
+	"circle-center/reader"
+)
+
+func TestDrawableToIconPack(t *testing.T) {
+	res, err := reader.ParseDrawableFromReader(strings.NewReader(`<resources>
+    <version>1</version>
+    <category title="Google Apps"/>
+    <item drawable="gmail"/>
+    <item drawable="maps"/>
+    <category title="Tools"/>
+    <item drawable="calc"/>
+    <item drawable="maps"/>
+</resources>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := WriteIconPack(&out, DrawableToIconPack(res, ConvertOptions{AllArray: DefaultAllArray}), ConvertOptions{}); err != nil {
+		t.Fatalf("WriteIconPack: %v", err)
+	}
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string-array name="all">
+        <item>gmail</item>
+        <item>maps</item>
+        <item>calc</item>
+    </string-array>
+
+    <string-array name="google_apps">
+        <item>gmail</item>
+        <item>maps</item>
+    </string-array>
+
+    <string-array name="tools">
+        <item>calc</item>
+        <item>maps</item>
+    </string-array>
+</resources>
+`
+	if out.String() != want {
+		t.Fatalf("unexpected icon_pack:\n%s", out.String())
+	}
+}
+
+func TestIconPackToDrawable(t *testing.T) {
+	res := globals.IconPackResources{Arrays: []globals.StringArray{
+		{Name: "all", Items: []string{"gmail", "calc", "clock"}},
+		{Name: "google_apps", Items: []string{"gmail"}},
+		{Name: "tools", Items: []string{"calc"}},
+	}}
+
+	var out strings.Builder
+	if err := WriteDrawable(&out, IconPackToDrawable(res, ConvertOptions{AllArray: DefaultAllArray}), ConvertOptions{}); err != nil {
+		t.Fatalf("WriteDrawable: %v", err)
+	}
+	// clock is only listed in the all array, so it keeps a category of its own.
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <version>1</version>
+
+    <category title="Google Apps" />
+    <item drawable="gmail" />
+
+    <category title="Tools" />
+    <item drawable="calc" />
+
+    <category title="All" />
+    <item drawable="clock" />
+</resources>
+`
+	if out.String() != want {
+		t.Fatalf("unexpected drawable.xml:\n%s", out.String())
+	}
+
+	back, err := reader.ParseDrawableFromReader(strings.NewReader(out.String()))
+	if err != nil {
+		t.Fatalf("output does not parse: %v", err)
+	}
+	if drift := ComparePackFiles(res, back); len(drift.OnlyInIconPack)+len(drift.OnlyInDrawable) != 0 {
+		t.Errorf("round trip lost drawables: %+v", drift)
+	}
+}
+
+func TestComparePackFiles(t *testing.T) {
+	pack := globals.IconPackResources{Arrays: []globals.StringArray{
+		{Name: "all", Items: []string{"gmail", "calc", "old"}},
+	}}
+	drawable := globals.DrawableResources{Categories: []globals.DrawableCategory{
+		{Title: "Tools", Drawables: []string{"calc", "clock"}},
+		{Title: "Google", Drawables: []string{"gmail"}},
+	}}
+
+	drift := ComparePackFiles(pack, drawable)
+	if !reflect.DeepEqual(drift.OnlyInIconPack, []string{"old"}) || !reflect.DeepEqual(drift.OnlyInDrawable, []string{"clock"}) {
+		t.Fatalf("unexpected drift: %+v", drift)
+	}
+}
@@ -0,0 +1,257 @@
+package operation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"circle-center/globals"
+)
+
+// PatchVersion is the version written into new patches. ApplyPatch rejects
+// patches with a different version.
+const PatchVersion = 1
+
+// ErrPatchConflict is returned by ApplyPatch when at least one operation does
+// not match the target appfilter. Nothing is written in that case.
+var ErrPatchConflict = errors.New("patch does not apply cleanly")
+
+// PatchOp is the kind of a patch operation.
+type PatchOp string
+
+const (
+	// PatchAdd adds a new item. The component must not exist yet.
+	PatchAdd PatchOp = "add"
+	// PatchRemove removes an existing item.
+	PatchRemove PatchOp = "remove"
+	// PatchChange rewrites the drawable and app name of an existing item.
+	PatchChange PatchOp = "change"
+)
+
+// PatchValue is the mapping of a component: its drawable and app name.
+type PatchValue struct {
+	Drawable string `json:"drawable"`
+	AppName  string `json:"app_name,omitempty"`
+}
+
+// PatchOperation is one change keyed by component. To holds the new value for
+// add and change; From holds the value the target is expected to have for
+// remove and change and is what conflicts are detected against.
+type PatchOperation struct {
+	Op        PatchOp     `json:"op"`
+	Component string      `json:"component"`
+	From      *PatchValue `json:"from,omitempty"`
+	To        *PatchValue `json:"to,omitempty"`
+}
+
+// AppFilterPatch is a small, reviewable description of the changes between
+// two appfilter.xml files. It is meant to be stored and exchanged as JSON.
+type AppFilterPatch struct {
+	Version    int              `json:"version"`
+	Operations []PatchOperation `json:"operations"`
+}
+
+// PatchConflict describes an operation that does not match the target.
+// Current is the target's item for the component, nil when it has none.
+type PatchConflict struct {
+	Index     int
+	Operation PatchOperation
+	Reason    string
+	Current   *globals.Item
+}
+
+// PatchResult summarises ApplyPatch.
+type PatchResult struct {
+	Added     int
+	Removed   int
+	Changed   int
+	Skipped   int
+	Conflicts []PatchConflict
+}
+
+// CreatePatch returns the patch that turns first into second, built from
+// DiffItems: items only in first become removals, items only in second
+// additions and changed items changes. Operations are ordered removals,
+// changes, additions, each in file order. A component listed more than once
+// gets a single operation per kind; ApplyPatch removes every copy.
+func CreatePatch(first, second []globals.Item, opts DiffOptions) *AppFilterPatch {
+	diff := DiffItems(first, second, opts)
+	patch := &AppFilterPatch{Version: PatchVersion, Operations: make([]PatchOperation, 0)}
+
+	seen := make(map[PatchOp]map[string]bool)
+	add := func(op PatchOperation) {
+		if seen[op.Op] == nil {
+			seen[op.Op] = make(map[string]bool)
+		}
+		if seen[op.Op][op.Component] {
+			return
+		}
+		seen[op.Op][op.Component] = true
+		patch.Operations = append(patch.Operations, op)
+	}
+
+	for _, item := range diff.OnlyInFirst {
+		add(PatchOperation{
+			Op:        PatchRemove,
+			Component: item.Component,
+			From:      patchValue(item),
+		})
+	}
+	for _, change := range diff.Changed {
+		add(PatchOperation{
+			Op:        PatchChange,
+			Component: change.Component,
+			From:      patchValue(change.Before),
+			To:        patchValue(change.After),
+		})
+	}
+	for _, item := range diff.OnlyInSecond {
+		add(PatchOperation{
+			Op:        PatchAdd,
+			Component: item.Component,
+			To:        patchValue(item),
+		})
+	}
+	return patch
+}
+
+// patchValue returns the mapping of item.
+func patchValue(item globals.Item) *PatchValue {
+	return &PatchValue{Drawable: item.Drawable, AppName: item.AppName}
+}
+
+// Validate checks that the patch is well-formed.
+func (p *AppFilterPatch) Validate() error {
+	if p.Version != PatchVersion {
+		return fmt.Errorf("unsupported patch version %d", p.Version)
+	}
+	for i, op := range p.Operations {
+		if op.Component == "" {
+			return fmt.Errorf("operation %d: missing component", i)
+		}
+		switch op.Op {
+		case PatchAdd:
+			if op.To == nil {
+				return fmt.Errorf("operation %d: add requires \"to\"", i)
+			}
+		case PatchChange:
+			if op.To == nil {
+				return fmt.Errorf("operation %d: change requires \"to\"", i)
+			}
+		case PatchRemove:
+		default:
+			return fmt.Errorf("operation %d: unknown op %q", i, op.Op)
+		}
+	}
+	return nil
+}
+
+// ApplyPatch applies patch to the appfilter.xml read from r and writes the
+// result to w. The target is edited as an AppFilterDocument, so untouched
+// parts keep their formatting. A remove deletes every copy of a duplicated
+// component, a change rewrites the first one. Operations whose effect is
+// already present (adding an identical item, changing an item to its current
+// value) are skipped. If any operation conflicts with the target (adding an existing
+// component with a different mapping, removing or changing a missing
+// component, or a "from" value that does not match) nothing is written and
+// ErrPatchConflict is returned together with the conflict list.
+func ApplyPatch(r io.Reader, w io.Writer, patch *AppFilterPatch) (*PatchResult, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+
+	doc, err := OpenAppFilter(r)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]globals.Item)
+	for _, item := range doc.Items() {
+		if _, ok := current[item.Component]; !ok {
+			current[item.Component] = item
+		}
+	}
+
+	result := &PatchResult{Conflicts: make([]PatchConflict, 0)}
+	conflict := func(i int, op PatchOperation, reason string) {
+		c := PatchConflict{Index: i, Operation: op, Reason: reason}
+		if item, ok := current[op.Component]; ok {
+			c.Current = &item
+		}
+		result.Conflicts = append(result.Conflicts, c)
+	}
+
+	for i, op := range patch.Operations {
+		item, exists := current[op.Component]
+		switch op.Op {
+		case PatchAdd:
+			if exists {
+				if matchesValue(item, op.To) {
+					result.Skipped++
+				} else {
+					conflict(i, op, "component already exists with a different mapping")
+				}
+				continue
+			}
+			added := globals.Item{Component: op.Component, Drawable: op.To.Drawable, AppName: op.To.AppName}
+			if err := doc.Add(added); err != nil {
+				return nil, err
+			}
+			current[op.Component] = added
+			result.Added++
+
+		case PatchRemove:
+			if !exists {
+				conflict(i, op, "component not found")
+				continue
+			}
+			if op.From != nil && !matchesValue(item, op.From) {
+				conflict(i, op, "current mapping does not match \"from\"")
+				continue
+			}
+			// Duplicated components are removed as a whole.
+			for doc.Has(op.Component) {
+				if err := doc.Remove(op.Component); err != nil {
+					return nil, err
+				}
+			}
+			delete(current, op.Component)
+			result.Removed++
+
+		case PatchChange:
+			if !exists {
+				conflict(i, op, "component not found")
+				continue
+			}
+			if matchesValue(item, op.To) {
+				result.Skipped++
+				continue
+			}
+			if op.From != nil && !matchesValue(item, op.From) {
+				conflict(i, op, "current mapping does not match \"from\"")
+				continue
+			}
+			changed := item
+			changed.Drawable, changed.AppName = op.To.Drawable, op.To.AppName
+			if err := doc.Replace(op.Component, changed); err != nil {
+				return nil, err
+			}
+			current[op.Component] = changed
+			result.Changed++
+		}
+	}
+
+	if len(result.Conflicts) > 0 {
+		return result, ErrPatchConflict
+	}
+
+	if _, err := doc.WriteTo(w); err != nil {
+		return nil, fmt.Errorf("write patched content: %w", err)
+	}
+	return result, nil
+}
+
+// matchesValue reports whether item has the mapping described by v.
+func matchesValue(item globals.Item, v *PatchValue) bool {
+	return item.Drawable == v.Drawable && item.AppName == v.AppName
+}
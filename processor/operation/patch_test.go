@@ -0,0 +1,120 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"circle-center/reader"
+)
+
+func TestCreateAndApplyPatch(t *testing.T) {
+	ours := strings.Replace(merge3Base, `drawable="camera"`, `drawable="camera_alt"`, 1)
+	ours = strings.Replace(ours,
+		"    <!-- Maps -->\n    <item component=\"ComponentInfo{com.example.maps/com.example.maps.Main}\" drawable=\"maps\" />\n", "", 1)
+	ours = strings.Replace(ours, "</resources>",
+		"    <!-- Notes -->\n    <item component=\"ComponentInfo{com.example.notes/com.example.notes.Main}\" drawable=\"notes\" />\n</resources>", 1)
+
+	baseItems, err := reader.ParseFromReader(strings.NewReader(merge3Base))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ourItems, err := reader.ParseFromReader(strings.NewReader(ours))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patch := CreatePatch(baseItems, ourItems, DiffOptions{})
+	if len(patch.Operations) != 3 {
+		t.Fatalf("expected 3 operations, got %+v", patch.Operations)
+	}
+
+	// The patch survives a JSON round trip.
+	data, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded AppFilterPatch
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	result, err := ApplyPatch(strings.NewReader(merge3Base), &out, &decoded)
+	if err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if result.Added != 1 || result.Removed != 1 || result.Changed != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if out.String() != ours {
+		t.Fatalf("unexpected patched content:\n%s", out.String())
+	}
+
+	// Applying again: the add and change are already present, the remove
+	// has nothing to remove.
+	out.Reset()
+	result, err = ApplyPatch(strings.NewReader(ours), &out, &decoded)
+	if !errors.Is(err, ErrPatchConflict) {
+		t.Fatalf("expected ErrPatchConflict, got %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Operation.Op != PatchRemove || result.Skipped != 2 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if out.Len() != 0 {
+		t.Error("nothing should be written when the patch conflicts")
+	}
+}
+
+func TestApplyPatchDetectsMismatch(t *testing.T) {
+	patch := &AppFilterPatch{Version: PatchVersion, Operations: []PatchOperation{{
+		Op:        PatchChange,
+		Component: "ComponentInfo{com.example.cam/com.example.cam.Main}",
+		From:      &PatchValue{Drawable: "camera_old", AppName: "Camera"},
+		To:        &PatchValue{Drawable: "camera_new", AppName: "Camera"},
+	}}}
+
+	result, err := ApplyPatch(strings.NewReader(merge3Base), &bytes.Buffer{}, patch)
+	if !errors.Is(err, ErrPatchConflict) {
+		t.Fatalf("expected ErrPatchConflict, got %v", err)
+	}
+	c := result.Conflicts[0]
+	if c.Current == nil || c.Current.Drawable != "camera" {
+		t.Errorf("expected current item in conflict, got %+v", c)
+	}
+
+	patch.Version = 2
+	if _, err := ApplyPatch(strings.NewReader(merge3Base), &bytes.Buffer{}, patch); err == nil || errors.Is(err, ErrPatchConflict) {
+		t.Errorf("expected version error, got %v", err)
+	}
+}
+
+func TestPatchRoundTripWithDuplicateComponent(t *testing.T) {
+	const maps = "    <!-- Maps -->\n    <item component=\"ComponentInfo{com.example.maps/com.example.maps.Main}\" drawable=\"maps\" />\n"
+	first := strings.Replace(merge3Base, "</resources>", maps+"</resources>", 1)
+	second := strings.Replace(merge3Base, maps, "", 1)
+
+	firstItems, err := reader.ParseFromReader(strings.NewReader(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondItems, err := reader.ParseFromReader(strings.NewReader(second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patch := CreatePatch(firstItems, secondItems, DiffOptions{})
+	if len(patch.Operations) != 1 || patch.Operations[0].Op != PatchRemove {
+		t.Fatalf("expected a single remove, got %+v", patch.Operations)
+	}
+
+	var out bytes.Buffer
+	if _, err := ApplyPatch(strings.NewReader(first), &out, patch); err != nil {
+		t.Fatalf("ApplyPatch: %v", err)
+	}
+	if out.String() != second {
+		t.Fatalf("unexpected patched content:\n%s", out.String())
+	}
+}
@@ -0,0 +1,569 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"circle-center/globals"
+	"circle-center/reader"
+)
+
+// ErrRenameCollision is returned by RenamePlan.Apply when the plan contains
+// collisions. Nothing is changed in that case.
+var ErrRenameCollision = errors.New("rename plan has collisions")
+
+// templateField matches a {field} placeholder of a template rule.
+var templateField = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// imgAttr matches the img1, img2, ... attributes of iconback, iconmask and
+// iconupon, which name drawables just like the drawable attribute.
+var imgAttr = regexp.MustCompile(`^img\d*$`)
+
+// RenameRule is one step of a bulk drawable rename. Rules are applied in
+// order, each to the result of the previous one.
+//
+// A regex rule has a Pattern and a Replace; every match of Pattern in the name
+// is replaced, and Replace may refer to groups as $1 or ${name}. A template
+// rule has a Template that builds the whole new name from the fields {name}
+// (the current name), {package} and {app} (package and app name of the first
+// appfilter item using the drawable, made resource-safe). Its optional Pattern
+// limits the rule to matching names. A template rule is skipped for drawables
+// that no item uses when it needs {package} or {app}.
+type RenameRule struct {
+	Pattern  string `json:"pattern,omitempty"`
+	Replace  string `json:"replace,omitempty"`
+	Template string `json:"template,omitempty"`
+}
+
+// PackFiles locates the parts of an icon pack taking part in a rename. Empty
+// paths are skipped. Items lists mappings that have no file of their own, such
+// as icons stored in the database; their drawables are renamed and checked for
+// collisions like the rest, and they feed the {package} and {app} fields.
+type PackFiles struct {
+	IconDir   string
+	AppFilter string
+	IconPack  string
+	Drawable  string
+	Items     []globals.Item
+}
+
+// DrawableRename is one drawable that changes name, with the number of image
+// files moved and XML references rewritten for it.
+type DrawableRename struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Files      int    `json:"files"`
+	References int    `json:"references"`
+}
+
+// RenameCollision explains why a target name cannot be used. From lists every
+// drawable that would be renamed to To.
+type RenameCollision struct {
+	To     string   `json:"to"`
+	From   []string `json:"from"`
+	Reason string   `json:"reason"`
+}
+
+// FileMove is an image file that is renamed.
+type FileMove struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RenamePlan is the preview of a bulk rename. It is complete before anything
+// is touched: Apply moves exactly the files listed in Moves and writes the
+// XML content prepared for Documents.
+type RenamePlan struct {
+	Renames    []DrawableRename  `json:"renames"`
+	Collisions []RenameCollision `json:"collisions"`
+	Moves      []FileMove        `json:"moves"`
+	Documents  []string          `json:"documents"`
+
+	staged map[string][]byte
+}
+
+// RenameDrawables plans a bulk rename of the drawables in files and, unless
+// dryRun is set, applies it. With collisions the plan is returned together
+// with ErrRenameCollision and nothing is changed.
+func RenameDrawables(files PackFiles, rules []RenameRule, dryRun bool) (*RenamePlan, error) {
+	plan, err := PlanDrawableRename(files, rules)
+	if err != nil || dryRun {
+		return plan, err
+	}
+	txn, err := plan.Apply()
+	if err != nil {
+		return plan, err
+	}
+	return plan, txn.Commit()
+}
+
+// PlanDrawableRename runs rules over every drawable referenced by files and
+// returns the resulting plan without changing anything. A target name is a
+// collision when it is not a valid resource name, when several drawables map
+// to it, when another drawable that keeps its name already uses it, or when
+// an unrelated file already exists at the new path of an image.
+func PlanDrawableRename(files PackFiles, rules []RenameRule) (*RenamePlan, error) {
+	compiled, err := compileRenameRules(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	known := make(map[string]bool)
+	note := func(name string) {
+		name = strings.TrimSpace(name)
+		if name != "" && !known[name] {
+			known[name] = true
+			names = append(names, name)
+		}
+	}
+	owners := make(map[string]*globals.Item)
+	own := func(items []globals.Item) {
+		for i := range items {
+			d := strings.TrimSpace(items[i].Drawable)
+			if _, ok := owners[d]; !ok {
+				owners[d] = &items[i]
+			}
+			note(d)
+		}
+	}
+
+	var icons []globals.LocalIcon
+	if files.IconDir != "" {
+		if _, err := os.Stat(files.IconDir); err == nil {
+			if icons, err = reader.ParseIconDirectory(files.IconDir); err != nil {
+				return nil, err
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("read icon directory: %w", err)
+		}
+	}
+
+	var docs []*renameDoc
+	for _, src := range []struct{ path, kind string }{
+		{files.AppFilter, "appfilter"},
+		{files.IconPack, "icon_pack"},
+		{files.Drawable, "drawable"},
+	} {
+		if src.path == "" {
+			continue
+		}
+		doc, err := openRenameDoc(src.path, src.kind)
+		if err != nil {
+			return nil, err
+		}
+		if src.kind == "appfilter" {
+			items, err := reader.ParseFromReader(bytes.NewReader(doc.src))
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", src.path, err)
+			}
+			own(items)
+		}
+		for _, ref := range doc.refs {
+			note(ref.value())
+		}
+		docs = append(docs, doc)
+	}
+	own(files.Items)
+	for _, icon := range icons {
+		note(icon.PackageName)
+	}
+
+	mapping := make(map[string]string)
+	for _, name := range names {
+		to := name
+		for _, rule := range compiled {
+			to = rule.apply(to, owners[name])
+		}
+		if to != name {
+			mapping[name] = to
+		}
+	}
+
+	plan := &RenamePlan{
+		Renames:    make([]DrawableRename, 0),
+		Collisions: make([]RenameCollision, 0),
+		Moves:      make([]FileMove, 0),
+		Documents:  make([]string, 0),
+		staged:     make(map[string][]byte),
+	}
+
+	sources := make(map[string][]string)
+	for _, name := range names {
+		if to, ok := mapping[name]; ok {
+			sources[to] = append(sources[to], name)
+		}
+	}
+	reported := make(map[string]bool)
+	for _, name := range names {
+		to, ok := mapping[name]
+		if !ok || reported[to] {
+			continue
+		}
+		reason := ""
+		switch _, renamed := mapping[to]; {
+		case !IsResourceName(to):
+			reason = "not a valid resource name"
+		case len(sources[to]) > 1:
+			reason = "several drawables are renamed to this name"
+		case known[to] && !renamed:
+			reason = "name is already used by another drawable"
+		}
+		if reason != "" {
+			reported[to] = true
+			plan.Collisions = append(plan.Collisions, RenameCollision{To: to, From: sources[to], Reason: reason})
+		}
+	}
+
+	moved := make(map[string]int)
+	moving := make(map[string]bool)
+	for _, icon := range icons {
+		if _, ok := mapping[icon.PackageName]; ok {
+			for _, v := range icon.Variants {
+				moving[v.FilePath] = true
+			}
+		}
+	}
+	for _, icon := range icons {
+		to, ok := mapping[icon.PackageName]
+		if !ok {
+			continue
+		}
+		for _, v := range icon.Variants {
+			target := filepath.Join(filepath.Dir(v.FilePath), to+filepath.Ext(v.FilePath))
+			if _, err := os.Lstat(target); err == nil && !moving[target] && !reported[to] {
+				reported[to] = true
+				plan.Collisions = append(plan.Collisions, RenameCollision{
+					To:     to,
+					From:   sources[to],
+					Reason: "file already exists: " + target,
+				})
+			}
+			plan.Moves = append(plan.Moves, FileMove{From: v.FilePath, To: target})
+			moved[icon.PackageName]++
+		}
+	}
+
+	refs := make(map[string]int)
+	for _, doc := range docs {
+		changed := false
+		for _, ref := range doc.refs {
+			if to, ok := mapping[ref.value()]; ok {
+				refs[ref.value()]++
+				ref.set(to, doc.style)
+				changed = true
+			}
+		}
+		if changed {
+			var buf bytes.Buffer
+			if _, err := doc.doc.WriteTo(&buf); err != nil {
+				return nil, fmt.Errorf("render %s: %w", doc.path, err)
+			}
+			plan.Documents = append(plan.Documents, doc.path)
+			plan.staged[doc.path] = buf.Bytes()
+		}
+	}
+
+	for _, name := range names {
+		if to, ok := mapping[name]; ok {
+			plan.Renames = append(plan.Renames, DrawableRename{
+				From:       name,
+				To:         to,
+				Files:      moved[name],
+				References: refs[name],
+			})
+		}
+	}
+	return plan, nil
+}
+
+// Mapping returns the old to new name of every renamed drawable.
+func (p *RenamePlan) Mapping() map[string]string {
+	m := make(map[string]string, len(p.Renames))
+	for _, r := range p.Renames {
+		m[r.From] = r.To
+	}
+	return m
+}
+
+// Apply carries out the plan. New XML content is written next to each
+// document first, then images are moved through temporary names so swaps and
+// chains never overwrite each other, and finally the documents are swapped in.
+// If any step fails everything done so far is undone. The returned RenameTxn
+// keeps the previous documents until Commit, so callers that update other
+// state (such as database rows) can still roll the whole rename back.
+func (p *RenamePlan) Apply() (*RenameTxn, error) {
+	if len(p.Collisions) > 0 {
+		return nil, ErrRenameCollision
+	}
+
+	txn := &RenameTxn{}
+	fail := func(err error) (*RenameTxn, error) {
+		if rerr := txn.Rollback(); rerr != nil {
+			err = errors.Join(err, fmt.Errorf("rollback: %w", rerr))
+		}
+		return nil, err
+	}
+
+	for _, path := range p.Documents {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fail(err)
+		}
+		tmp := path + ".rename.tmp"
+		txn.temps = append(txn.temps, tmp)
+		if err := os.WriteFile(tmp, p.staged[path], info.Mode().Perm()); err != nil {
+			return fail(fmt.Errorf("stage %s: %w", path, err))
+		}
+	}
+
+	parked := make([]string, len(p.Moves))
+	for i, m := range p.Moves {
+		parked[i] = filepath.Join(filepath.Dir(m.From), fmt.Sprintf(".rename-%d%s", i, filepath.Ext(m.From)))
+		if err := txn.rename(m.From, parked[i]); err != nil {
+			return fail(err)
+		}
+	}
+	for i, m := range p.Moves {
+		if err := txn.rename(parked[i], m.To); err != nil {
+			return fail(err)
+		}
+	}
+
+	for _, path := range p.Documents {
+		backup := path + ".rename.bak"
+		if err := txn.rename(path, backup); err != nil {
+			return fail(err)
+		}
+		txn.backups = append(txn.backups, backup)
+		if err := txn.rename(path+".rename.tmp", path); err != nil {
+			return fail(err)
+		}
+	}
+	return txn, nil
+}
+
+// RenameTxn is an applied rename that can still be undone.
+type RenameTxn struct {
+	done    [][2]string
+	temps   []string
+	backups []string
+}
+
+// rename moves from to to and records the step for Rollback.
+func (t *RenameTxn) rename(from, to string) error {
+	if err := os.Rename(from, to); err != nil {
+		return fmt.Errorf("rename %s: %w", from, err)
+	}
+	t.done = append(t.done, [2]string{from, to})
+	return nil
+}
+
+// Rollback restores every file to its state before Apply.
+func (t *RenameTxn) Rollback() error {
+	var errs []error
+	for i := len(t.done) - 1; i >= 0; i-- {
+		if err := os.Rename(t.done[i][1], t.done[i][0]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, tmp := range t.temps {
+		if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	t.done, t.temps, t.backups = nil, nil, nil
+	return errors.Join(errs...)
+}
+
+// Commit makes the rename final by dropping the backups of the documents.
+func (t *RenameTxn) Commit() error {
+	var errs []error
+	for _, backup := range t.backups {
+		if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	t.done, t.temps, t.backups = nil, nil, nil
+	return errors.Join(errs...)
+}
+
+// compiledRule is a RenameRule ready to run.
+type compiledRule struct {
+	re       *regexp.Regexp
+	replace  string
+	template string
+}
+
+// compileRenameRules validates rules and compiles their patterns.
+func compileRenameRules(rules []RenameRule) ([]compiledRule, error) {
+	if len(rules) == 0 {
+		return nil, errors.New("no rename rules given")
+	}
+	compiled := make([]compiledRule, 0, len(rules))
+	for i, r := range rules {
+		c := compiledRule{replace: r.Replace, template: r.Template}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+			c.re = re
+		}
+		if c.template == "" && c.re == nil {
+			return nil, fmt.Errorf("rule %d: pattern or template is required", i)
+		}
+		for _, m := range templateField.FindAllStringSubmatch(c.template, -1) {
+			switch m[1] {
+			case "name", "package", "app":
+			default:
+				return nil, fmt.Errorf("rule %d: unknown template field %s", i, m[0])
+			}
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// apply returns name after the rule. item is the first item using the
+// drawable, nil when there is none.
+func (r compiledRule) apply(name string, item *globals.Item) string {
+	if r.template == "" {
+		return r.re.ReplaceAllString(name, r.replace)
+	}
+	if r.re != nil && !r.re.MatchString(name) {
+		return name
+	}
+	ok := true
+	out := templateField.ReplaceAllStringFunc(r.template, func(field string) string {
+		var v string
+		switch field {
+		case "{name}":
+			return name
+		case "{package}":
+			if item != nil {
+				v = item.PackageName
+			}
+		case "{app}":
+			if item != nil {
+				v = item.AppName
+			}
+		}
+		v = SanitizeResourceName(v)
+		if v == "" {
+			ok = false
+		}
+		return v
+	})
+	if !ok {
+		return name
+	}
+	return out
+}
+
+// renameDoc is an XML file whose drawable references are rewritten.
+type renameDoc struct {
+	path  string
+	src   []byte
+	doc   *rawDoc
+	style docStyle
+	refs  []drawableRef
+}
+
+// openRenameDoc parses the file at path and collects its drawable references:
+// drawable and img* attributes for an appfilter, item drawables for
+// drawable.xml and string-array items for icon_pack.xml.
+func openRenameDoc(path, kind string) (*renameDoc, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	doc, err := parseRawDoc(bytes.NewReader(src))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	root := doc.root("resources")
+	if root == nil {
+		return nil, fmt.Errorf("read %s: missing <resources> root element", path)
+	}
+
+	d := &renameDoc{path: path, src: src, doc: doc, style: detectStyle(src, root, "item")}
+	var walk func(n *rawNode)
+	walk = func(n *rawNode) {
+		for _, c := range n.children {
+			if c.kind != nodeElement {
+				continue
+			}
+			switch kind {
+			case "appfilter":
+				for _, a := range c.attrs {
+					if a.Name.Local == "drawable" || imgAttr.MatchString(a.Name.Local) {
+						d.refs = append(d.refs, drawableRef{node: c, attr: a.Name.Local})
+					}
+				}
+			case "drawable":
+				if c.name == "item" && c.attr("drawable") != "" {
+					d.refs = append(d.refs, drawableRef{node: c, attr: "drawable"})
+				}
+			case "icon_pack":
+				if c.name == "item" && n.name == "string-array" && textOnly(c) {
+					d.refs = append(d.refs, drawableRef{node: c})
+				}
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	return d, nil
+}
+
+// textOnly reports whether every child of n is character data.
+func textOnly(n *rawNode) bool {
+	for _, c := range n.children {
+		if c.kind != nodeText {
+			return false
+		}
+	}
+	return true
+}
+
+// drawableRef is one place naming a drawable: an attribute of node, or its
+// text when attr is empty.
+type drawableRef struct {
+	node *rawNode
+	attr string
+}
+
+// value returns the drawable name at the reference.
+func (r drawableRef) value() string {
+	if r.attr == "" {
+		return strings.TrimSpace(r.node.text())
+	}
+	return strings.TrimSpace(r.node.attr(r.attr))
+}
+
+// set rewrites the reference to name, keeping whitespace around item text.
+func (r drawableRef) set(name string, style docStyle) {
+	if r.attr != "" {
+		r.node.setAttr(r.attr, name, style)
+		return
+	}
+	var raw strings.Builder
+	for _, c := range r.node.children {
+		raw.Write(c.raw)
+	}
+	s := raw.String()
+	lead := s[:len(s)-len(strings.TrimLeft(s, " \t\r\n"))]
+	trail := s[len(strings.TrimRight(s, " \t\r\n")):]
+	var esc bytes.Buffer
+	_ = xml.EscapeText(&esc, []byte(name))
+	r.node.children = []*rawNode{newTextNode(lead + esc.String() + trail)}
+	adopt(r.node, r.node.children)
+	r.node.touch()
+}
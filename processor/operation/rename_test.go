@@ -0,0 +1,209 @@
+package operation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const renameAppFilter = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <iconback img1="ic_back"/>
+    <!-- Calculator -->
+    <item component="ComponentInfo{com.example.calc/com.example.calc.Main}" drawable="ic_calc"/>
+    <!-- Camera -->
+    <item component="ComponentInfo{com.example.cam/com.example.cam.Main}" drawable="camera"/>
+</resources>
+`
+
+const renameIconPack = `<resources>
+    <string-array name="all">
+        <item>ic_calc</item>
+        <item> camera </item>
+    </string-array>
+</resources>
+`
+
+const renameDrawable = `<resources>
+    <version>1</version>
+    <category title="Tools"/>
+    <item drawable="ic_calc"/>
+    <item drawable="camera"/>
+</resources>
+`
+
+func writeRenamePack(t *testing.T, extra map[string]string) PackFiles {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"appfilter.xml":                         renameAppFilter,
+		"icon_pack.xml":                         renameIconPack,
+		"drawable.xml":                          renameDrawable,
+		"icons/res/drawable-nodpi/ic_calc.png":  "calc-nodpi",
+		"icons/res/drawable-xxhdpi/ic_calc.png": "calc-xxhdpi",
+		"icons/camera.png":                      "camera",
+		"icons/ic_back.png":                     "back",
+	}
+	for f, content := range extra {
+		files[f] = content
+	}
+	for f, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return PackFiles{
+		IconDir:   filepath.Join(dir, "icons"),
+		AppFilter: filepath.Join(dir, "appfilter.xml"),
+		IconPack:  filepath.Join(dir, "icon_pack.xml"),
+		Drawable:  filepath.Join(dir, "drawable.xml"),
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRenameDrawablesRewritesPack(t *testing.T) {
+	files := writeRenamePack(t, nil)
+	rules := []RenameRule{{Pattern: `^ic_`, Replace: ""}}
+
+	plan, err := RenameDrawables(files, rules, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(plan.Renames) != 2 || len(plan.Moves) != 3 || len(plan.Documents) != 3 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if got := readFile(t, files.AppFilter); got != renameAppFilter {
+		t.Fatalf("dry run changed appfilter:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(files.IconDir, "ic_back.png")); err != nil {
+		t.Fatalf("dry run moved a file: %v", err)
+	}
+
+	if _, err := RenameDrawables(files, rules, false); err != nil {
+		t.Fatalf("RenameDrawables: %v", err)
+	}
+
+	wantAppFilter := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <iconback img1="back"/>
+    <!-- Calculator -->
+    <item component="ComponentInfo{com.example.calc/com.example.calc.Main}" drawable="calc"/>
+    <!-- Camera -->
+    <item component="ComponentInfo{com.example.cam/com.example.cam.Main}" drawable="camera"/>
+</resources>
+`
+	if got := readFile(t, files.AppFilter); got != wantAppFilter {
+		t.Errorf("unexpected appfilter:\n%s", got)
+	}
+	wantIconPack := `<resources>
+    <string-array name="all">
+        <item>calc</item>
+        <item> camera </item>
+    </string-array>
+</resources>
+`
+	if got := readFile(t, files.IconPack); got != wantIconPack {
+		t.Errorf("unexpected icon_pack:\n%s", got)
+	}
+	if got := readFile(t, files.Drawable); got != `<resources>
+    <version>1</version>
+    <category title="Tools"/>
+    <item drawable="calc"/>
+    <item drawable="camera"/>
+</resources>
+` {
+		t.Errorf("unexpected drawable.xml:\n%s", got)
+	}
+
+	for f, content := range map[string]string{
+		"res/drawable-nodpi/calc.png":  "calc-nodpi",
+		"res/drawable-xxhdpi/calc.png": "calc-xxhdpi",
+		"back.png":                     "back",
+		"camera.png":                   "camera",
+	} {
+		if got := readFile(t, filepath.Join(files.IconDir, filepath.FromSlash(f))); got != content {
+			t.Errorf("%s: got %q, want %q", f, got, content)
+		}
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(files.AppFilter), "*.rename.*"))
+	if len(leftovers) > 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+}
+
+func TestPlanDrawableRenameCollisions(t *testing.T) {
+	files := writeRenamePack(t, map[string]string{
+		"icons/camera_alt.png": "alt",
+		"icons/clock.png":      "clock",
+		"icons/calc.webp":      "stale",
+	})
+	rules := []RenameRule{
+		{Pattern: `_alt$`, Replace: ""},        // camera_alt -> camera, which stays
+		{Pattern: `^clock$`, Replace: "Clock"}, // not a resource name
+		{Pattern: `^ic_`, Replace: ""},         // ic_calc -> calc, taken by calc.webp
+	}
+
+	plan, err := PlanDrawableRename(files, rules)
+	if err != nil {
+		t.Fatalf("PlanDrawableRename: %v", err)
+	}
+	reasons := make(map[string]string)
+	for _, c := range plan.Collisions {
+		reasons[c.To] = c.Reason
+	}
+	if len(reasons) != 3 || reasons["camera"] == "" || reasons["Clock"] == "" || reasons["calc"] == "" {
+		t.Fatalf("unexpected collisions: %+v", plan.Collisions)
+	}
+
+	if _, err := plan.Apply(); !errors.Is(err, ErrRenameCollision) {
+		t.Fatalf("expected ErrRenameCollision, got %v", err)
+	}
+	if got := readFile(t, files.AppFilter); got != renameAppFilter {
+		t.Fatalf("appfilter changed despite collisions:\n%s", got)
+	}
+}
+
+func TestRenameDrawablesTemplateAndRollback(t *testing.T) {
+	files := writeRenamePack(t, nil)
+	// ic_back is used by no item, so the template does not apply to it.
+	rules := []RenameRule{{Template: "{package}"}}
+
+	plan, err := PlanDrawableRename(files, rules)
+	if err != nil {
+		t.Fatalf("PlanDrawableRename: %v", err)
+	}
+	mapping := plan.Mapping()
+	if mapping["ic_calc"] != "com_example_calc" || mapping["camera"] != "com_example_cam" || len(mapping) != 2 {
+		t.Fatalf("unexpected mapping: %v", mapping)
+	}
+
+	txn, err := plan.Apply()
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(files.IconDir, "com_example_cam.png")); err != nil {
+		t.Fatalf("camera not moved: %v", err)
+	}
+	if err := txn.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if got := readFile(t, files.AppFilter); got != renameAppFilter {
+		t.Errorf("rollback left appfilter changed:\n%s", got)
+	}
+	if got := readFile(t, filepath.Join(files.IconDir, "res", "drawable-nodpi", "ic_calc.png")); got != "calc-nodpi" {
+		t.Errorf("rollback did not restore ic_calc: %q", got)
+	}
+}
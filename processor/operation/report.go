@@ -0,0 +1,279 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"circle-center/globals"
+)
+
+// ReportFormat selects how RenderDiffReport renders a diff.
+type ReportFormat string
+
+const (
+	// ReportMarkdown renders GitHub-flavoured Markdown tables.
+	ReportMarkdown ReportFormat = "markdown"
+	// ReportHTML renders a standalone HTML page.
+	ReportHTML ReportFormat = "html"
+)
+
+// maxThumbnailBytes is the largest icon file LocalThumbnails embeds.
+const maxThumbnailBytes = 256 * 1024
+
+// ThumbnailFunc returns the image URL of a drawable, usually a data URI, or ""
+// when there is none.
+type ThumbnailFunc func(drawable string) string
+
+// DiffReportOptions controls RenderDiffReport.
+type DiffReportOptions struct {
+	// Title is the report heading; "Appfilter changes" when empty.
+	Title string
+	// Thumbnails, when set, adds an icon column to HTML reports.
+	Thumbnails ThumbnailFunc
+}
+
+// LocalThumbnails returns a ThumbnailFunc serving the icons as data URIs. Only
+// raster variants are embedded, the best one first; files are read when a
+// report asks for them, and larger ones than 256 KiB are skipped.
+func LocalThumbnails(icons []globals.LocalIcon) ThumbnailFunc {
+	files := make(map[string][]string, len(icons))
+	for _, icon := range icons {
+		for _, v := range icon.Variants {
+			if v.Format != "xml" {
+				files[icon.PackageName] = append(files[icon.PackageName], v.FilePath)
+			}
+		}
+	}
+	return func(drawable string) string {
+		for _, path := range files[drawable] {
+			info, err := os.Stat(path)
+			if err != nil || info.Size() > maxThumbnailBytes {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			mime := http.DetectContentType(data)
+			if !strings.HasPrefix(mime, "image/") {
+				continue
+			}
+			return "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(data)
+		}
+		return ""
+	}
+}
+
+// RenderDiffReport writes diff as a human-readable report with sections for
+// added (only in the second file), removed (only in the first file) and
+// changed apps, each sorted by app name. App names come from the appfilter
+// comments, falling back to the package name.
+func RenderDiffReport(w io.Writer, diff *AppFilterDiff, format ReportFormat, opts DiffReportOptions) error {
+	data := newReportData(diff, opts)
+	var buf bytes.Buffer
+	switch format {
+	case ReportMarkdown:
+		writeMarkdownReport(&buf, data)
+	case ReportHTML:
+		if err := htmlReport.Execute(&buf, data); err != nil {
+			return fmt.Errorf("render report: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+	if _, err := buf.WriteTo(w); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
+
+// reportRow is one item of a report section.
+type reportRow struct {
+	App       string
+	Component string
+	Drawable  string
+	Thumb     template.URL
+}
+
+// reportChange is one changed item of a report.
+type reportChange struct {
+	App         string
+	Component   string
+	Fields      string
+	Before      string
+	After       string
+	BeforeThumb template.URL
+	AfterThumb  template.URL
+}
+
+// reportSection is an added or removed section of an HTML report.
+type reportSection struct {
+	Title  string
+	Class  string
+	Rows   []reportRow
+	Thumbs bool
+}
+
+// reportData is what both report formats are rendered from.
+type reportData struct {
+	Title     string
+	Thumbs    bool
+	Added     []reportRow
+	Removed   []reportRow
+	Changed   []reportChange
+	Unchanged int
+}
+
+// newReportData sorts the diff into report sections.
+func newReportData(diff *AppFilterDiff, opts DiffReportOptions) reportData {
+	data := reportData{Title: opts.Title, Thumbs: opts.Thumbnails != nil, Unchanged: len(diff.Common)}
+	if data.Title == "" {
+		data.Title = "Appfilter changes"
+	}
+	thumb := func(drawable string) template.URL {
+		if opts.Thumbnails == nil {
+			return ""
+		}
+		// Only images are trusted as URLs in the page.
+		url := opts.Thumbnails(drawable)
+		if strings.HasPrefix(url, "data:image/") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") {
+			return template.URL(url)
+		}
+		return ""
+	}
+	rows := func(items []globals.Item) []reportRow {
+		out := make([]reportRow, 0, len(items))
+		for _, item := range items {
+			out = append(out, reportRow{
+				App:       reportAppName(item),
+				Component: item.Component,
+				Drawable:  item.Drawable,
+				Thumb:     thumb(item.Drawable),
+			})
+		}
+		sort.SliceStable(out, func(i, j int) bool { return strings.ToLower(out[i].App) < strings.ToLower(out[j].App) })
+		return out
+	}
+
+	data.Added = rows(diff.OnlyInSecond)
+	data.Removed = rows(diff.OnlyInFirst)
+	data.Changed = make([]reportChange, 0, len(diff.Changed))
+	for _, ch := range diff.Changed {
+		app := reportAppName(ch.After)
+		if before := reportAppName(ch.Before); before != app {
+			app = before + " → " + app
+		}
+		data.Changed = append(data.Changed, reportChange{
+			App:         app,
+			Component:   ch.Component,
+			Fields:      strings.Join(ch.Fields, ", "),
+			Before:      ch.Before.Drawable,
+			After:       ch.After.Drawable,
+			BeforeThumb: thumb(ch.Before.Drawable),
+			AfterThumb:  thumb(ch.After.Drawable),
+		})
+	}
+	sort.SliceStable(data.Changed, func(i, j int) bool {
+		return strings.ToLower(data.Changed[i].App) < strings.ToLower(data.Changed[j].App)
+	})
+	return data
+}
+
+// reportAppName returns the app name shown for item.
+func reportAppName(item globals.Item) string {
+	switch {
+	case item.AppName != "":
+		return item.AppName
+	case item.PackageName != "":
+		return item.PackageName
+	}
+	return item.Component
+}
+
+// writeMarkdownReport renders data as Markdown.
+func writeMarkdownReport(buf *bytes.Buffer, data reportData) {
+	fmt.Fprintf(buf, "# %s\n\n", mdEscape(data.Title))
+	fmt.Fprintf(buf, "**%d added, %d removed, %d changed, %d unchanged**\n",
+		len(data.Added), len(data.Removed), len(data.Changed), data.Unchanged)
+
+	for _, section := range []struct {
+		title string
+		rows  []reportRow
+	}{{"Added", data.Added}, {"Removed", data.Removed}} {
+		fmt.Fprintf(buf, "\n## %s (%d)\n\n", section.title, len(section.rows))
+		if len(section.rows) == 0 {
+			buf.WriteString("_None_\n")
+			continue
+		}
+		buf.WriteString("| App | Component | Drawable |\n| --- | --- | --- |\n")
+		for _, r := range section.rows {
+			fmt.Fprintf(buf, "| %s | %s | %s |\n", mdEscape(r.App), mdCode(r.Component), mdCode(r.Drawable))
+		}
+	}
+
+	fmt.Fprintf(buf, "\n## Changed (%d)\n\n", len(data.Changed))
+	if len(data.Changed) == 0 {
+		buf.WriteString("_None_\n")
+		return
+	}
+	buf.WriteString("| App | Component | Before | After |\n| --- | --- | --- | --- |\n")
+	for _, c := range data.Changed {
+		fmt.Fprintf(buf, "| %s | %s | %s | %s |\n", mdEscape(c.App), mdCode(c.Component), mdCode(c.Before), mdCode(c.After))
+	}
+}
+
+// mdEscape escapes text for a Markdown table cell.
+func mdEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;", "\n", " ")
+	return r.Replace(s)
+}
+
+// mdCode renders s as inline code in a Markdown table cell.
+func mdCode(s string) string {
+	return "`" + strings.NewReplacer("`", "'", "|", `\|`, "\n", " ").Replace(s) + "`"
+}
+
+// htmlReport is the standalone HTML report page.
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"section": func(title, class string, rows []reportRow, thumbs bool) reportSection {
+		return reportSection{Title: title, Class: class, Rows: rows, Thumbs: thumbs}
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 2rem; color: #1f2328; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2rem; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: middle; }
+th { background: #f6f8fa; }
+code { font-size: 0.85em; }
+img { width: 48px; height: 48px; object-fit: contain; }
+.added { color: #1a7f37; } .removed { color: #cf222e; } .changed { color: #9a6700; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p><strong>{{len .Added}} added, {{len .Removed}} removed, {{len .Changed}} changed, {{.Unchanged}} unchanged</strong></p>
+{{template "rows" (section "Added" "added" .Added .Thumbs)}}
+{{template "rows" (section "Removed" "removed" .Removed .Thumbs)}}
+<h2 class="changed">Changed ({{len .Changed}})</h2>
+{{if .Changed}}<table>
+<tr><th>App</th><th>Component</th><th>Fields</th>{{if .Thumbs}}<th></th>{{end}}<th>Before</th>{{if .Thumbs}}<th></th>{{end}}<th>After</th></tr>
+{{range .Changed}}<tr><td>{{.App}}</td><td><code>{{.Component}}</code></td><td>{{.Fields}}</td>{{if $.Thumbs}}<td>{{if .BeforeThumb}}<img src="{{.BeforeThumb}}" alt="">{{end}}</td>{{end}}<td><code>{{.Before}}</code></td>{{if $.Thumbs}}<td>{{if .AfterThumb}}<img src="{{.AfterThumb}}" alt="">{{end}}</td>{{end}}<td><code>{{.After}}</code></td></tr>
+{{end}}</table>{{else}}<p><em>None</em></p>{{end}}
+</body>
+</html>
+{{define "rows"}}<h2 class="{{.Class}}">{{.Title}} ({{len .Rows}})</h2>
+{{if .Rows}}<table>
+<tr>{{if .Thumbs}}<th></th>{{end}}<th>App</th><th>Component</th><th>Drawable</th></tr>
+{{range .Rows}}<tr>{{if $.Thumbs}}<td>{{if .Thumb}}<img src="{{.Thumb}}" alt="">{{end}}</td>{{end}}<td>{{.App}}</td><td><code>{{.Component}}</code></td><td><code>{{.Drawable}}</code></td></tr>
+{{end}}</table>{{else}}<p><em>None</em></p>{{end}}{{end}}`))
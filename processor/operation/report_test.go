@@ -0,0 +1,87 @@
+package operation
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"circle-center/globals"
+)
+
+func reportDiff() *AppFilterDiff {
+	first := []globals.Item{
+		{Component: "ComponentInfo{com.calc/com.calc.Main}", Drawable: "calc", AppName: "Calc"},
+		{Component: "ComponentInfo{com.old/com.old.Main}", Drawable: "old", PackageName: "com.old"},
+		{Component: "ComponentInfo{com.cam/com.cam.Main}", Drawable: "cam", AppName: "Camera"},
+	}
+	second := []globals.Item{
+		{Component: "ComponentInfo{com.calc/com.calc.Main}", Drawable: "calc", AppName: "Calc"},
+		{Component: "ComponentInfo{com.cam/com.cam.Main}", Drawable: "camera", AppName: "Camera"},
+		{Component: "ComponentInfo{com.zoo/com.zoo.Main}", Drawable: "zoo", AppName: "Zoo | <b>"},
+		{Component: "ComponentInfo{com.ant/com.ant.Main}", Drawable: "ant", AppName: "Ant"},
+	}
+	return DiffItems(first, second, DiffOptions{})
+}
+
+func TestRenderDiffReportMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderDiffReport(&buf, reportDiff(), ReportMarkdown, DiffReportOptions{Title: "Release 2"}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# Release 2\n",
+		"**2 added, 1 removed, 1 changed, 1 unchanged**",
+		"## Added (2)",
+		"| Ant | `ComponentInfo{com.ant/com.ant.Main}` | `ant` |",
+		`| Zoo \| &lt;b> |`,
+		"## Removed (1)",
+		"| com.old |",
+		"| Camera | `ComponentInfo{com.cam/com.cam.Main}` | `cam` | `camera` |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "| Ant |") > strings.Index(out, "| Zoo") {
+		t.Errorf("added apps are not sorted by name:\n%s", out)
+	}
+}
+
+func TestRenderDiffReportHTML(t *testing.T) {
+	dir := t.TempDir()
+	// A 1x1 PNG.
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89\x00\x00\x00\rIDATx\x9cc\xf8\x0f\x00\x00\x01\x01\x00\x05\x18\xd8N\x00\x00\x00\x00IEND\xaeB`\x82")
+	path := filepath.Join(dir, "zoo.png")
+	if err := os.WriteFile(path, png, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	icons := []globals.LocalIcon{{PackageName: "zoo", FilePath: path, Variants: []globals.DrawableVariant{{Format: "png", FilePath: path}}}}
+
+	var buf bytes.Buffer
+	opts := DiffReportOptions{Thumbnails: LocalThumbnails(icons)}
+	if err := RenderDiffReport(&buf, reportDiff(), ReportHTML, opts); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "<!DOCTYPE html>") || !strings.Contains(out, "<title>Appfilter changes</title>") {
+		t.Errorf("not a standalone page:\n%s", out)
+	}
+	if !strings.Contains(out, `<img src="data:image/png;base64,`) {
+		t.Errorf("thumbnail not embedded:\n%s", out)
+	}
+	if strings.Count(out, "<img") != 1 {
+		t.Errorf("expected only the stored icon to be embedded:\n%s", out)
+	}
+	if strings.Contains(out, "<b>") || !strings.Contains(out, "Zoo | &lt;b&gt;") {
+		t.Errorf("app name not escaped:\n%s", out)
+	}
+
+	if err := RenderDiffReport(&buf, reportDiff(), "pdf", opts); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,173 @@
+package operation
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"circle-center/globals"
+	"circle-center/reader"
+)
+
+// ErrArchiveTooLarge is returned by UnpackZip when an archive exceeds one of
+// the configured UnpackLimits.
+var ErrArchiveTooLarge = errors.New("archive exceeds size limits")
+
+// UnpackLimits bounds how much UnpackZip is willing to extract. Sizes are
+// checked against the bytes actually decompressed, not the sizes claimed in
+// the ZIP headers.
+type UnpackLimits struct {
+	// MaxFiles is the maximum number of regular files.
+	MaxFiles int
+	// MaxFileSize is the maximum decompressed size of a single file.
+	MaxFileSize int64
+	// MaxTotalSize is the maximum decompressed size of all files together.
+	MaxTotalSize int64
+}
+
+// DefaultUnpackLimits is a reasonable bound for an uploaded icon set.
+var DefaultUnpackLimits = UnpackLimits{
+	MaxFiles:     20000,
+	MaxFileSize:  16 << 20,
+	MaxTotalSize: 512 << 20,
+}
+
+// UnpackZip extracts the ZIP archive read from r into dst, which must exist.
+// Entries that would escape dst (absolute paths, ".." components), symlinks
+// and other non-regular files are rejected, and extraction stops with
+// ErrArchiveTooLarge as soon as a limit is exceeded.
+func UnpackZip(r io.ReaderAt, size int64, dst string, limits UnpackLimits) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("open zip: %w", err)
+	}
+
+	root, err := filepath.Abs(dst)
+	if err != nil {
+		return fmt.Errorf("resolve destination: %w", err)
+	}
+
+	var files int
+	var total int64
+	for _, f := range zr.File {
+		target, err := safeJoin(root, f.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := f.Mode()
+		if mode.IsDir() {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return fmt.Errorf("create directory: %w", err)
+			}
+			continue
+		}
+		if !mode.IsRegular() {
+			return fmt.Errorf("unsupported zip entry %q: not a regular file", f.Name)
+		}
+
+		files++
+		if limits.MaxFiles > 0 && files > limits.MaxFiles {
+			return fmt.Errorf("%w: more than %d files", ErrArchiveTooLarge, limits.MaxFiles)
+		}
+
+		n, err := extractZipFile(f, target, limits.MaxFileSize)
+		if err != nil {
+			return err
+		}
+		total += n
+		if limits.MaxTotalSize > 0 && total > limits.MaxTotalSize {
+			return fmt.Errorf("%w: more than %d bytes in total", ErrArchiveTooLarge, limits.MaxTotalSize)
+		}
+	}
+
+	return nil
+}
+
+// safeJoin resolves a ZIP entry name below root, rejecting names that would
+// end up outside of it (zip-slip).
+func safeJoin(root, name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("illegal zip entry %q: absolute path", name)
+	}
+
+	target := filepath.Join(root, filepath.FromSlash(name))
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("illegal zip entry %q: outside of destination", name)
+	}
+	return target, nil
+}
+
+// extractZipFile writes a single entry to target and returns the number of
+// bytes written. At most maxSize bytes are decompressed.
+func extractZipFile(f *zip.File, target string, maxSize int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return 0, fmt.Errorf("create directory: %w", err)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return 0, fmt.Errorf("open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("create %s: %w", f.Name, err)
+	}
+	defer out.Close()
+
+	src := io.Reader(rc)
+	if maxSize > 0 {
+		src = io.LimitReader(rc, maxSize+1)
+	}
+	n, err := io.Copy(out, src)
+	if err != nil {
+		return n, fmt.Errorf("extract %s: %w", f.Name, err)
+	}
+	if maxSize > 0 && n > maxSize {
+		return n, fmt.Errorf("%w: %s is larger than %d bytes", ErrArchiveTooLarge, f.Name, maxSize)
+	}
+	return n, nil
+}
+
+// ReadIconArchive unpacks a ZIP of icons into dir and returns the icons it
+// contains, as reader.ParseIconDirectory would. Archives that wrap everything
+// in a single top-level folder are handled transparently.
+func ReadIconArchive(r io.ReaderAt, size int64, dir string, limits UnpackLimits) ([]globals.LocalIcon, error) {
+	root, err := UnpackIconArchive(r, size, dir, limits)
+	if err != nil {
+		return nil, err
+	}
+	return reader.ParseIconDirectory(root)
+}
+
+// UnpackIconArchive unpacks a ZIP of icons into dir and returns the directory
+// holding the icons: dir itself, or the folder an archive wraps everything in.
+func UnpackIconArchive(r io.ReaderAt, size int64, dir string, limits UnpackLimits) (string, error) {
+	if err := UnpackZip(r, size, dir, limits); err != nil {
+		return "", err
+	}
+
+	root := dir
+	for {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			return "", fmt.Errorf("read unpacked icons: %w", err)
+		}
+		if len(entries) != 1 || !entries[0].IsDir() {
+			return root, nil
+		}
+		name := entries[0].Name()
+		if strings.HasPrefix(name, "drawable") || strings.HasPrefix(name, "mipmap") {
+			return root, nil
+		}
+		root = filepath.Join(root, name)
+	}
+}
@@ -0,0 +1,107 @@
+package operation
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"circle-center/globals"
+)
+
+func buildZip(t *testing.T, files map[string]string) *bytes.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestUnpackZipRejectsZipSlip(t *testing.T) {
+	for _, name := range []string{"../evil.png", "icons/../../evil.png", "/abs/evil.png"} {
+		dst := t.TempDir()
+		r := buildZip(t, map[string]string{name: "x"})
+		if err := UnpackZip(r, r.Size(), dst, DefaultUnpackLimits); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dst), "evil.png")); err == nil {
+			t.Errorf("%q escaped the destination", name)
+		}
+	}
+}
+
+func TestUnpackZipLimits(t *testing.T) {
+	r := buildZip(t, map[string]string{"a.png": strings.Repeat("a", 100)})
+	err := UnpackZip(r, r.Size(), t.TempDir(), UnpackLimits{MaxFileSize: 10})
+	if !errors.Is(err, ErrArchiveTooLarge) {
+		t.Fatalf("expected ErrArchiveTooLarge for file size, got %v", err)
+	}
+
+	r = buildZip(t, map[string]string{"a.png": "a", "b.png": "b", "c.png": "c"})
+	err = UnpackZip(r, r.Size(), t.TempDir(), UnpackLimits{MaxFiles: 2})
+	if !errors.Is(err, ErrArchiveTooLarge) {
+		t.Fatalf("expected ErrArchiveTooLarge for file count, got %v", err)
+	}
+
+	r = buildZip(t, map[string]string{"a.png": "aaaa", "b.png": "bbbb"})
+	err = UnpackZip(r, r.Size(), t.TempDir(), UnpackLimits{MaxTotalSize: 6})
+	if !errors.Is(err, ErrArchiveTooLarge) {
+		t.Fatalf("expected ErrArchiveTooLarge for total size, got %v", err)
+	}
+}
+
+func TestReadIconArchiveAndDiff(t *testing.T) {
+	r := buildZip(t, map[string]string{
+		"icons/com.example.calc.png": "png",
+		"icons/camera.png":           "png",
+		"icons/notes.png":            "png",
+	})
+	icons, err := ReadIconArchive(r, r.Size(), t.TempDir(), DefaultUnpackLimits)
+	if err != nil {
+		t.Fatalf("ReadIconArchive: %v", err)
+	}
+	if len(icons) != 3 {
+		t.Fatalf("expected 3 icons, got %d", len(icons))
+	}
+
+	items := []globals.Item{
+		{Component: "ComponentInfo{com.example.calc/com.example.calc.Main}", Drawable: "com_example_calc", PackageName: "com.example.calc"},
+		{Component: "ComponentInfo{com.example.cam/com.example.cam.Main}", Drawable: "camera", PackageName: "com.example.cam"},
+		{Component: "ComponentInfo{com.example.maps/com.example.maps.Main}", Drawable: "maps", PackageName: "com.example.maps"},
+	}
+	report := DiffIconsAgainstAppFilter(icons, items)
+
+	if want := []string{"camera", "notes"}; strings.Join(report.MissingIcons, ",") != strings.Join(want, ",") {
+		t.Errorf("MissingIcons = %v, want %v", report.MissingIcons, want)
+	}
+	if want := []string{"notes"}; strings.Join(report.OrphanDrawables, ",") != strings.Join(want, ",") {
+		t.Errorf("OrphanDrawables = %v, want %v", report.OrphanDrawables, want)
+	}
+	if len(report.UnusedEntries) != 1 || report.UnusedEntries[0].Drawable != "maps" {
+		t.Errorf("UnusedEntries = %+v", report.UnusedEntries)
+	}
+
+	packReport := DiffIconsAgainstIconPack(icons, globals.IconPackResources{
+		Arrays: []globals.StringArray{{Name: "all", Items: []string{"camera", "maps"}}},
+	})
+	if want := []string{"com_example_calc", "notes"}; strings.Join(packReport.OrphanDrawables, ",") != strings.Join(want, ",") {
+		t.Errorf("OrphanDrawables = %v, want %v", packReport.OrphanDrawables, want)
+	}
+	if len(packReport.UnusedEntries) != 1 || packReport.UnusedEntries[0].Drawable != "maps" {
+		t.Errorf("UnusedEntries = %+v", packReport.UnusedEntries)
+	}
+}
@@ -0,0 +1,537 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"circle-center/reader"
+)
+
+// nodeKind classifies a node of a rawDoc tree.
+type nodeKind int
+
+const (
+	nodeText nodeKind = iota
+	nodeComment
+	nodeElement
+	nodeOther // processing instructions, directives
+)
+
+// rawNode is one node of a format-preserving XML tree. Every node remembers
+// the exact bytes it was parsed from, so untouched parts of a document are
+// written back byte for byte. Elements whose children were edited are marked
+// dirty and rebuilt from their raw start tag, children and raw end tag.
+type rawNode struct {
+	kind   nodeKind
+	raw    []byte
+	offset int64 // byte offset of the node in the source
+	parent *rawNode
+
+	// Element-only fields.
+	name     string
+	attrs    []xml.Attr
+	startTag []byte
+	endTag   []byte // nil for self-closing elements
+	children []*rawNode
+	dirty    bool
+}
+
+// rawDoc is a parsed XML document whose top-level nodes (declaration,
+// whitespace, the root element) are kept in order.
+type rawDoc struct {
+	nodes []*rawNode
+}
+
+// parseRawDoc reads the whole document from r and builds a rawDoc.
+func parseRawDoc(r io.Reader) (*rawDoc, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read xml: %w", err)
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(src))
+	dec.CharsetReader = reader.CharsetReader
+	doc := &rawDoc{}
+	// stack[0] is a pseudo element collecting the top-level nodes.
+	stack := []*rawNode{{kind: nodeElement}}
+
+	for {
+		start := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read xml: %w", err)
+		}
+		end := dec.InputOffset()
+		raw := src[start:end]
+		parent := stack[len(stack)-1]
+		// Top-level nodes have no parent element.
+		var owner *rawNode
+		if len(stack) > 1 {
+			owner = parent
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			el := &rawNode{
+				kind:     nodeElement,
+				offset:   start,
+				parent:   owner,
+				name:     t.Name.Local,
+				attrs:    append([]xml.Attr(nil), t.Attr...),
+				startTag: raw,
+			}
+			parent.children = append(parent.children, el)
+			stack = append(stack, el)
+		case xml.EndElement:
+			el := parent
+			stack = stack[:len(stack)-1]
+			if end > start {
+				el.endTag = raw
+			}
+			el.raw = src[el.offset:end]
+		case xml.Comment:
+			parent.children = append(parent.children, &rawNode{kind: nodeComment, raw: raw, offset: start, parent: owner})
+		case xml.CharData:
+			parent.children = append(parent.children, &rawNode{kind: nodeText, raw: raw, offset: start, parent: owner})
+		default:
+			parent.children = append(parent.children, &rawNode{kind: nodeOther, raw: raw, offset: start, parent: owner})
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("failed to read xml: unexpected EOF")
+	}
+
+	doc.nodes = stack[0].children
+	return doc, nil
+}
+
+// root returns the document element with the given local name, or nil.
+func (d *rawDoc) root(name string) *rawNode {
+	for _, n := range d.nodes {
+		if n.kind == nodeElement && n.name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// ensureDeclaration prepends an XML declaration when the document has none.
+func (d *rawDoc) ensureDeclaration() {
+	for _, n := range d.nodes {
+		if n.kind == nodeOther && bytes.HasPrefix(n.raw, []byte("<?xml")) {
+			return
+		}
+	}
+	decl := &rawNode{kind: nodeOther, raw: []byte(`<?xml version="1.0" encoding="utf-8"?>`)}
+	d.nodes = append([]*rawNode{decl, newTextNode("\n")}, d.nodes...)
+}
+
+// WriteTo writes the document, reproducing untouched nodes byte for byte.
+func (d *rawDoc) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	for _, n := range d.nodes {
+		n.write(&buf)
+	}
+	return buf.WriteTo(w)
+}
+
+// write serialises n into buf.
+func (n *rawNode) write(buf *bytes.Buffer) {
+	if n.kind != nodeElement || !n.dirty {
+		buf.Write(n.raw)
+		return
+	}
+
+	if n.endTag == nil {
+		// A self-closing container that gained children needs an explicit
+		// end tag now.
+		if len(n.children) == 0 {
+			buf.Write(n.startTag)
+			return
+		}
+		open := bytes.TrimRight(bytes.TrimSuffix(bytes.TrimSpace(n.startTag), []byte("/>")), " \t\r\n")
+		buf.Write(open)
+		buf.WriteString(">")
+		for _, c := range n.children {
+			c.write(buf)
+		}
+		buf.WriteString("</" + n.name + ">")
+		return
+	}
+
+	buf.Write(n.startTag)
+	for _, c := range n.children {
+		c.write(buf)
+	}
+	buf.Write(n.endTag)
+}
+
+// attr returns the value of the attribute with the given local name.
+func (n *rawNode) attr(name string) string {
+	for _, a := range n.attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// text returns the unescaped character data directly inside n.
+func (n *rawNode) text() string {
+	var sb strings.Builder
+	for _, c := range n.children {
+		if c.kind != nodeText {
+			continue
+		}
+		var s string
+		if err := xml.Unmarshal(append(append([]byte("<t>"), c.raw...), "</t>"...), &s); err == nil {
+			sb.WriteString(s)
+		}
+	}
+	return sb.String()
+}
+
+// isBlank reports whether n is a whitespace-only text node.
+func (n *rawNode) isBlank() bool {
+	return n.kind == nodeText && len(bytes.TrimSpace(n.raw)) == 0
+}
+
+// commentText returns the inner text of a comment node.
+func (n *rawNode) commentText() string {
+	s := strings.TrimPrefix(string(n.raw), "<!--")
+	return strings.TrimSuffix(s, "-->")
+}
+
+// setAttr rewrites a single attribute value inside the raw start tag,
+// keeping attribute order, quoting and spacing intact. Missing attributes are
+// appended before the tag is closed.
+func (n *rawNode) setAttr(name, value string, style docStyle) {
+	for i, a := range n.attrs {
+		if a.Name.Local == name {
+			if a.Value == value {
+				return
+			}
+			n.attrs[i].Value = value
+			// The start tag lists the attributes in the order they were parsed.
+			spans := attrValueSpans(n.startTag)
+			if i >= len(spans) {
+				return
+			}
+			start, end := spans[i][0], spans[i][1]
+			q := string(n.startTag[start])
+			tag := append([]byte{}, n.startTag[:start]...)
+			tag = append(tag, q+escapeAttr(value, q)+q...)
+			tag = append(tag, n.startTag[end:]...)
+			n.setStartTag(tag)
+			return
+		}
+	}
+
+	n.attrs = append(n.attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	tag := n.startTag
+	closing := []byte(">")
+	if n.endTag == nil {
+		closing = []byte("/>")
+	}
+	body := bytes.TrimSuffix(tag, closing)
+	trailing := len(body) - len(bytes.TrimRight(body, " \t\r\n"))
+	insert := " " + name + "=" + style.quote + escapeAttr(value, style.quote) + style.quote
+	out := append([]byte{}, body[:len(body)-trailing]...)
+	out = append(out, insert...)
+	out = append(out, body[len(body)-trailing:]...)
+	out = append(out, closing...)
+	n.setStartTag(out)
+}
+
+// attrValueSpans scans a start tag and returns, for each attribute in order,
+// the byte range of its quoted value including the quotes.
+func attrValueSpans(tag []byte) [][2]int {
+	isSpace := func(b byte) bool { return b == ' ' || b == '\t' || b == '\r' || b == '\n' }
+	i := 1 // skip '<'
+	for i < len(tag) && !isSpace(tag[i]) && tag[i] != '>' && tag[i] != '/' {
+		i++
+	}
+
+	var spans [][2]int
+	for {
+		for i < len(tag) && isSpace(tag[i]) {
+			i++
+		}
+		if i >= len(tag) || tag[i] == '>' || tag[i] == '/' {
+			return spans
+		}
+		for i < len(tag) && !isSpace(tag[i]) && tag[i] != '=' {
+			i++
+		}
+		for i < len(tag) && isSpace(tag[i]) {
+			i++
+		}
+		if i >= len(tag) || tag[i] != '=' {
+			return spans
+		}
+		i++
+		for i < len(tag) && isSpace(tag[i]) {
+			i++
+		}
+		if i >= len(tag) || (tag[i] != '"' && tag[i] != '\'') {
+			return spans
+		}
+		end := bytes.IndexByte(tag[i+1:], tag[i])
+		if end < 0 {
+			return spans
+		}
+		end += i + 2
+		spans = append(spans, [2]int{i, end})
+		i = end
+	}
+}
+
+// setStartTag replaces the start tag of n.
+func (n *rawNode) setStartTag(tag []byte) {
+	n.startTag = tag
+	n.touch()
+}
+
+// touch marks n and all of its ancestors as modified so they are rebuilt on
+// write instead of copied from the source.
+func (n *rawNode) touch() {
+	for p := n; p != nil; p = p.parent {
+		p.dirty = true
+	}
+}
+
+// escapeAttr escapes an attribute value for the given quote character.
+func escapeAttr(v, quote string) string {
+	r := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", quote, map[string]string{`"`: "&quot;", "'": "&apos;"}[quote])
+	return r.Replace(v)
+}
+
+// docStyle captures the formatting conventions of an existing document so
+// that inserted nodes blend in.
+type docStyle struct {
+	newline    string // "\n" or "\r\n"
+	indent     string // indentation of a direct child of the container
+	quote      string // attribute quote character
+	selfClose  string // "/>" or " />"
+	commentPad string // padding inside comments, usually " "
+}
+
+// attrQuotePattern finds the quote character of the first attribute value.
+var attrQuotePattern = regexp.MustCompile(`=\s*(["'])`)
+
+// detectStyle inspects the children of container to infer its style. sample
+// is the element name whose formatting should be copied (e.g. "item").
+func detectStyle(src []byte, container *rawNode, sample string) docStyle {
+	style := docStyle{newline: "\n", indent: "    ", quote: `"`, selfClose: "/>", commentPad: " "}
+	if bytes.Contains(src, []byte("\r\n")) {
+		style.newline = "\r\n"
+	}
+
+	foundIndent, foundTag, foundComment := false, false, false
+	for i, c := range container.children {
+		switch {
+		case c.isBlank() && !foundIndent && i+1 < len(container.children):
+			s := string(c.raw)
+			if idx := strings.LastIndex(s, "\n"); idx >= 0 {
+				style.indent = s[idx+1:]
+				foundIndent = true
+			}
+		case c.kind == nodeComment && !foundComment:
+			text := c.commentText()
+			style.commentPad = ""
+			if strings.HasPrefix(text, " ") {
+				style.commentPad = " "
+			}
+			foundComment = true
+		case c.kind == nodeElement && c.name == sample && !foundTag:
+			tag := string(c.startTag)
+			if m := attrQuotePattern.FindStringSubmatch(tag); m != nil {
+				style.quote = m[1]
+			}
+			if c.endTag == nil && strings.HasSuffix(tag, " />") {
+				style.selfClose = " />"
+			}
+			foundTag = true
+		}
+	}
+	return style
+}
+
+// newTextNode returns a text node with the given raw content.
+func newTextNode(s string) *rawNode {
+	return &rawNode{kind: nodeText, raw: []byte(s)}
+}
+
+// newCommentNode renders a comment in the document's style.
+func newCommentNode(text string, style docStyle) *rawNode {
+	text = strings.ReplaceAll(text, "--", "- -")
+	return &rawNode{kind: nodeComment, raw: []byte("<!--" + style.commentPad + text + style.commentPad + "-->")}
+}
+
+// newEmptyElement renders a self-closing element with attributes in the
+// given order.
+func newEmptyElement(name string, attrs [][2]string, style docStyle) *rawNode {
+	var sb strings.Builder
+	sb.WriteString("<" + name)
+	xattrs := make([]xml.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		sb.WriteString(" " + a[0] + "=" + style.quote + escapeAttr(a[1], style.quote) + style.quote)
+		xattrs = append(xattrs, xml.Attr{Name: xml.Name{Local: a[0]}, Value: a[1]})
+	}
+	sb.WriteString(style.selfClose)
+	raw := []byte(sb.String())
+	return &rawNode{kind: nodeElement, name: name, attrs: xattrs, startTag: raw, raw: raw}
+}
+
+// newTextElement renders <name>text</name>.
+func newTextElement(name, text string) *rawNode {
+	var esc bytes.Buffer
+	_ = xml.EscapeText(&esc, []byte(text))
+	start := []byte("<" + name + ">")
+	end := []byte("</" + name + ">")
+	raw := append(append(append([]byte{}, start...), esc.Bytes()...), end...)
+	return &rawNode{
+		kind:     nodeElement,
+		name:     name,
+		startTag: start,
+		endTag:   end,
+		children: []*rawNode{newTextNode(esc.String())},
+		raw:      raw,
+	}
+}
+
+// newlineCount returns how many line breaks a whitespace node contains.
+func newlineCount(n *rawNode) int {
+	if n == nil || !n.isBlank() {
+		return 0
+	}
+	return bytes.Count(n.raw, []byte("\n"))
+}
+
+// block describes the nodes that belong to one child element of a container:
+// the whitespace that leads into it, the comment attached to it (the last
+// comment with only whitespace between it and the element) and the element.
+// start and end are indices into container.children, end is inclusive.
+type block struct {
+	start, end int
+	lead       *rawNode // leading whitespace, may be nil
+	comment    *rawNode // attached comment, may be nil
+}
+
+// blockAt computes the block of the element at index i of container.
+func blockAt(container *rawNode, i int) block {
+	b := block{start: i, end: i}
+	ch := container.children
+	j := i - 1
+	if j >= 0 && ch[j].isBlank() {
+		b.lead = ch[j]
+		b.start = j
+		j--
+	}
+	if j >= 0 && ch[j].kind == nodeComment {
+		b.comment = ch[j]
+		b.start = j
+		b.lead = nil
+		j--
+		if j >= 0 && ch[j].isBlank() {
+			b.lead = ch[j]
+			b.start = j
+		}
+	}
+	return b
+}
+
+// removeBlock cuts the block of the element at index i out of container and
+// returns its nodes (without the leading whitespace). When the removed block
+// opened a visual group (its leading whitespace had more line breaks than the
+// following one) the next block inherits that whitespace so blank-line
+// grouping survives.
+func removeBlock(container *rawNode, i int) []*rawNode {
+	b := blockAt(container, i)
+	ch := container.children
+
+	// Only hand the lead on when another node follows the next whitespace;
+	// otherwise that whitespace is the indentation of the closing tag.
+	if b.lead != nil && b.end+2 < len(ch) {
+		next := ch[b.end+1]
+		if next.isBlank() && newlineCount(b.lead) > newlineCount(next) {
+			ch[b.end+1] = newTextNode(string(b.lead.raw))
+		}
+	}
+
+	var cut []*rawNode
+	for k := b.start; k <= b.end; k++ {
+		if ch[k] == b.lead {
+			continue
+		}
+		cut = append(cut, ch[k])
+	}
+
+	container.children = append(append([]*rawNode{}, ch[:b.start]...), ch[b.end+1:]...)
+	container.touch()
+	return cut
+}
+
+// insertNodes inserts nodes at index pos of container, preceded by a
+// newline+indent whitespace node.
+func insertNodes(container *rawNode, pos int, nodes []*rawNode, style docStyle) {
+	ins := append([]*rawNode{newTextNode(style.newline + style.indent)}, nodes...)
+	adopt(container, ins)
+	ch := container.children
+	out := make([]*rawNode, 0, len(ch)+len(ins))
+	out = append(out, ch[:pos]...)
+	out = append(out, ins...)
+	out = append(out, ch[pos:]...)
+	container.children = out
+	container.touch()
+}
+
+// insertBlockBefore inserts nodes as a new block in front of the element at
+// index i. The new block takes over the anchor's leading whitespace (so it
+// opens the anchor's group) and the anchor gets a plain newline+indent.
+func insertBlockBefore(container *rawNode, i int, nodes []*rawNode, style docStyle) {
+	b := blockAt(container, i)
+	if b.lead == nil {
+		insertNodes(container, b.start, nodes, style)
+		// Separate the new block from the anchor.
+		pos := b.start + 1 + len(nodes)
+		container.children = append(container.children[:pos], append([]*rawNode{newTextNode(style.newline + style.indent)}, container.children[pos:]...)...)
+		return
+	}
+
+	lead := string(b.lead.raw)
+	container.children[b.start] = newTextNode(style.newline + style.indent)
+	ins := append([]*rawNode{newTextNode(lead)}, nodes...)
+	adopt(container, ins)
+	ch := container.children
+	out := make([]*rawNode, 0, len(ch)+len(ins))
+	out = append(out, ch[:b.start]...)
+	out = append(out, ins...)
+	out = append(out, ch[b.start:]...)
+	container.children = out
+	container.touch()
+}
+
+// adopt sets container as the parent of nodes.
+func adopt(container *rawNode, nodes []*rawNode) {
+	for _, n := range nodes {
+		n.parent = container
+	}
+}
+
+// childIndex returns the index of child within container, or -1.
+func childIndex(container, child *rawNode) int {
+	for i, c := range container.children {
+		if c == child {
+			return i
+		}
+	}
+	return -1
+}
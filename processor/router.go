@@ -13,4 +13,10 @@ func RegisterRoutes(router gin.IRouter) {
 	processorGroup.POST("/diffappfilters", svc.DiffAppFilters)
 	processorGroup.POST("/difficons", svc.DiffIcons)
 	processorGroup.POST("/mergeappfilters", svc.MergeAppFilters)
+	processorGroup.POST("/merge3", svc.MergeThreeWay)
+	processorGroup.POST("/lint", svc.Lint)
+	processorGroup.POST("/format", svc.Format)
+	processorGroup.POST("/exportpatch", svc.ExportPatch)
+	processorGroup.POST("/applypatch", svc.ApplyPatch)
+	processorGroup.POST("/convertpack", svc.ConvertPack)
 }
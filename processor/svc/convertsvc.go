@@ -0,0 +1,105 @@
+package svc
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"circle-center/globals"
+	"circle-center/processor/operation"
+	"circle-center/reader"
+)
+
+// ConvertPack handles POST /convertpack which accepts form-data with:
+// "icon_pack" (file) and/or "drawable" (file) - at least one is required
+// "to" (string) - "icon_pack" or "drawable"; required when both files are
+// sent, otherwise the format of the missing file
+// "all" (string, optional) - name of the array listing every drawable, "all"
+// by default; send it empty to leave that array out
+// It returns the generated file and, when both files were sent, the drawables
+// found in only one of them.
+func ConvertPack(c *gin.Context) {
+	var iconPack *globals.IconPackResources
+	if header, err := c.FormFile("icon_pack"); err == nil {
+		f, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open icon_pack: " + err.Error()})
+			return
+		}
+		defer f.Close()
+		res, err := reader.ParseIconPackFromReader(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parse icon_pack failed: " + err.Error()})
+			return
+		}
+		iconPack = &res
+	}
+
+	var drawable *globals.DrawableResources
+	if header, err := c.FormFile("drawable"); err == nil {
+		f, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open drawable: " + err.Error()})
+			return
+		}
+		defer f.Close()
+		res, err := reader.ParseDrawableFromReader(f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parse drawable failed: " + err.Error()})
+			return
+		}
+		drawable = &res
+	}
+
+	if iconPack == nil && drawable == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing icon_pack or drawable field"})
+		return
+	}
+
+	to := strings.TrimSpace(c.PostForm("to"))
+	switch {
+	case to == "" && iconPack == nil:
+		to = "icon_pack"
+	case to == "" && drawable == nil:
+		to = "drawable"
+	case to != "icon_pack" && to != "drawable":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + to})
+		return
+	}
+	if (to == "icon_pack" && drawable == nil) || (to == "drawable" && iconPack == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no source file to generate " + to + " from"})
+		return
+	}
+
+	opts := operation.ConvertOptions{AllArray: operation.DefaultAllArray}
+	if all, ok := c.GetPostForm("all"); ok {
+		opts.AllArray = strings.TrimSpace(all)
+	}
+
+	var content strings.Builder
+	result := gin.H{"target": to}
+	if to == "icon_pack" {
+		out := operation.DrawableToIconPack(*drawable, opts)
+		if err := operation.WriteIconPack(&content, out, opts); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		result["array_count"] = len(out.Arrays)
+	} else {
+		out := operation.IconPackToDrawable(*iconPack, opts)
+		if err := operation.WriteDrawable(&content, out, opts); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		result["category_count"] = len(out.Categories)
+	}
+	if iconPack != nil && drawable != nil {
+		result["drift"] = operation.ComparePackFiles(*iconPack, *drawable)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"result":  result,
+		"content": content.String(),
+	})
+}
@@ -1,10 +1,12 @@
 package svc
 
 import (
-	"circle-center/globals"
 	"circle-center/processor/operation"
+	"errors"
 	"io"
 	"net/http"
+	"os"
+	"strings"
 
 	"circle-center/reader"
 
@@ -13,6 +15,11 @@ import (
 
 // diffAppFilters handles POST /diffappfilters which accepts two form-data file fields:
 // "file1" and "file2" containing appfilter.xml files to compare.
+// An optional "normalize" field set to "true" matches components after
+// normalising them (relative activities, whitespace, wrapper case).
+// An optional "report" field set to "markdown" or "html" also renders a
+// human-readable report, titled by the optional "title" field. HTML reports
+// embed thumbnails from an optional "icons" ZIP archive.
 // It returns the differences in JSON format.
 func DiffAppFilters(c *gin.Context) {
 	// Get first file
@@ -58,80 +65,155 @@ func DiffAppFilters(c *gin.Context) {
 		return
 	}
 
-	// Build sets for efficient lookup
-	firstSet := make(map[string]globals.Item)
-	secondSet := make(map[string]globals.Item)
-
-	for _, item := range firstItems {
-		firstSet[item.Component] = item
+	opts := operation.DiffOptions{
+		NormalizeComponents: c.PostForm("normalize") == "true",
 	}
+	diff := operation.DiffItems(firstItems, secondItems, opts)
 
-	for _, item := range secondItems {
-		secondSet[item.Component] = item
+	result := gin.H{
+		"only_in_first":  diff.OnlyInFirst,
+		"only_in_second": diff.OnlyInSecond,
+		"common":         diff.Common,
+		"changed":        diff.Changed,
+		"summary": gin.H{
+			"first_count":       len(firstItems),
+			"second_count":      len(secondItems),
+			"only_first_count":  len(diff.OnlyInFirst),
+			"only_second_count": len(diff.OnlyInSecond),
+			"common_count":      len(diff.Common),
+			"changed_count":     len(diff.Changed),
+		},
 	}
 
-	// Find differences
-	var onlyInFirst, onlyInSecond, common []globals.Item
-
-	// Items only in first file
-	for _, item := range firstItems {
-		if _, exists := secondSet[item.Component]; !exists {
-			onlyInFirst = append(onlyInFirst, item)
+	if format := c.PostForm("report"); format != "" {
+		if format != string(operation.ReportMarkdown) && format != string(operation.ReportHTML) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report: " + format})
+			return
 		}
-	}
-
-	// Items only in second file
-	for _, item := range secondItems {
-		if _, exists := firstSet[item.Component]; !exists {
-			onlyInSecond = append(onlyInSecond, item)
+		reportOpts := operation.DiffReportOptions{Title: c.PostForm("title")}
+		if iconsHeader, err := c.FormFile("icons"); err == nil {
+			icons, err := iconsHeader.Open()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open icons: " + err.Error()})
+				return
+			}
+			defer icons.Close()
+
+			tmpDir, err := os.MkdirTemp("", "diffreport_*")
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "create temp dir failed"})
+				return
+			}
+			defer os.RemoveAll(tmpDir)
+
+			localIcons, err := operation.ReadIconArchive(icons, iconsHeader.Size, tmpDir, operation.DefaultUnpackLimits)
+			if err != nil {
+				status := http.StatusBadRequest
+				if errors.Is(err, operation.ErrArchiveTooLarge) {
+					status = http.StatusRequestEntityTooLarge
+				}
+				c.JSON(status, gin.H{"error": "unpack icons failed: " + err.Error()})
+				return
+			}
+			reportOpts.Thumbnails = operation.LocalThumbnails(localIcons)
 		}
-	}
 
-	// Common items
-	for _, item := range firstItems {
-		if _, exists := secondSet[item.Component]; exists {
-			common = append(common, item)
+		var report strings.Builder
+		if err := operation.RenderDiffReport(&report, diff, operation.ReportFormat(format), reportOpts); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
 		}
+		result["report"] = report.String()
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"only_in_first":  onlyInFirst,
-		"only_in_second": onlyInSecond,
-		"common":         common,
-		"summary": gin.H{
-			"first_count":       len(firstItems),
-			"second_count":      len(secondItems),
-			"only_first_count":  len(onlyInFirst),
-			"only_second_count": len(onlyInSecond),
-			"common_count":      len(common),
-		},
-	})
+	c.JSON(http.StatusOK, result)
 }
 
-// diffIcons handles POST /difficons which accepts form-data with:
-// "icon_dir" (directory path) and "icon_pack" (file path) to compare local icons
-// with icon_pack.xml entries.
+// DiffIcons handles POST /difficons which accepts form-data with:
+// "icons" (file, required) - a ZIP archive of icon files (a flat folder or an
+// Android res tree)
+// "appfilter" or "icon_pack" (file, one required) - the entries to compare with
+// The archive is unpacked into a private temporary directory with size limits
+// and zip-slip protection, and removed afterwards.
 func DiffIcons(c *gin.Context) {
-	iconDir := c.PostForm("icon_dir")
-	if iconDir == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing icon_dir field"})
+	iconsHeader, err := c.FormFile("icons")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing icons field: " + err.Error()})
 		return
 	}
 
-	iconPackPath := c.PostForm("icon_pack")
-	if iconPackPath == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "missing icon_pack field"})
+	appFilterHeader, appFilterErr := c.FormFile("appfilter")
+	iconPackHeader, iconPackErr := c.FormFile("icon_pack")
+	if appFilterErr != nil && iconPackErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing appfilter or icon_pack field"})
 		return
 	}
 
-	missing, err := operation.FindMissingIcons(iconDir, iconPackPath)
+	icons, err := iconsHeader.Open()
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "diff failed: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open icons: " + err.Error()})
 		return
 	}
+	defer icons.Close()
+
+	tmpDir, err := os.MkdirTemp("", "difficons_*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "create temp dir failed"})
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	localIcons, err := operation.ReadIconArchive(icons, iconsHeader.Size, tmpDir, operation.DefaultUnpackLimits)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, operation.ErrArchiveTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, gin.H{"error": "unpack icons failed: " + err.Error()})
+		return
+	}
+
+	var report operation.IconDiffReport
+	if appFilterErr == nil {
+		appFilter, err := appFilterHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open appfilter: " + err.Error()})
+			return
+		}
+		defer appFilter.Close()
+
+		items, err := reader.ParseFromReader(appFilter)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parse appfilter failed: " + err.Error()})
+			return
+		}
+		report = operation.DiffIconsAgainstAppFilter(localIcons, items)
+	} else {
+		iconPack, err := iconPackHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open icon_pack: " + err.Error()})
+			return
+		}
+		defer iconPack.Close()
+
+		res, err := reader.ParseIconPackFromReader(iconPack)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parse icon_pack failed: " + err.Error()})
+			return
+		}
+		report = operation.DiffIconsAgainstIconPack(localIcons, res)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"missing_icons": missing,
-		"count":         len(missing),
+		"missing_icons":    report.MissingIcons,
+		"orphan_drawables": report.OrphanDrawables,
+		"unused_entries":   report.UnusedEntries,
+		"count":            len(report.MissingIcons),
+		"summary": gin.H{
+			"icon_count":            len(localIcons),
+			"missing_icons_count":   len(report.MissingIcons),
+			"orphan_drawable_count": len(report.OrphanDrawables),
+			"unused_entry_count":    len(report.UnusedEntries),
+		},
 	})
 }
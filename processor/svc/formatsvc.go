@@ -0,0 +1,61 @@
+package svc
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"circle-center/processor/operation"
+)
+
+// Format handles POST /format which accepts form-data with:
+// "appfilter" (file, required) - the appfilter.xml to format
+// "sort" (string, optional) - "app_name" (default) or "package"
+// "indent" (int, optional) - number of spaces per level, 4 by default
+// It returns the canonical content together with a summary.
+func Format(c *gin.Context) {
+	appFilterHeader, err := c.FormFile("appfilter")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing appfilter field: " + err.Error()})
+		return
+	}
+
+	var opts operation.FormatOptions
+	switch sort := operation.FormatSort(c.PostForm("sort")); sort {
+	case "", operation.SortByAppName, operation.SortByPackage:
+		opts.Sort = sort
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort: " + string(sort)})
+		return
+	}
+
+	if raw := c.PostForm("indent"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 8 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid indent: " + raw})
+			return
+		}
+		opts.Indent = strings.Repeat(" ", n)
+	}
+
+	appFilter, err := appFilterHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open appfilter: " + err.Error()})
+		return
+	}
+	defer appFilter.Close()
+
+	var content strings.Builder
+	result, err := operation.FormatAppFilter(appFilter, &content, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"result":  result,
+		"content": content.String(),
+	})
+}
@@ -0,0 +1,71 @@
+package svc
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"circle-center/processor/operation"
+	"circle-center/reader"
+)
+
+// Lint handles POST /lint which accepts form-data with:
+// "appfilter" (file, required) - the appfilter.xml to check
+// "icon_pack" (file, optional) - icon_pack.xml used by the missing-from-icon-pack rule
+// "rules" (string, optional) - JSON object mapping rule ids to a severity
+// ("error", "warning", "info" or "off"), e.g. {"missing-app-name":"off"}
+func Lint(c *gin.Context) {
+	appFilterHeader, err := c.FormFile("appfilter")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing appfilter field: " + err.Error()})
+		return
+	}
+
+	appFilter, err := appFilterHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open appfilter: " + err.Error()})
+		return
+	}
+	defer appFilter.Close()
+
+	var cfg operation.LintConfig
+	if raw := c.PostForm("rules"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.Rules); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rules: " + err.Error()})
+			return
+		}
+	}
+
+	if iconPackHeader, err := c.FormFile("icon_pack"); err == nil {
+		iconPack, err := iconPackHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open icon_pack: " + err.Error()})
+			return
+		}
+		defer iconPack.Close()
+
+		res, err := reader.ParseIconPackFromReader(iconPack)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parse icon_pack failed: " + err.Error()})
+			return
+		}
+		cfg.IconPack = &res
+	}
+
+	findings, err := operation.LintAppFilter(appFilter, cfg)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lint failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"findings": findings,
+		"summary": gin.H{
+			"total":    len(findings),
+			"errors":   operation.CountFindings(findings, operation.SeverityError),
+			"warnings": operation.CountFindings(findings, operation.SeverityWarning),
+			"infos":    operation.CountFindings(findings, operation.SeverityInfo),
+		},
+	})
+}
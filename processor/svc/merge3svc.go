@@ -0,0 +1,64 @@
+package svc
+
+import (
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"circle-center/processor/operation"
+)
+
+// Merge3Request represents the form options for a three-way merge.
+type Merge3Request struct {
+	// If true, conflicting items are written with git-style conflict markers
+	// instead of keeping the "ours" version.
+	Markers bool `form:"markers" json:"markers"`
+}
+
+// MergeThreeWay handles POST /merge3 which accepts form-data with three
+// appfilter.xml files: "base" (common ancestor), "ours" and "theirs". Changes
+// made on one side are applied automatically; incompatible changes are
+// returned in result.Conflicts.
+func MergeThreeWay(c *gin.Context) {
+	var headers [3]*multipart.FileHeader
+	for i, field := range []string{"base", "ours", "theirs"} {
+		h, err := c.FormFile(field)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing " + field})
+			return
+		}
+		headers[i] = h
+	}
+
+	var req Merge3Request
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	var files [3]multipart.File
+	for i, h := range headers {
+		f, err := h.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open upload: " + err.Error()})
+			return
+		}
+		defer f.Close()
+		files[i] = f
+	}
+
+	result, content, err := operation.MergeThreeWay(files[0], files[1], files[2], operation.Merge3Options{
+		Markers: req.Markers,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"result":    result,
+		"content":   string(content),
+		"conflicts": len(result.Conflicts),
+	})
+}
@@ -2,8 +2,7 @@ package svc
 
 import (
 	"net/http"
-	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -41,32 +40,23 @@ func MergeAppFilters(c *gin.Context) {
 		return
 	}
 
-	// Create temporary directory for processing
-	tmpDir, err := os.MkdirTemp("", "merge_*")
+	// Open uploads directly; the merge runs entirely in memory
+	first, err := file1.Open()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "create temp dir failed"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "open file1 failed"})
 		return
 	}
-	defer os.RemoveAll(tmpDir)
+	defer first.Close()
 
-	// Save uploaded files
-	file1Path := filepath.Join(tmpDir, "file1.xml")
-	if err := c.SaveUploadedFile(file1, file1Path); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "save file1 failed"})
-		return
-	}
-
-	file2Path := filepath.Join(tmpDir, "file2.xml")
-	if err := c.SaveUploadedFile(file2, file2Path); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "save file2 failed"})
+	second, err := file2.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "open file2 failed"})
 		return
 	}
+	defer second.Close()
 
 	// Prepare merge request
 	mergeReq := operation.MergeRequest{
-		FirstFile:      file1Path,
-		SecondFile:     file2Path,
-		OutputFile:     filepath.Join(tmpDir, "output.xml"),
 		Mode:           operation.MergeSelected,
 		MergeIntoFirst: req.MergeIntoFirst,
 	}
@@ -79,7 +69,8 @@ func MergeAppFilters(c *gin.Context) {
 	}
 
 	// Perform merge in memory
-	result, content, err := operation.MergeAppFiltersInMemory(mergeReq)
+	var content strings.Builder
+	result, err := operation.MergeAppFilterReaders(first, second, &content, mergeReq)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -88,6 +79,6 @@ func MergeAppFilters(c *gin.Context) {
 	// Return result
 	c.JSON(http.StatusOK, gin.H{
 		"result":  result,
-		"content": content,
+		"content": content.String(),
 	})
 }
@@ -0,0 +1,134 @@
+package svc
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"circle-center/processor/operation"
+	"circle-center/reader"
+)
+
+// ExportPatch handles POST /exportpatch which accepts two form-data file fields,
+// "file1" and "file2", and returns the JSON patch that turns file1 into file2.
+// An optional "normalize" field set to "true" matches components after
+// normalising them, as /diffappfilters does.
+func ExportPatch(c *gin.Context) {
+	file1Header, err := c.FormFile("file1")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file1 field: " + err.Error()})
+		return
+	}
+
+	file2Header, err := c.FormFile("file2")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing file2 field: " + err.Error()})
+		return
+	}
+
+	file1, err := file1Header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open file1: " + err.Error()})
+		return
+	}
+	defer file1.Close()
+
+	file2, err := file2Header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open file2: " + err.Error()})
+		return
+	}
+	defer file2.Close()
+
+	firstItems, err := reader.ParseFromReader(file1)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parse file1 failed: " + err.Error()})
+		return
+	}
+
+	secondItems, err := reader.ParseFromReader(file2)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parse file2 failed: " + err.Error()})
+		return
+	}
+
+	patch := operation.CreatePatch(firstItems, secondItems, operation.DiffOptions{
+		NormalizeComponents: c.PostForm("normalize") == "true",
+	})
+
+	c.JSON(http.StatusOK, patch)
+}
+
+// ApplyPatch handles POST /applypatch which accepts form-data with:
+// "appfilter" (file, required) - the appfilter.xml to patch
+// "patch" (file or string, required) - the JSON patch produced by /exportpatch
+// On success it returns the patched content. If the patch does not match the
+// target it responds with 409 and the list of conflicts.
+func ApplyPatch(c *gin.Context) {
+	appFilterHeader, err := c.FormFile("appfilter")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing appfilter field: " + err.Error()})
+		return
+	}
+
+	rawPatch, err := readPatchField(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var patch operation.AppFilterPatch
+	if err := json.Unmarshal(rawPatch, &patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid patch: " + err.Error()})
+		return
+	}
+
+	appFilter, err := appFilterHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open appfilter: " + err.Error()})
+		return
+	}
+	defer appFilter.Close()
+
+	var content strings.Builder
+	result, err := operation.ApplyPatch(appFilter, &content, &patch)
+	if errors.Is(err, operation.ErrPatchConflict) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     err.Error(),
+			"conflicts": result.Conflicts,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "apply patch failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"result":  result,
+		"content": content.String(),
+	})
+}
+
+// readPatchField returns the "patch" form value, accepting either an uploaded
+// file or a plain form field.
+func readPatchField(c *gin.Context) ([]byte, error) {
+	if header, err := c.FormFile("patch"); err == nil {
+		f, err := header.Open()
+		if err != nil {
+			return nil, errors.New("cannot open patch: " + err.Error())
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}
+
+	raw := c.PostForm("patch")
+	if raw == "" {
+		return nil, errors.New("missing patch field")
+	}
+	return []byte(raw), nil
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/beevik/etree"
 
@@ -11,19 +13,36 @@ import (
 )
 
 // ParseFromReader reads an appfilter.xml file from the provided io.Reader and
-// returns the slice of parsed globals.Item structures.
+// returns the slice of parsed globals.Item structures. It is a thin wrapper
+// around StreamAppFilter that collects every streamed item.
 func ParseFromReader(r io.Reader) ([]globals.Item, error) {
+	var items []globals.Item
+	err := StreamAppFilter(r, func(it globals.Item) error {
+		items = append(items, it)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ParseAppFilterDocument reads an appfilter.xml file from the provided
+// io.Reader and returns the full globals.AppFilter model, including the
+// pack-level <iconback>, <iconmask>, <iconupon>, <scale>, <calendar> and
+// <dynamic-clock> elements that ParseFromReader does not expose.
+func ParseAppFilterDocument(r io.Reader) (globals.AppFilter, error) {
 	doc := etree.NewDocument()
 	if _, err := doc.ReadFrom(r); err != nil {
-		return nil, fmt.Errorf("failed to read xml: %w", err)
+		return globals.AppFilter{}, fmt.Errorf("failed to read xml: %w", err)
 	}
 
 	root := doc.SelectElement("resources")
 	if root == nil {
-		return nil, fmt.Errorf("missing <resources> root element")
+		return globals.AppFilter{}, fmt.Errorf("missing <resources> root element")
 	}
 
-	var items []globals.Item
+	var af globals.AppFilter
 	// Iterate through all child tokens of <resources> to capture comment nodes
 	// that appear directly before each <item>. The last encountered comment
 	// prior to an <item> element is taken as the application's human-readable
@@ -35,28 +54,62 @@ func ParseFromReader(r io.Reader) ([]globals.Item, error) {
 			// Store trimmed comment text for the next <item> element.
 			currentComment = ParseCommentText(t.Data)
 		case *etree.Element:
-			if t.Tag == "item" {
+			switch t.Tag {
+			case "item":
 				comp := t.SelectAttrValue("component", "")
 				drawable := t.SelectAttrValue("drawable", "")
 
 				pkg, act := ParseComponentInfo(comp)
 
-				items = append(items, globals.Item{
+				af.Items = append(af.Items, globals.Item{
 					Component:    comp,
 					Drawable:     drawable,
 					PackageName:  pkg,
 					ActivityName: act,
 					AppName:      currentComment,
 				})
+			case "calendar":
+				comp := t.SelectAttrValue("component", "")
+				pkg, act := ParseComponentInfo(comp)
 
-				// Reset comment after use to avoid incorrectly assigning it to
-				// subsequent items when they lack an explicit comment.
-				currentComment = ""
+				af.Calendars = append(af.Calendars, globals.Calendar{
+					Component:    comp,
+					Prefix:       t.SelectAttrValue("prefix", ""),
+					AppName:      currentComment,
+					PackageName:  pkg,
+					ActivityName: act,
+				})
+			case "iconback":
+				af.IconBack = append(af.IconBack, imageAttrs(t)...)
+			case "iconmask":
+				af.IconMask = append(af.IconMask, imageAttrs(t)...)
+			case "iconupon":
+				af.IconUpon = append(af.IconUpon, imageAttrs(t)...)
+			case "scale":
+				if f, err := strconv.ParseFloat(strings.TrimSpace(t.SelectAttrValue("factor", "")), 64); err == nil {
+					af.Scale = f
+				}
+			case "dynamic-clock":
+				af.DynamicClocks = append(af.DynamicClocks, globals.DynamicClock{
+					Drawable:         t.SelectAttrValue("drawable", ""),
+					HourLayerIndex:   intAttr(t, "hourLayerIndex"),
+					MinuteLayerIndex: intAttr(t, "minuteLayerIndex"),
+					SecondLayerIndex: intAttr(t, "secondLayerIndex"),
+					DefaultHour:      intAttr(t, "defaultHour"),
+					DefaultMinute:    intAttr(t, "defaultMinute"),
+					DefaultSecond:    intAttr(t, "defaultSecond"),
+				})
+			default:
+				continue
 			}
+
+			// Reset comment after use to avoid incorrectly assigning it to
+			// subsequent elements when they lack an explicit comment.
+			currentComment = ""
 		}
 	}
 
-	return items, nil
+	return af, nil
 }
 
 // ParseAppFilterFile opens an appfilter.xml at the given path and returns the
@@ -70,3 +123,44 @@ func ParseAppFilterFile(path string) ([]globals.Item, error) {
 
 	return ParseFromReader(f)
 }
+
+// ParseAppFilterDocumentFile opens an appfilter.xml at the given path and
+// returns the full globals.AppFilter model.
+func ParseAppFilterDocumentFile(path string) (globals.AppFilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return globals.AppFilter{}, fmt.Errorf("cannot open file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return ParseAppFilterDocument(f)
+}
+
+// imageAttrs returns the values of the img1..imgN attributes of an
+// <iconback>/<iconmask>/<iconupon> element in document order.
+func imageAttrs(el *etree.Element) []string {
+	var imgs []string
+	for _, attr := range el.Attr {
+		if !strings.HasPrefix(attr.Key, "img") {
+			continue
+		}
+		if v := strings.TrimSpace(attr.Value); v != "" {
+			imgs = append(imgs, v)
+		}
+	}
+	return imgs
+}
+
+// intAttr parses an integer attribute, returning nil when it is missing or
+// malformed.
+func intAttr(el *etree.Element, key string) *int {
+	attr := el.SelectAttr(key)
+	if attr == nil {
+		return nil
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(attr.Value))
+	if err != nil {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,85 @@
+package reader
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleAppFilter = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <!-- Pack settings -->
+    <iconback img1="iconback_1" img2="iconback_2"/>
+    <iconmask img1="iconmask"/>
+    <iconupon img1="iconupon"/>
+    <scale factor="0.85"/>
+
+    <!-- Calendar -->
+    <calendar component="ComponentInfo{com.android.calendar/com.android.calendar.AllInOneActivity}" prefix="calendar_"/>
+    <dynamic-clock drawable="clock" hourLayerIndex="1" minuteLayerIndex="2" defaultHour="10" defaultMinute="10"/>
+
+    <!-- Browser -->
+    <item component="ComponentInfo{com.android.browser/com.android.browser.BrowserActivity}" drawable="browser"/>
+    <item component="ComponentInfo{com.example.app/.Main}" drawable="example"/>
+</resources>`
+
+func TestParseAppFilterDocument(t *testing.T) {
+	af, err := ParseAppFilterDocument(strings.NewReader(sampleAppFilter))
+	if err != nil {
+		t.Fatalf("parse appfilter document: %v", err)
+	}
+
+	if len(af.IconBack) != 2 || af.IconBack[0] != "iconback_1" || af.IconBack[1] != "iconback_2" {
+		t.Errorf("unexpected iconback: %v", af.IconBack)
+	}
+	if len(af.IconMask) != 1 || af.IconMask[0] != "iconmask" {
+		t.Errorf("unexpected iconmask: %v", af.IconMask)
+	}
+	if len(af.IconUpon) != 1 || af.IconUpon[0] != "iconupon" {
+		t.Errorf("unexpected iconupon: %v", af.IconUpon)
+	}
+	if af.Scale != 0.85 {
+		t.Errorf("expected scale 0.85, got %v", af.Scale)
+	}
+
+	if len(af.Calendars) != 1 {
+		t.Fatalf("expected 1 calendar, got %d", len(af.Calendars))
+	}
+	cal := af.Calendars[0]
+	if cal.Prefix != "calendar_" || cal.PackageName != "com.android.calendar" || cal.AppName != "Calendar" {
+		t.Errorf("unexpected calendar: %+v", cal)
+	}
+
+	if len(af.DynamicClocks) != 1 {
+		t.Fatalf("expected 1 dynamic clock, got %d", len(af.DynamicClocks))
+	}
+	clock := af.DynamicClocks[0]
+	if clock.Drawable != "clock" || clock.HourLayerIndex == nil || *clock.HourLayerIndex != 1 {
+		t.Errorf("unexpected dynamic clock: %+v", clock)
+	}
+	if clock.SecondLayerIndex != nil {
+		t.Errorf("expected nil second layer index, got %d", *clock.SecondLayerIndex)
+	}
+
+	if len(af.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(af.Items))
+	}
+	if af.Items[0].AppName != "Browser" || af.Items[0].Drawable != "browser" {
+		t.Errorf("unexpected first item: %+v", af.Items[0])
+	}
+	if af.Items[1].AppName != "" {
+		t.Errorf("expected empty app name for second item, got %q", af.Items[1].AppName)
+	}
+}
+
+func TestParseFromReaderKeepsItemsOnly(t *testing.T) {
+	items, err := ParseFromReader(strings.NewReader(sampleAppFilter))
+	if err != nil {
+		t.Fatalf("parse appfilter: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if items[1].PackageName != "com.example.app" || items[1].ActivityName != ".Main" {
+		t.Errorf("unexpected component parse: %+v", items[1])
+	}
+}
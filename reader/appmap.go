@@ -0,0 +1,59 @@
+package reader
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/beevik/etree"
+
+	"circle-center/globals"
+)
+
+// ParseAppMapFromReader parses an appmap.xml file from the provided io.Reader.
+// As with appfilter.xml, a comment directly before an <item> is used as the
+// item's AppName.
+func ParseAppMapFromReader(r io.Reader) (globals.AppMapResources, error) {
+	doc := etree.NewDocument()
+	if _, err := doc.ReadFrom(r); err != nil {
+		return globals.AppMapResources{}, fmt.Errorf("failed to read xml: %w", err)
+	}
+
+	root := doc.SelectElement("appmap")
+	if root == nil {
+		return globals.AppMapResources{}, fmt.Errorf("missing <appmap> root element")
+	}
+
+	var res globals.AppMapResources
+	var currentComment string
+	for _, tok := range root.Child {
+		switch t := tok.(type) {
+		case *etree.Comment:
+			currentComment = ParseCommentText(t.Data)
+		case *etree.Element:
+			if t.Tag != "item" {
+				continue
+			}
+			res.Items = append(res.Items, globals.AppMapItem{
+				Class:    strings.TrimSpace(t.SelectAttrValue("class", "")),
+				Drawable: strings.TrimSpace(t.SelectAttrValue("name", "")),
+				AppName:  currentComment,
+			})
+			currentComment = ""
+		}
+	}
+
+	return res, nil
+}
+
+// ParseAppMapFile opens and parses an appmap.xml file from the given path.
+func ParseAppMapFile(path string) (globals.AppMapResources, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return globals.AppMapResources{}, fmt.Errorf("cannot open file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return ParseAppMapFromReader(f)
+}
@@ -0,0 +1,168 @@
+package reader
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"circle-center/globals"
+)
+
+// maxArchiveEntrySize caps how many bytes are decompressed from a single
+// archive entry, protecting against zip bombs.
+const maxArchiveEntrySize = 32 << 20
+
+// archiveCandidates lists, per file type, the entry paths searched inside an
+// archive in order of preference. Plain-text assets are preferred over
+// compiled res/xml resources. Matching is done on the path suffix so that
+// source ZIPs (e.g. app/src/main/assets/appfilter.xml) work as well as APKs.
+var archiveCandidates = map[FileType][]string{
+	FileTypeAppFilter: {"assets/appfilter.xml", "res/xml/appfilter.xml"},
+	FileTypeDrawable:  {"assets/drawable.xml", "res/xml/drawable.xml"},
+	FileTypeAppMap:    {"assets/appmap.xml", "res/xml/appmap.xml"},
+}
+
+// IsArchive reports whether data starts with a ZIP local file header, which
+// also covers APK files.
+func IsArchive(data []byte) bool {
+	return len(data) >= 4 && bytes.Equal(data[:4], []byte("PK\x03\x04"))
+}
+
+// ParseArchive opens an APK or ZIP archive and parses every supported icon
+// pack file it contains. Compiled binary XML is decoded transparently. An
+// error is returned only if the archive is unreadable or contains none of
+// appfilter.xml, drawable.xml or appmap.xml.
+func ParseArchive(r io.ReaderAt, size int64) (globals.ArchiveResources, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return globals.ArchiveResources{}, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	res := globals.ArchiveResources{Sources: make(map[string]string)}
+
+	if data, name, err := readArchiveEntry(zr, FileTypeAppFilter); err != nil {
+		return globals.ArchiveResources{}, err
+	} else if data != nil {
+		items, err := ParseFromReader(bytes.NewReader(data))
+		if err != nil {
+			return globals.ArchiveResources{}, fmt.Errorf("parse %s: %w", name, err)
+		}
+		res.AppFilter = items
+		res.Sources[string(FileTypeAppFilter)] = name
+	}
+
+	if data, name, err := readArchiveEntry(zr, FileTypeDrawable); err != nil {
+		return globals.ArchiveResources{}, err
+	} else if data != nil {
+		d, err := ParseDrawableFromReader(bytes.NewReader(data))
+		if err != nil {
+			return globals.ArchiveResources{}, fmt.Errorf("parse %s: %w", name, err)
+		}
+		res.Drawable = &d
+		res.Sources[string(FileTypeDrawable)] = name
+	}
+
+	if data, name, err := readArchiveEntry(zr, FileTypeAppMap); err != nil {
+		return globals.ArchiveResources{}, err
+	} else if data != nil {
+		am, err := ParseAppMapFromReader(bytes.NewReader(data))
+		if err != nil {
+			return globals.ArchiveResources{}, fmt.Errorf("parse %s: %w", name, err)
+		}
+		res.AppMap = &am
+		res.Sources[string(FileTypeAppMap)] = name
+	}
+
+	if len(res.Sources) == 0 {
+		return globals.ArchiveResources{}, fmt.Errorf("archive contains no appfilter.xml, drawable.xml or appmap.xml")
+	}
+	return res, nil
+}
+
+// ParseAppFilterFromArchive opens an APK or ZIP archive, locates its
+// appfilter.xml and returns the same items ParseFromReader would.
+func ParseAppFilterFromArchive(r io.ReaderAt, size int64) ([]globals.Item, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	data, name, err := readArchiveEntry(zr, FileTypeAppFilter)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("archive contains no appfilter.xml")
+	}
+
+	items, err := ParseFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	return items, nil
+}
+
+// readArchiveEntry finds the preferred entry for the given file type and
+// returns its textual XML content together with the entry name. It returns a
+// nil slice without error when no candidate exists.
+func readArchiveEntry(zr *zip.Reader, fileType FileType) ([]byte, string, error) {
+	for _, candidate := range archiveCandidates[fileType] {
+		for _, f := range zr.File {
+			name := strings.TrimPrefix(f.Name, "/")
+			if name != candidate && !strings.HasSuffix(name, "/"+candidate) {
+				continue
+			}
+
+			data, err := readZipFile(f)
+			if err != nil {
+				return nil, "", fmt.Errorf("read %s: %w", f.Name, err)
+			}
+			if IsBinaryXML(data) {
+				if data, err = DecodeBinaryXML(data); err != nil {
+					return nil, "", fmt.Errorf("decode %s: %w", f.Name, err)
+				}
+			}
+			return data, f.Name, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// readZipFile reads a single archive entry, refusing entries that
+// decompress to more than maxArchiveEntrySize bytes.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxArchiveEntrySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxArchiveEntrySize {
+		return nil, fmt.Errorf("entry exceeds %d bytes", maxArchiveEntrySize)
+	}
+	return data, nil
+}
+
+// ParseArchiveFile opens an APK or ZIP archive at the given path and parses
+// the icon pack files inside it. It wraps ParseArchive for convenience.
+func ParseArchiveFile(path string) (globals.ArchiveResources, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return globals.ArchiveResources{}, fmt.Errorf("cannot open file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return globals.ArchiveResources{}, fmt.Errorf("cannot stat file %s: %w", path, err)
+	}
+
+	return ParseArchive(f, info.Size())
+}
@@ -0,0 +1,317 @@
+package reader
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// axmlNode is a tiny element tree used to build binary XML fixtures. typed
+// attributes carry a Res_value without a raw string, as aapt2 writes for
+// numbers, booleans and references.
+type axmlNode struct {
+	name     string
+	attrs    [][2]string
+	typed    []axmlTypedAttr
+	children []axmlNode
+}
+
+// axmlTypedAttr is an attribute stored only as a typed Res_value.
+type axmlTypedAttr struct {
+	name     string
+	dataType byte
+	data     uint32
+}
+
+// axmlOptions selects the encoder flavour. The zero value mimics aapt: a
+// UTF-16 string pool and a raw string kept for every attribute. aapt2
+// defaults to a UTF-8 pool and drops raw values of string attributes.
+type axmlOptions struct {
+	utf8Pool  bool
+	dropRaw   bool
+	resMapIDs []uint32
+}
+
+// encodeBinaryXML compiles n into the AXML format with a UTF-16 string pool
+// and string-typed attributes, mimicking what aapt produces for res/xml.
+func encodeBinaryXML(n axmlNode) []byte {
+	return encodeBinaryXMLWith(n, axmlOptions{})
+}
+
+// encodeBinaryXMLWith compiles n into the AXML format using opts.
+func encodeBinaryXMLWith(n axmlNode, opts axmlOptions) []byte {
+	var pool []string
+	index := map[string]uint32{}
+	intern := func(s string) uint32 {
+		if i, ok := index[s]; ok {
+			return i
+		}
+		index[s] = uint32(len(pool))
+		pool = append(pool, s)
+		return index[s]
+	}
+
+	var body bytes.Buffer
+	le := binary.LittleEndian
+	var walk func(n axmlNode)
+	walk = func(n axmlNode) {
+		size := 16 + 20 + 20*(len(n.attrs)+len(n.typed))
+		hdr := make([]byte, size)
+		le.PutUint16(hdr[0:], axmlStartElementType)
+		le.PutUint16(hdr[2:], 16)
+		le.PutUint32(hdr[4:], uint32(size))
+		le.PutUint32(hdr[12:], axmlNoIndex)
+		ext := hdr[16:]
+		le.PutUint32(ext[0:], axmlNoIndex)
+		le.PutUint32(ext[4:], intern(n.name))
+		le.PutUint16(ext[8:], 20)
+		le.PutUint16(ext[10:], 20)
+		le.PutUint16(ext[12:], uint16(len(n.attrs)+len(n.typed)))
+		for i, a := range n.attrs {
+			at := ext[20+i*20:]
+			le.PutUint32(at[0:], axmlNoIndex)
+			le.PutUint32(at[4:], intern(a[0]))
+			v := intern(a[1])
+			le.PutUint32(at[8:], v)
+			if opts.dropRaw {
+				le.PutUint32(at[8:], axmlNoIndex)
+			}
+			le.PutUint16(at[12:], 8)
+			at[15] = axmlTypeString
+			le.PutUint32(at[16:], v)
+		}
+		for i, a := range n.typed {
+			at := ext[20+(len(n.attrs)+i)*20:]
+			le.PutUint32(at[0:], axmlNoIndex)
+			le.PutUint32(at[4:], intern(a.name))
+			le.PutUint32(at[8:], axmlNoIndex)
+			le.PutUint16(at[12:], 8)
+			at[15] = a.dataType
+			le.PutUint32(at[16:], a.data)
+		}
+		body.Write(hdr)
+		for _, c := range n.children {
+			walk(c)
+		}
+		end := make([]byte, 24)
+		le.PutUint16(end[0:], axmlEndElementType)
+		le.PutUint16(end[2:], 16)
+		le.PutUint32(end[4:], 24)
+		le.PutUint32(end[12:], axmlNoIndex)
+		le.PutUint32(end[16:], axmlNoIndex)
+		le.PutUint32(end[20:], intern(n.name))
+		body.Write(end)
+	}
+	walk(n)
+
+	var strs bytes.Buffer
+	offsets := make([]uint32, len(pool))
+	// UTF-8 pool lengths take one byte, or two with the high bit set.
+	utf8Len := func(n int) []byte {
+		if n > 0x7F {
+			return []byte{byte(n>>8) | 0x80, byte(n)}
+		}
+		return []byte{byte(n)}
+	}
+	for i, s := range pool {
+		offsets[i] = uint32(strs.Len())
+		units := utf16.Encode([]rune(s))
+		if opts.utf8Pool {
+			strs.Write(utf8Len(len(units)))
+			strs.Write(utf8Len(len(s)))
+			strs.WriteString(s)
+			strs.WriteByte(0)
+			continue
+		}
+		_ = binary.Write(&strs, le, uint16(len(units)))
+		_ = binary.Write(&strs, le, units)
+		_ = binary.Write(&strs, le, uint16(0))
+	}
+	for strs.Len()%4 != 0 {
+		strs.WriteByte(0)
+	}
+	poolHeader := 28
+	poolSize := poolHeader + 4*len(pool) + strs.Len()
+	sp := make([]byte, poolHeader)
+	le.PutUint16(sp[0:], axmlStringPoolType)
+	le.PutUint16(sp[2:], uint16(poolHeader))
+	le.PutUint32(sp[4:], uint32(poolSize))
+	le.PutUint32(sp[8:], uint32(len(pool)))
+	if opts.utf8Pool {
+		le.PutUint32(sp[16:], axmlUTF8Flag)
+	}
+	le.PutUint32(sp[20:], uint32(poolHeader+4*len(pool)))
+
+	var resMap bytes.Buffer
+	if len(opts.resMapIDs) > 0 {
+		rm := make([]byte, 8)
+		le.PutUint16(rm[0:], axmlResourceMapType)
+		le.PutUint16(rm[2:], 8)
+		le.PutUint32(rm[4:], uint32(8+4*len(opts.resMapIDs)))
+		resMap.Write(rm)
+		_ = binary.Write(&resMap, le, opts.resMapIDs)
+	}
+
+	var out bytes.Buffer
+	total := 8 + poolSize + resMap.Len() + body.Len()
+	fh := make([]byte, 8)
+	le.PutUint16(fh[0:], axmlFileType)
+	le.PutUint16(fh[2:], 8)
+	le.PutUint32(fh[4:], uint32(total))
+	out.Write(fh)
+	out.Write(sp)
+	_ = binary.Write(&out, le, offsets)
+	out.Write(strs.Bytes())
+	out.Write(resMap.Bytes())
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func TestDecodeBinaryXML(t *testing.T) {
+	bin := encodeBinaryXML(axmlNode{name: "resources", children: []axmlNode{
+		{name: "item", attrs: [][2]string{{"component", "ComponentInfo{com.a/com.a.Main}"}, {"drawable", "a & b"}}},
+	}})
+	if !IsBinaryXML(bin) {
+		t.Fatal("fixture not recognised as binary xml")
+	}
+
+	text, err := DecodeBinaryXML(bin)
+	if err != nil {
+		t.Fatalf("decode binary xml: %v", err)
+	}
+
+	items, err := ParseFromReader(bytes.NewReader(text))
+	if err != nil {
+		t.Fatalf("parse decoded xml: %v\n%s", err, text)
+	}
+	if len(items) != 1 || items[0].PackageName != "com.a" || items[0].Drawable != "a & b" {
+		t.Errorf("unexpected items: %+v", items)
+	}
+}
+
+func TestDecodeBinaryXMLAapt2Layout(t *testing.T) {
+	// aapt2 output: UTF-8 string pool, a resource map and no raw values, so
+	// every attribute goes through formatTypedValue.
+	long := "ComponentInfo{com.example." + strings.Repeat("long", 40) + "/.Main}"
+	bin := encodeBinaryXMLWith(axmlNode{name: "resources", children: []axmlNode{
+		{name: "iconback", attrs: [][2]string{{"img1", "iconback"}}},
+		{name: "scale", typed: []axmlTypedAttr{{name: "factor", dataType: axmlTypeFloat, data: math.Float32bits(0.85)}}},
+		{name: "item", attrs: [][2]string{{"component", "ComponentInfo{com.café/com.café.Main}"}, {"drawable", "cafe"}}},
+		{name: "item", attrs: [][2]string{{"component", long}, {"drawable", "long_app"}}},
+		{name: "dynamic-clock", attrs: [][2]string{{"drawable", "clock"}}, typed: []axmlTypedAttr{
+			{name: "hourLayerIndex", dataType: axmlTypeIntDec, data: 1},
+			{name: "defaultHour", dataType: axmlTypeIntHex, data: 0xa},
+		}},
+		{name: "calendar", attrs: [][2]string{{"component", "ComponentInfo{com.cal/.Main}"}}, typed: []axmlTypedAttr{
+			{name: "prefix", dataType: axmlTypeReference, data: 0x7f080001},
+		}},
+	}}, axmlOptions{utf8Pool: true, dropRaw: true, resMapIDs: []uint32{0x01010003}})
+
+	text, err := DecodeBinaryXML(bin)
+	if err != nil {
+		t.Fatalf("decode binary xml: %v", err)
+	}
+	for _, want := range []string{`factor="0.85"`, `hourLayerIndex="1"`, `defaultHour="0xa"`, `prefix="@0x7f080001"`} {
+		if !strings.Contains(string(text), want) {
+			t.Errorf("decoded xml lacks %s:\n%s", want, text)
+		}
+	}
+
+	af, err := ParseAppFilterDocument(bytes.NewReader(text))
+	if err != nil {
+		t.Fatalf("parse decoded xml: %v\n%s", err, text)
+	}
+	if len(af.Items) != 2 || af.Items[0].PackageName != "com.café" || af.Items[1].Component != long {
+		t.Errorf("unexpected items: %+v", af.Items)
+	}
+	if af.Scale != 0.85 || len(af.IconBack) != 1 || af.IconBack[0] != "iconback" {
+		t.Errorf("unexpected pack elements: %+v", af)
+	}
+}
+
+func TestDecodeUTF8PoolString(t *testing.T) {
+	long := strings.Repeat("é", 100) // 100 UTF-16 units, 200 bytes
+	entry := append([]byte{100, 0x80, 200}, long...)
+	got, err := decodeUTF8PoolString(append(entry, 0))
+	if err != nil || got != long {
+		t.Fatalf("decodeUTF8PoolString() = %q, %v", got, err)
+	}
+	if _, err := decodeUTF8PoolString(entry[:50]); err == nil {
+		t.Error("expected truncated string error")
+	}
+}
+
+func TestParseArchive(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string][]byte{
+		"assets/appfilter.xml": []byte(`<resources><!-- A --><item component="ComponentInfo{com.a/com.a.Main}" drawable="a"/></resources>`),
+		"res/xml/appfilter.xml": encodeBinaryXML(axmlNode{name: "resources", children: []axmlNode{
+			{name: "item", attrs: [][2]string{{"component", "ComponentInfo{com.ignored/.Main}"}, {"drawable", "ignored"}}},
+		}}),
+		"res/xml/drawable.xml": encodeBinaryXML(axmlNode{name: "resources", children: []axmlNode{
+			{name: "category", attrs: [][2]string{{"title", "All"}}},
+			{name: "item", attrs: [][2]string{{"drawable", "a"}}},
+		}}),
+		"classes.dex": []byte("dex\n035"),
+	}
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !IsArchive(buf.Bytes()) {
+		t.Fatal("fixture not recognised as archive")
+	}
+
+	r := bytes.NewReader(buf.Bytes())
+	res, err := ParseArchive(r, int64(r.Len()))
+	if err != nil {
+		t.Fatalf("parse archive: %v", err)
+	}
+
+	if res.Sources["appfilter"] != "assets/appfilter.xml" {
+		t.Errorf("expected assets appfilter to be preferred, got %q", res.Sources["appfilter"])
+	}
+	if len(res.AppFilter) != 1 || res.AppFilter[0].AppName != "A" {
+		t.Errorf("unexpected appfilter items: %+v", res.AppFilter)
+	}
+	if res.Drawable == nil || len(res.Drawable.Categories) != 1 || res.Drawable.Categories[0].Title != "All" {
+		t.Errorf("unexpected drawable: %+v", res.Drawable)
+	}
+	if res.AppMap != nil {
+		t.Errorf("expected no appmap, got %+v", res.AppMap)
+	}
+
+	items, err := ParseAppFilterFromArchive(r, int64(r.Len()))
+	if err != nil {
+		t.Fatalf("parse appfilter from archive: %v", err)
+	}
+	if len(items) != 1 {
+		t.Errorf("expected 1 item, got %d", len(items))
+	}
+}
+
+func TestParseArchiveWithoutPackFiles(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("README.md")
+	_, _ = w.Write([]byte("nothing here"))
+	_ = zw.Close()
+
+	r := bytes.NewReader(buf.Bytes())
+	if _, err := ParseArchive(r, int64(r.Len())); err == nil || !strings.Contains(err.Error(), "no appfilter.xml") {
+		t.Fatalf("expected missing files error, got %v", err)
+	}
+}
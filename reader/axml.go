@@ -0,0 +1,253 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Chunk types of the Android binary XML (AXML) format produced by aapt/aapt2
+// for files under res/xml inside an APK.
+const (
+	axmlStringPoolType   = 0x0001
+	axmlFileType         = 0x0003
+	axmlStartNamespace   = 0x0100
+	axmlEndNamespace     = 0x0101
+	axmlStartElementType = 0x0102
+	axmlEndElementType   = 0x0103
+	axmlCDataType        = 0x0104
+	axmlResourceMapType  = 0x0180
+
+	axmlUTF8Flag = 1 << 8
+	axmlNoIndex  = 0xFFFFFFFF
+)
+
+// Res_value data types used when rendering typed attribute values.
+const (
+	axmlTypeReference = 0x01
+	axmlTypeAttribute = 0x02
+	axmlTypeString    = 0x03
+	axmlTypeFloat     = 0x04
+	axmlTypeIntDec    = 0x10
+	axmlTypeIntHex    = 0x11
+	axmlTypeBoolean   = 0x12
+)
+
+// IsBinaryXML reports whether data starts with the AXML file header.
+func IsBinaryXML(data []byte) bool {
+	return len(data) >= 8 && binary.LittleEndian.Uint16(data[0:2]) == axmlFileType
+}
+
+// DecodeBinaryXML converts a compiled Android binary XML document into its
+// textual form so it can be fed to the regular parsers. Namespaces are
+// dropped (icon pack files do not use them) and comments, which aapt strips
+// at compile time, cannot be recovered.
+func DecodeBinaryXML(data []byte) ([]byte, error) {
+	if !IsBinaryXML(data) {
+		return nil, fmt.Errorf("not a binary xml document")
+	}
+	headerSize := int(binary.LittleEndian.Uint16(data[2:4]))
+	total := int(binary.LittleEndian.Uint32(data[4:8]))
+	if total > len(data) || headerSize < 8 || headerSize > total {
+		return nil, fmt.Errorf("corrupt binary xml header")
+	}
+
+	var pool []string
+	str := func(idx uint32) string {
+		if idx == axmlNoIndex || int(idx) >= len(pool) {
+			return ""
+		}
+		return pool[idx]
+	}
+
+	var out bytes.Buffer
+	out.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+
+	for off := headerSize; off+8 <= total; {
+		chunkType := binary.LittleEndian.Uint16(data[off : off+2])
+		chunkHeader := int(binary.LittleEndian.Uint16(data[off+2 : off+4]))
+		chunkSize := int(binary.LittleEndian.Uint32(data[off+4 : off+8]))
+		if chunkSize < 8 || off+chunkSize > total {
+			return nil, fmt.Errorf("corrupt chunk at offset %d", off)
+		}
+		chunk := data[off : off+chunkSize]
+
+		switch chunkType {
+		case axmlStringPoolType:
+			p, err := decodeStringPool(chunk)
+			if err != nil {
+				return nil, err
+			}
+			pool = p
+		case axmlStartElementType:
+			if chunkHeader+20 > len(chunk) {
+				return nil, fmt.Errorf("corrupt start element at offset %d", off)
+			}
+			ext := chunk[chunkHeader:]
+			name := str(binary.LittleEndian.Uint32(ext[4:8]))
+			attrStart := int(binary.LittleEndian.Uint16(ext[8:10]))
+			attrSize := int(binary.LittleEndian.Uint16(ext[10:12]))
+			attrCount := int(binary.LittleEndian.Uint16(ext[12:14]))
+
+			out.WriteString("<" + name)
+			for i := 0; i < attrCount; i++ {
+				a := attrStart + i*attrSize
+				if a+20 > len(ext) {
+					return nil, fmt.Errorf("corrupt attribute in <%s>", name)
+				}
+				attrName := str(binary.LittleEndian.Uint32(ext[a+4 : a+8]))
+				raw := binary.LittleEndian.Uint32(ext[a+8 : a+12])
+				dataType := ext[a+15]
+				value := binary.LittleEndian.Uint32(ext[a+16 : a+20])
+
+				var v string
+				if raw != axmlNoIndex {
+					v = str(raw)
+				} else {
+					v = formatTypedValue(dataType, value, str)
+				}
+				out.WriteString(" " + attrName + `="`)
+				if err := xml.EscapeText(&out, []byte(v)); err != nil {
+					return nil, err
+				}
+				out.WriteString(`"`)
+			}
+			out.WriteString(">")
+		case axmlEndElementType:
+			if chunkHeader+8 > len(chunk) {
+				return nil, fmt.Errorf("corrupt end element at offset %d", off)
+			}
+			ext := chunk[chunkHeader:]
+			out.WriteString("</" + str(binary.LittleEndian.Uint32(ext[4:8])) + ">")
+		case axmlCDataType:
+			if chunkHeader+4 > len(chunk) {
+				return nil, fmt.Errorf("corrupt cdata at offset %d", off)
+			}
+			ext := chunk[chunkHeader:]
+			if err := xml.EscapeText(&out, []byte(str(binary.LittleEndian.Uint32(ext[0:4])))); err != nil {
+				return nil, err
+			}
+		case axmlStartNamespace, axmlEndNamespace, axmlResourceMapType:
+			// Not needed to reconstruct icon pack documents.
+		}
+
+		off += chunkSize
+	}
+
+	return out.Bytes(), nil
+}
+
+// decodeStringPool parses a ResStringPool chunk into a slice of strings.
+func decodeStringPool(chunk []byte) ([]string, error) {
+	if len(chunk) < 28 {
+		return nil, fmt.Errorf("corrupt string pool")
+	}
+	headerSize := int(binary.LittleEndian.Uint16(chunk[2:4]))
+	count := int(binary.LittleEndian.Uint32(chunk[8:12]))
+	flags := binary.LittleEndian.Uint32(chunk[16:20])
+	stringsStart := int(binary.LittleEndian.Uint32(chunk[20:24]))
+	if headerSize+count*4 > len(chunk) || stringsStart > len(chunk) {
+		return nil, fmt.Errorf("corrupt string pool")
+	}
+	utf8Pool := flags&axmlUTF8Flag != 0
+
+	pool := make([]string, count)
+	for i := 0; i < count; i++ {
+		o := stringsStart + int(binary.LittleEndian.Uint32(chunk[headerSize+i*4:]))
+		if o >= len(chunk) {
+			return nil, fmt.Errorf("string %d out of range", i)
+		}
+		var s string
+		var err error
+		if utf8Pool {
+			s, err = decodeUTF8PoolString(chunk[o:])
+		} else {
+			s, err = decodeUTF16PoolString(chunk[o:])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("string %d: %w", i, err)
+		}
+		pool[i] = s
+	}
+	return pool, nil
+}
+
+// decodeUTF8PoolString reads one length-prefixed UTF-8 pool entry. The entry
+// stores the UTF-16 length followed by the UTF-8 byte length, each encoded in
+// one or two bytes.
+func decodeUTF8PoolString(b []byte) (string, error) {
+	readLen := func(b []byte) (int, int, error) {
+		if len(b) < 1 {
+			return 0, 0, fmt.Errorf("truncated length")
+		}
+		if b[0]&0x80 == 0 {
+			return int(b[0]), 1, nil
+		}
+		if len(b) < 2 {
+			return 0, 0, fmt.Errorf("truncated length")
+		}
+		return int(b[0]&0x7F)<<8 | int(b[1]), 2, nil
+	}
+	_, n1, err := readLen(b)
+	if err != nil {
+		return "", err
+	}
+	size, n2, err := readLen(b[n1:])
+	if err != nil {
+		return "", err
+	}
+	start := n1 + n2
+	if start+size > len(b) {
+		return "", fmt.Errorf("truncated string")
+	}
+	return string(b[start : start+size]), nil
+}
+
+// decodeUTF16PoolString reads one length-prefixed UTF-16LE pool entry.
+func decodeUTF16PoolString(b []byte) (string, error) {
+	if len(b) < 2 {
+		return "", fmt.Errorf("truncated length")
+	}
+	size := int(binary.LittleEndian.Uint16(b))
+	start := 2
+	if size&0x8000 != 0 {
+		if len(b) < 4 {
+			return "", fmt.Errorf("truncated length")
+		}
+		size = (size&0x7FFF)<<16 | int(binary.LittleEndian.Uint16(b[2:]))
+		start = 4
+	}
+	if start+size*2 > len(b) {
+		return "", fmt.Errorf("truncated string")
+	}
+	units := make([]uint16, size)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[start+i*2:])
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// formatTypedValue renders a Res_value the way aapt dump does for the types
+// that can appear in icon pack XML files.
+func formatTypedValue(dataType byte, value uint32, str func(uint32) string) string {
+	switch dataType {
+	case axmlTypeString:
+		return str(value)
+	case axmlTypeReference:
+		return fmt.Sprintf("@0x%08x", value)
+	case axmlTypeAttribute:
+		return fmt.Sprintf("?0x%08x", value)
+	case axmlTypeFloat:
+		return strconv.FormatFloat(float64(math.Float32frombits(value)), 'g', -1, 32)
+	case axmlTypeIntHex:
+		return fmt.Sprintf("0x%x", value)
+	case axmlTypeBoolean:
+		return strconv.FormatBool(value != 0)
+	default:
+		return strconv.FormatInt(int64(int32(value)), 10)
+	}
+}
@@ -0,0 +1,101 @@
+package reader
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"circle-center/globals"
+)
+
+// DefaultComponentLimit is the number of distinct components an index holds
+// when NewComponentIndex is given no limit.
+const DefaultComponentLimit = 100000
+
+// ComponentIndex records which launcher activities have been seen for each
+// package. Callers build one from sources they trust (stored project icons,
+// request items, an appfilter they own) and pass it to whatever resolves
+// activities. It is safe for concurrent use.
+type ComponentIndex struct {
+	mu       sync.RWMutex
+	packages map[string]map[string]int // package -> activity -> times seen
+	size     int
+	limit    int
+}
+
+// NewComponentIndex returns an empty index holding at most limit distinct
+// components; once full, new components are ignored while known ones are
+// still counted. A limit <= 0 means DefaultComponentLimit.
+func NewComponentIndex(limit int) *ComponentIndex {
+	if limit <= 0 {
+		limit = DefaultComponentLimit
+	}
+	return &ComponentIndex{packages: make(map[string]map[string]int), limit: limit}
+}
+
+// Add records a component. Both the appfilter form ("ComponentInfo{pkg/act}")
+// and the bare "pkg/act" form are accepted; relative activities are expanded
+// and placeholder activities such as "TODO" are ignored.
+func (x *ComponentIndex) Add(component string) {
+	component = strings.TrimSpace(component)
+	if !strings.HasPrefix(strings.ToLower(component), "componentinfo{") {
+		component = "ComponentInfo{" + component + "}"
+	}
+
+	pkg, act := ParseComponentInfo(NormalizeComponent(component))
+	if pkg == "" || act == "" || strings.EqualFold(act, "TODO") {
+		return
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	acts := x.packages[pkg]
+	if _, known := acts[act]; !known {
+		if x.size >= x.limit {
+			return
+		}
+		if acts == nil {
+			acts = make(map[string]int)
+			x.packages[pkg] = acts
+		}
+		x.size++
+	}
+	acts[act]++
+}
+
+// AddItems records the components of all items.
+func (x *ComponentIndex) AddItems(items []globals.Item) {
+	for _, item := range items {
+		x.Add(item.Component)
+	}
+}
+
+// Activities returns the fully-qualified activities known for pkg, most
+// frequently seen first. It returns nil when nothing is known.
+func (x *ComponentIndex) Activities(pkg string) []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	acts := x.packages[pkg]
+	if len(acts) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(acts))
+	for act := range acts {
+		out = append(out, act)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if acts[out[i]] != acts[out[j]] {
+			return acts[out[i]] > acts[out[j]]
+		}
+		return out[i] < out[j]
+	})
+	return out
+}
+
+// Len returns the number of packages in the index.
+func (x *ComponentIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.packages)
+}
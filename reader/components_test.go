@@ -0,0 +1,41 @@
+package reader
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComponentIndex(t *testing.T) {
+	x := NewComponentIndex(0)
+	x.Add("ComponentInfo{com.example/com.example.Main}")
+	x.Add("componentinfo{com.example/.Main}")
+	x.Add("com.example/com.example.Alias")
+	x.Add("ComponentInfo{com.example/TODO}")
+	x.Add("ComponentInfo{com.other}")
+	x.Add("")
+
+	if got, want := x.Activities("com.example"), []string{"com.example.Main", "com.example.Alias"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Activities = %v, want %v", got, want)
+	}
+	if got := x.Activities("com.other"); got != nil {
+		t.Errorf("expected no activities for com.other, got %v", got)
+	}
+	if x.Len() != 1 {
+		t.Errorf("Len = %d, want 1", x.Len())
+	}
+}
+
+func TestComponentIndexLimit(t *testing.T) {
+	x := NewComponentIndex(2)
+	x.Add("com.a/com.a.Main")
+	x.Add("com.b/com.b.Main")
+	x.Add("com.c/com.c.Main")
+	x.Add("com.a/com.a.Main")
+
+	if x.Len() != 2 || x.Activities("com.c") != nil {
+		t.Errorf("index grew past its limit: %d packages", x.Len())
+	}
+	if got := x.Activities("com.a"); len(got) != 1 {
+		t.Errorf("known components should still be counted, got %v", got)
+	}
+}
@@ -0,0 +1,102 @@
+package reader
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInconclusiveType is returned by DetectFileType for a <resources>
+// document without any children that identify it, such as an empty
+// appfilter or one holding only comments. Callers may fall back to a type
+// they already know.
+var ErrInconclusiveType = errors.New("unable to detect xml type: <resources> has no recognizable children")
+
+// FileType identifies which kind of icon pack XML a document is.
+type FileType string
+
+const (
+	FileTypeUnknown   FileType = ""
+	FileTypeAppFilter FileType = "appfilter"
+	FileTypeIconPack  FileType = "icon_pack"
+	FileTypeDrawable  FileType = "drawable"
+	FileTypeAppMap    FileType = "appmap"
+	FileTypeTheme     FileType = "theme_resources"
+	FileTypeArchive   FileType = "archive"
+)
+
+// DetectFileType sniffs the root element and the direct children of an XML
+// document and reports which icon pack file it is:
+//   - <appmap> root: appmap.xml
+//   - <Theme> root: theme_resources.xml
+//   - <resources> with <string-array>: icon_pack.xml
+//   - <resources> with <category> or <item drawable> only: drawable.xml
+//   - <resources> with <item component> or pack-level elements: appfilter.xml
+//
+// Only as much of the input as needed to decide is consumed. A <resources>
+// document without recognizable children yields ErrInconclusiveType.
+func DetectFileType(r io.Reader) (FileType, error) {
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = CharsetReader
+
+	depth := 0
+	root := ""
+	sawDrawableItem := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return FileTypeUnknown, fmt.Errorf("failed to read xml: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				root = t.Name.Local
+				switch root {
+				case "appmap":
+					return FileTypeAppMap, nil
+				case "Theme":
+					return FileTypeTheme, nil
+				case "resources":
+					continue
+				default:
+					return FileTypeUnknown, fmt.Errorf("unsupported root element <%s>", root)
+				}
+			}
+			if depth != 2 {
+				continue
+			}
+
+			switch t.Name.Local {
+			case "string-array":
+				return FileTypeIconPack, nil
+			case "category":
+				return FileTypeDrawable, nil
+			case "iconback", "iconmask", "iconupon", "scale", "calendar", "dynamic-clock":
+				return FileTypeAppFilter, nil
+			case "item":
+				if attrValue(t.Attr, "component") != "" {
+					return FileTypeAppFilter, nil
+				}
+				if attrValue(t.Attr, "drawable") != "" {
+					sawDrawableItem = true
+				}
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	if root == "" {
+		return FileTypeUnknown, fmt.Errorf("missing root element")
+	}
+	if sawDrawableItem {
+		return FileTypeDrawable, nil
+	}
+	return FileTypeUnknown, ErrInconclusiveType
+}
@@ -0,0 +1,73 @@
+package reader
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetectFileType(t *testing.T) {
+	tests := []struct {
+		name    string
+		xml     string
+		want    FileType
+		wantErr bool
+	}{
+		{name: "appfilter items", xml: `<resources><!-- A --><item component="ComponentInfo{a/.A}" drawable="a"/></resources>`, want: FileTypeAppFilter},
+		{name: "appfilter header", xml: `<resources><iconback img1="back"/></resources>`, want: FileTypeAppFilter},
+		{name: "icon pack", xml: `<resources><string-array name="icon_pack"><item>a</item></string-array></resources>`, want: FileTypeIconPack},
+		{name: "drawable categories", xml: `<resources><version>1</version><category title="All"/><item drawable="a"/></resources>`, want: FileTypeDrawable},
+		{name: "drawable items only", xml: `<resources><item drawable="a"/></resources>`, want: FileTypeDrawable},
+		{name: "appmap", xml: `<appmap><item class=".Main" name="a"/></appmap>`, want: FileTypeAppMap},
+		{name: "theme", xml: `<Theme version="1"><AppIcon name="a/.A" image="a"/></Theme>`, want: FileTypeTheme},
+		{name: "empty resources", xml: `<resources/>`, wantErr: true},
+		{name: "comments only", xml: `<resources><!-- nothing yet --></resources>`, wantErr: true},
+		{name: "unknown root", xml: `<manifest/>`, wantErr: true},
+		{name: "not xml", xml: `hello`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectFileType(strings.NewReader(tt.xml))
+			if tt.name == "empty resources" && !errors.Is(err, ErrInconclusiveType) {
+				t.Fatalf("DetectFileType() error = %v, want ErrInconclusiveType", err)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectFileType() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DetectFileType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAppMapAndTheme(t *testing.T) {
+	am, err := ParseAppMapFromReader(strings.NewReader(`<appmap>
+    <!-- Browser -->
+    <item class=".BrowserActivity" name="browser"/>
+    <item class="com.example.Main" name="example"/>
+</appmap>`))
+	if err != nil {
+		t.Fatalf("parse appmap: %v", err)
+	}
+	if len(am.Items) != 2 || am.Items[0].AppName != "Browser" || am.Items[0].Drawable != "browser" || am.Items[1].AppName != "" {
+		t.Errorf("unexpected appmap items: %+v", am.Items)
+	}
+
+	th, err := ParseThemeFromReader(strings.NewReader(`<Theme version="1">
+    <Label value="My Pack"/>
+    <!-- Browser -->
+    <AppIcon name="com.android.browser/.BrowserActivity" image="browser"/>
+</Theme>`))
+	if err != nil {
+		t.Fatalf("parse theme: %v", err)
+	}
+	if th.Label != "My Pack" || len(th.Icons) != 1 {
+		t.Fatalf("unexpected theme: %+v", th)
+	}
+	icon := th.Icons[0]
+	if icon.PackageName != "com.android.browser" || icon.ActivityName != ".BrowserActivity" || icon.AppName != "Browser" {
+		t.Errorf("unexpected theme icon: %+v", icon)
+	}
+}
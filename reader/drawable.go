@@ -0,0 +1,67 @@
+package reader
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/beevik/etree"
+
+	"circle-center/globals"
+)
+
+// ParseDrawableFromReader parses a drawable.xml file from the provided
+// io.Reader. Each <category title> starts a new category and every following
+// <item drawable> is appended to it until the next category.
+func ParseDrawableFromReader(r io.Reader) (globals.DrawableResources, error) {
+	doc := etree.NewDocument()
+	if _, err := doc.ReadFrom(r); err != nil {
+		return globals.DrawableResources{}, fmt.Errorf("failed to read xml: %w", err)
+	}
+
+	root := doc.SelectElement("resources")
+	if root == nil {
+		return globals.DrawableResources{}, fmt.Errorf("missing <resources> root element")
+	}
+
+	var res globals.DrawableResources
+	current := -1
+	for _, el := range root.ChildElements() {
+		switch el.Tag {
+		case "version":
+			res.Version = strings.TrimSpace(el.Text())
+		case "category":
+			res.Categories = append(res.Categories, globals.DrawableCategory{
+				Title: strings.TrimSpace(el.SelectAttrValue("title", "")),
+			})
+			current = len(res.Categories) - 1
+		case "item":
+			drawable := strings.TrimSpace(el.SelectAttrValue("drawable", ""))
+			if drawable == "" {
+				continue
+			}
+			// Items before the first category go into an untitled one so
+			// that nothing is silently dropped.
+			if current < 0 {
+				res.Categories = append(res.Categories, globals.DrawableCategory{})
+				current = len(res.Categories) - 1
+			}
+			res.Categories[current].Drawables = append(res.Categories[current].Drawables, drawable)
+		}
+	}
+
+	return res, nil
+}
+
+// ParseDrawableFile opens and parses a drawable.xml file from the given path.
+// It wraps ParseDrawableFromReader for convenience.
+func ParseDrawableFile(path string) (globals.DrawableResources, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return globals.DrawableResources{}, fmt.Errorf("cannot open file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return ParseDrawableFromReader(f)
+}
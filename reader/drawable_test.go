@@ -0,0 +1,54 @@
+package reader
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDrawableFromReader(t *testing.T) {
+	const xmlStr = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <version>1</version>
+    <item drawable="orphan"/>
+
+    <category title="New"/>
+    <item drawable="browser"/>
+    <item drawable="camera"/>
+
+    <category title="System"/>
+    <item drawable="settings"/>
+    <item drawable=""/>
+</resources>`
+
+	res, err := ParseDrawableFromReader(strings.NewReader(xmlStr))
+	if err != nil {
+		t.Fatalf("parse drawable: %v", err)
+	}
+
+	if res.Version != "1" {
+		t.Errorf("expected version 1, got %q", res.Version)
+	}
+	if len(res.Categories) != 3 {
+		t.Fatalf("expected 3 categories, got %d", len(res.Categories))
+	}
+
+	wantTitles := []string{"", "New", "System"}
+	wantCounts := []int{1, 2, 1}
+	for i, cat := range res.Categories {
+		if cat.Title != wantTitles[i] {
+			t.Errorf("category %d: expected title %q, got %q", i, wantTitles[i], cat.Title)
+		}
+		if len(cat.Drawables) != wantCounts[i] {
+			t.Errorf("category %d: expected %d drawables, got %d", i, wantCounts[i], len(cat.Drawables))
+		}
+	}
+	if res.Categories[1].Drawables[1] != "camera" {
+		t.Errorf("expected camera as second New drawable, got %q", res.Categories[1].Drawables[1])
+	}
+}
+
+func TestParseDrawableFromReaderMissingRoot(t *testing.T) {
+	if _, err := ParseDrawableFromReader(strings.NewReader(`<appmap/>`)); err == nil {
+		t.Fatal("expected error for missing resources root")
+	}
+}
@@ -1,40 +1,100 @@
 package reader
 
 import (
+	"bytes"
+	"encoding/xml"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"circle-center/globals"
 )
 
+// rasterFormats lists the image extensions accepted anywhere in the scanned
+// tree. VectorDrawable .xml files are only accepted inside drawable/mipmap
+// folders, since XML in the root is usually appfilter.xml and friends.
+var rasterFormats = map[string]string{
+	".png":  "png",
+	".webp": "webp",
+	".jpg":  "jpg",
+	".jpeg": "jpg",
+}
+
+// densityRanks orders density qualifiers from least to most preferred.
+// nodpi and anydpi outrank every bucket because they hold the original
+// artwork or a scalable vector.
+var densityRanks = map[string]int{
+	"ldpi":    120,
+	"mdpi":    160,
+	"tvdpi":   213,
+	"hdpi":    240,
+	"xhdpi":   320,
+	"xxhdpi":  480,
+	"xxxhdpi": 640,
+	"nodpi":   10000,
+	"anydpi":  10001,
+}
+
 // ParseIconDirectory walks the provided directory and collects information
-// about PNG files. Each PNG file is mapped into a globals.LocalIcon structure
-// where PackageName is the file name without the .png extension.
+// about drawables. Image files in the root directory are always considered;
+// beyond that, any drawable* or mipmap* folder in the tree (for example
+// res/drawable-nodpi or res/drawable-xxxhdpi) is scanned as well. PNG, WebP
+// and JPEG images are accepted everywhere and VectorDrawable XML (a <vector>
+// root, plain or compiled) inside drawable/mipmap folders. Files sharing a
+// base name are merged into a single globals.LocalIcon whose Variants list
+// every density and format found.
 func ParseIconDirectory(dir string) ([]globals.LocalIcon, error) {
-	var icons []globals.LocalIcon
+	var order []string
+	byName := make(map[string]*globals.LocalIcon)
 
 	walkFn := func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		if d.IsDir() {
-			// Skip nested directories; only consider files in root dir.
-			if path != dir {
-				return filepath.SkipDir
-			}
 			return nil
 		}
 
-		if strings.HasSuffix(strings.ToLower(d.Name()), ".png") {
-			base := strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))
-			icons = append(icons, globals.LocalIcon{
-				FileName:    d.Name(),
-				PackageName: base,
-				FilePath:    path,
-			})
+		parent := filepath.Dir(path)
+		inRoot := parent == filepath.Clean(dir)
+		_, qualifier, isResDir := splitResDirName(filepath.Base(parent))
+		if !inRoot && !isResDir {
+			return nil
+		}
+
+		ext := strings.ToLower(filepath.Ext(d.Name()))
+		format, ok := rasterFormats[ext]
+		if !ok {
+			if ext != ".xml" || !isResDir {
+				return nil
+			}
+			// Selectors, layer-lists, adaptive icons and bitmap XML live in
+			// the same folders but are not artwork.
+			if root, err := xmlRootElement(path); err != nil || root != "vector" {
+				return nil
+			}
+			format = "xml"
+		}
+
+		base := strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))
+		variant := globals.DrawableVariant{
+			Qualifier: qualifier,
+			Density:   densityQualifier(qualifier),
+			Format:    format,
+			FilePath:  path,
 		}
+
+		icon, exists := byName[base]
+		if !exists {
+			icon = &globals.LocalIcon{PackageName: base}
+			byName[base] = icon
+			order = append(order, base)
+		}
+		icon.Variants = append(icon.Variants, variant)
 		return nil
 	}
 
@@ -42,5 +102,85 @@ func ParseIconDirectory(dir string) ([]globals.LocalIcon, error) {
 		return nil, fmt.Errorf("error scanning directory %s: %w", dir, err)
 	}
 
+	icons := make([]globals.LocalIcon, 0, len(order))
+	for _, name := range order {
+		icon := byName[name]
+		sort.SliceStable(icon.Variants, func(i, j int) bool {
+			return densityRank(icon.Variants[i].Density) > densityRank(icon.Variants[j].Density)
+		})
+		best := icon.Variants[0]
+		icon.FilePath = best.FilePath
+		icon.FileName = filepath.Base(best.FilePath)
+		icons = append(icons, *icon)
+	}
+
 	return icons, nil
 }
+
+// xmlRootElement returns the local name of the root element of the XML file
+// at path. Compiled binary XML, as found in unpacked APKs, is decoded first.
+func xmlRootElement(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if IsBinaryXML(data) {
+		if data, err = DecodeBinaryXML(data); err != nil {
+			return "", err
+		}
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = CharsetReader
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// splitResDirName splits an Android resource directory name such as
+// "drawable-xxxhdpi-v4" into its type ("drawable") and qualifier string
+// ("xxxhdpi-v4"). ok is false for directories that are not drawable or
+// mipmap resource folders.
+func splitResDirName(name string) (resType, qualifier string, ok bool) {
+	resType, qualifier, _ = strings.Cut(name, "-")
+	if resType != "drawable" && resType != "mipmap" {
+		return "", "", false
+	}
+	return resType, qualifier, true
+}
+
+// densityQualifier extracts the density part of a qualifier string, e.g.
+// "xxhdpi" from "night-xxhdpi-v26" or "420dpi" from "420dpi".
+func densityQualifier(qualifier string) string {
+	for _, q := range strings.Split(qualifier, "-") {
+		if _, ok := densityRanks[q]; ok {
+			return q
+		}
+		if n, ok := strings.CutSuffix(q, "dpi"); ok {
+			if _, err := strconv.Atoi(n); err == nil {
+				return q
+			}
+		}
+	}
+	return ""
+}
+
+// densityRank returns the preference rank of a density qualifier. Numeric
+// densities (e.g. "420dpi") rank by their value; no qualifier ranks lowest.
+func densityRank(density string) int {
+	if r, ok := densityRanks[density]; ok {
+		return r
+	}
+	if n, ok := strings.CutSuffix(density, "dpi"); ok {
+		if v, err := strconv.Atoi(n); err == nil {
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package reader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIconDirectoryResTree(t *testing.T) {
+	dir := t.TempDir()
+	const vector = `<?xml version="1.0" encoding="utf-8"?>
+<vector xmlns:android="http://schemas.android.com/apk/res/android" android:width="24dp"/>`
+	files := map[string]string{
+		"flat_icon.png":                         "x",
+		"appfilter.xml":                         "<resources/>",
+		"renamed/flat_icon.png":                 "x",
+		"res/drawable-xxhdpi/browser.png":       "x",
+		"res/drawable-xxxhdpi-v4/browser.webp":  "x",
+		"res/drawable-nodpi/camera.png":         "x",
+		"res/drawable-anydpi-v26/camera.xml":    vector,
+		"res/drawable/clock.xml":                vector,
+		"res/drawable/button_bg.xml":            `<selector><item android:drawable="@drawable/clock"/></selector>`,
+		"res/drawable/layers.xml":               `<layer-list/>`,
+		"res/mipmap-anydpi-v26/ic_launcher.xml": `<adaptive-icon/>`,
+		"res/drawable/broken.xml":               "x",
+		"res/values/icon_pack.xml":              "<resources/>",
+	}
+	for f, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	icons, err := ParseIconDirectory(dir)
+	if err != nil {
+		t.Fatalf("parse icon directory: %v", err)
+	}
+
+	byName := make(map[string]int)
+	for i, ic := range icons {
+		byName[ic.PackageName] = i
+	}
+	for _, skipped := range []string{"button_bg", "layers", "ic_launcher", "broken"} {
+		if _, ok := byName[skipped]; ok {
+			t.Errorf("non-vector xml %s reported as a drawable", skipped)
+		}
+	}
+	if len(icons) != 4 {
+		t.Fatalf("expected 4 drawables, got %d: %+v", len(icons), icons)
+	}
+
+	flat := icons[byName["flat_icon"]]
+	if len(flat.Variants) != 1 || flat.Variants[0].Qualifier != "" || flat.FileName != "flat_icon.png" {
+		t.Errorf("unexpected flat icon: %+v", flat)
+	}
+
+	browser := icons[byName["browser"]]
+	if len(browser.Variants) != 2 {
+		t.Fatalf("expected 2 browser variants, got %+v", browser.Variants)
+	}
+	if browser.Variants[0].Density != "xxxhdpi" || browser.Variants[0].Format != "webp" || browser.Variants[0].Qualifier != "xxxhdpi-v4" {
+		t.Errorf("expected xxxhdpi webp as preferred variant, got %+v", browser.Variants[0])
+	}
+	if browser.FileName != "browser.webp" {
+		t.Errorf("expected preferred file browser.webp, got %s", browser.FileName)
+	}
+
+	camera := icons[byName["camera"]]
+	if camera.Variants[0].Format != "xml" || camera.Variants[0].Density != "anydpi" {
+		t.Errorf("expected anydpi vector first, got %+v", camera.Variants)
+	}
+
+	if clock := icons[byName["clock"]]; clock.Variants[0].Format != "xml" {
+		t.Errorf("expected vector clock drawable, got %+v", clock)
+	}
+}
@@ -11,10 +11,10 @@ import (
 	"circle-center/globals"
 )
 
-// parseIconPackFromReader parses an icon_pack.xml file using the provided io.Reader.
+// ParseIconPackFromReader parses an icon_pack.xml file using the provided io.Reader.
 // It returns a fully populated IconPackResources structure that contains all
 // <string-array> definitions.
-func parseIconPackFromReader(r io.Reader) (globals.IconPackResources, error) {
+func ParseIconPackFromReader(r io.Reader) (globals.IconPackResources, error) {
 	doc := etree.NewDocument()
 	if _, err := doc.ReadFrom(r); err != nil {
 		return globals.IconPackResources{}, fmt.Errorf("failed to read xml: %w", err)
@@ -43,7 +43,7 @@ func parseIconPackFromReader(r io.Reader) (globals.IconPackResources, error) {
 }
 
 // ParseIconPackFile opens and parses an icon_pack.xml file from the given path.
-// It wraps parseIconPackFromReader for convenience.
+// It wraps ParseIconPackFromReader for convenience.
 func ParseIconPackFile(path string) (globals.IconPackResources, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -51,5 +51,5 @@ func ParseIconPackFile(path string) (globals.IconPackResources, error) {
 	}
 	defer f.Close()
 
-	return parseIconPackFromReader(f)
+	return ParseIconPackFromReader(f)
 }
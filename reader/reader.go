@@ -2,6 +2,9 @@ package reader
 
 import (
 	"circle-center/globals"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,19 +16,49 @@ func ReadAppFilter(path string) ([]globals.Item, error) {
 	return ParseAppFilterFile(path)
 }
 
+// ReadAppFilterDocument parses an appfilter.xml file into the full
+// globals.AppFilter model, keeping pack-level elements alongside the items.
+func ReadAppFilterDocument(path string) (globals.AppFilter, error) {
+	return ParseAppFilterDocumentFile(path)
+}
+
 // ReadIconPack provides a public API to parse icon_pack.xml files.
 func ReadIconPack(path string) (globals.IconPackResources, error) {
 	return ParseIconPackFile(path)
 }
 
-// ReadLocalIcons parses a directory containing PNG icon files and returns the
-// slice of globals.LocalIcon structures.
+// ReadDrawable provides a public API to parse drawable.xml files.
+func ReadDrawable(path string) (globals.DrawableResources, error) {
+	return ParseDrawableFile(path)
+}
+
+// ReadAppMap provides a public API to parse appmap.xml files.
+func ReadAppMap(path string) (globals.AppMapResources, error) {
+	return ParseAppMapFile(path)
+}
+
+// ReadTheme provides a public API to parse theme_resources.xml files.
+func ReadTheme(path string) (globals.ThemeResources, error) {
+	return ParseThemeFile(path)
+}
+
+// ReadArchive parses the icon pack files contained in an APK or ZIP archive.
+func ReadArchive(path string) (globals.ArchiveResources, error) {
+	return ParseArchiveFile(path)
+}
+
+// ReadLocalIcons parses a directory of icon files or an Android res tree and
+// returns the slice of globals.LocalIcon structures, one per drawable name.
 func ReadLocalIcons(dir string) ([]globals.LocalIcon, error) {
 	return ParseIconDirectory(dir)
 }
 
-// reader handles POST /readfile which accepts a form-data file field named "file" containing an appfilter.xml.
-// It parses the XML and returns the parsed items in JSON format.
+// reader handles POST /readfile which accepts a form-data file field named
+// "file" containing one of the supported icon pack XML files, or an APK/ZIP
+// archive holding them. The file type is detected from the content; an
+// optional "type" field may be sent and is checked against the detected type;
+// it also decides how an empty <resources> document is read.
+// The response carries the detected type alongside the parsed content.
 func reader(c *gin.Context) {
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
@@ -40,24 +73,94 @@ func reader(c *gin.Context) {
 	}
 	defer f.Close()
 
-	fileType := c.PostForm("type")
+	magic := make([]byte, 4)
+	n, _ := io.ReadFull(f, magic)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot rewind uploaded file: " + err.Error()})
+		return
+	}
+	if IsArchive(magic[:n]) {
+		readArchiveUpload(c, f, fileHeader.Size)
+		return
+	}
 
-	switch fileType {
-	case "icon_pack":
-		res, err := parseIconPackFromReader(f)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
+	declared := FileType(c.PostForm("type"))
+	detected, err := DetectFileType(f)
+	switch {
+	case errors.Is(err, ErrInconclusiveType):
+		// An empty <resources> is valid for every resources-rooted file;
+		// trust the declared type and default to appfilter.
+		detected = FileTypeAppFilter
+		if declared == FileTypeIconPack || declared == FileTypeDrawable {
+			detected = declared
 		}
-		c.JSON(http.StatusOK, gin.H{"icon_pack": res})
-	case "", "appfilter":
-		items, err := ParseFromReader(f)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{"items": items})
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot detect file type: " + err.Error()})
+		return
+	}
+	if declared != FileTypeUnknown && declared != detected {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("type mismatch: declared %q but file looks like %q", declared, detected),
+			"type":  detected,
+		})
+		return
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot rewind uploaded file: " + err.Error()})
+		return
+	}
+
+	var key string
+	var result any
+	switch detected {
+	case FileTypeIconPack:
+		key = "icon_pack"
+		result, err = ParseIconPackFromReader(f)
+	case FileTypeDrawable:
+		key = "drawable"
+		result, err = ParseDrawableFromReader(f)
+	case FileTypeAppMap:
+		key = "appmap"
+		result, err = ParseAppMapFromReader(f)
+	case FileTypeTheme:
+		key = "theme"
+		result, err = ParseThemeFromReader(f)
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown type: " + fileType})
+		key = "items"
+		result, err = ParseFromReader(f)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "type": detected})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"type": detected, key: result})
+}
+
+// readArchiveUpload handles the archive branch of POST /readfile. Every icon
+// pack file found in the archive is returned under the same key the plain
+// XML upload would use.
+func readArchiveUpload(c *gin.Context, f io.ReaderAt, size int64) {
+	if declared := FileType(c.PostForm("type")); declared != FileTypeUnknown && declared != FileTypeArchive {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("type mismatch: declared %q but file looks like %q", declared, FileTypeArchive),
+			"type":  FileTypeArchive,
+		})
+		return
 	}
+
+	res, err := ParseArchive(f, size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "type": FileTypeArchive})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"type":     FileTypeArchive,
+		"items":    res.AppFilter,
+		"drawable": res.Drawable,
+		"appmap":   res.AppMap,
+		"sources":  res.Sources,
+	})
 }
@@ -0,0 +1,115 @@
+package reader
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"circle-center/globals"
+)
+
+// ErrStopStream can be returned by a StreamAppFilter callback to stop reading
+// early. StreamAppFilter then returns nil instead of the error.
+var ErrStopStream = errors.New("stop stream")
+
+// StreamAppFilter reads an appfilter.xml from r token by token and calls fn
+// for every <item> child of <resources> in document order. Unlike
+// ParseAppFilterDocument it never builds the whole tree in memory, which keeps
+// memory usage flat for packs with tens of thousands of items.
+//
+// The comment that directly precedes an item is used as its AppName, exactly
+// like ParseFromReader. If fn returns an error, streaming stops and that error
+// is returned (ErrStopStream is swallowed).
+func StreamAppFilter(r io.Reader, fn func(globals.Item) error) error {
+	return StreamAppFilterLines(r, func(it globals.Item, _ int) error {
+		return fn(it)
+	})
+}
+
+// StreamAppFilterLines behaves like StreamAppFilter but also passes the
+// 1-based line number on which each <item> element starts, which is what
+// diagnostics such as the appfilter linter report.
+func StreamAppFilterLines(r io.Reader, fn func(item globals.Item, line int) error) error {
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = CharsetReader
+
+	depth := 0
+	sawRoot := false
+	var currentComment string
+
+	for {
+		// InputPos after a token points just past it, so remember where the
+		// token begins before reading it.
+		line, _ := dec.InputPos()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read xml: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				if t.Name.Local != "resources" {
+					return fmt.Errorf("missing <resources> root element")
+				}
+				sawRoot = true
+				continue
+			}
+			if depth != 2 {
+				continue
+			}
+
+			switch t.Name.Local {
+			case "item":
+				comp := attrValue(t.Attr, "component")
+				pkg, act := ParseComponentInfo(comp)
+
+				item := globals.Item{
+					Component:    comp,
+					Drawable:     attrValue(t.Attr, "drawable"),
+					PackageName:  pkg,
+					ActivityName: act,
+					AppName:      currentComment,
+				}
+				if err := fn(item, line); err != nil {
+					if errors.Is(err, ErrStopStream) {
+						return nil
+					}
+					return err
+				}
+				currentComment = ""
+			case "calendar", "iconback", "iconmask", "iconupon", "scale", "dynamic-clock":
+				// Keep the comment rule identical to ParseAppFilterDocument:
+				// a comment is consumed by the next known element.
+				currentComment = ""
+			}
+		case xml.EndElement:
+			depth--
+		case xml.Comment:
+			if depth == 1 {
+				currentComment = ParseCommentText(string(t))
+			}
+		}
+	}
+
+	if !sawRoot {
+		return fmt.Errorf("missing <resources> root element")
+	}
+	return nil
+}
+
+// attrValue returns the value of the attribute with the given local name or
+// an empty string when it is absent.
+func attrValue(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
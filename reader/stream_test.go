@@ -0,0 +1,140 @@
+package reader
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"circle-center/globals"
+)
+
+// buildLargeAppFilter generates an appfilter.xml with n commented items,
+// roughly mirroring the shape of a large real-world pack.
+func buildLargeAppFilter(n int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n<resources>\n")
+	b.WriteString(`    <iconback img1="iconback"/>` + "\n")
+	b.WriteString(`    <scale factor="1.0"/>` + "\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "    <!-- App %d -->\n", i)
+		fmt.Fprintf(&b, `    <item component="ComponentInfo{com.example.app%d/com.example.app%d.MainActivity}" drawable="app_%d"/>`+"\n", i, i, i)
+	}
+	b.WriteString("</resources>\n")
+	return b.String()
+}
+
+func TestStreamAppFilterMatchesDocumentParser(t *testing.T) {
+	want, err := ParseAppFilterDocument(strings.NewReader(sampleAppFilter))
+	if err != nil {
+		t.Fatalf("parse appfilter document: %v", err)
+	}
+
+	var got []globals.Item
+	if err := StreamAppFilter(strings.NewReader(sampleAppFilter), func(it globals.Item) error {
+		got = append(got, it)
+		return nil
+	}); err != nil {
+		t.Fatalf("stream appfilter: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want.Items) {
+		t.Errorf("streamed items differ from document items:\n got: %+v\nwant: %+v", got, want.Items)
+	}
+}
+
+func TestStreamAppFilterIgnoresNestedElements(t *testing.T) {
+	const xmlStr = `<resources>
+    <!-- Outer -->
+    <group>
+        <!-- Inner -->
+        <item component="ComponentInfo{nested/.Nested}" drawable="nested"/>
+    </group>
+    <item component="ComponentInfo{top/.Top}" drawable="top"/>
+</resources>`
+
+	items, err := ParseFromReader(strings.NewReader(xmlStr))
+	if err != nil {
+		t.Fatalf("parse appfilter: %v", err)
+	}
+	if len(items) != 1 || items[0].Drawable != "top" {
+		t.Fatalf("expected only top-level item, got %+v", items)
+	}
+	if items[0].AppName != "Outer" {
+		t.Errorf("expected app name Outer, got %q", items[0].AppName)
+	}
+}
+
+func TestStreamAppFilterStop(t *testing.T) {
+	count := 0
+	err := StreamAppFilter(strings.NewReader(buildLargeAppFilter(100)), func(it globals.Item) error {
+		count++
+		if count == 10 {
+			return ErrStopStream
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("stream appfilter: %v", err)
+	}
+	if count != 10 {
+		t.Errorf("expected streaming to stop after 10 items, got %d", count)
+	}
+}
+
+func TestStreamAppFilterMissingRoot(t *testing.T) {
+	err := StreamAppFilter(strings.NewReader(`<appmap><item/></appmap>`), func(globals.Item) error { return nil })
+	if err == nil {
+		t.Fatal("expected error for non-resources root")
+	}
+}
+
+func TestParseFromReaderDeclaredCharset(t *testing.T) {
+	src := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<resources>\n" +
+		"    <!-- Caf\xe9 -->\n" +
+		"    <item component=\"ComponentInfo{com.cafe/com.cafe.Main}\" drawable=\"cafe\"/>\n" +
+		"</resources>\n"
+
+	items, err := ParseFromReader(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("parse latin-1 appfilter: %v", err)
+	}
+	if len(items) != 1 || items[0].AppName != "Café" || items[0].Drawable != "cafe" {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+
+	// Unknown labels are passed through like etree did.
+	src = strings.Replace(src, "ISO-8859-1", "x-unknown", 1)
+	src = strings.Replace(src, "Caf\xe9", "Cafe", 1)
+	if _, err := ParseFromReader(strings.NewReader(src)); err != nil {
+		t.Fatalf("parse appfilter with unknown charset: %v", err)
+	}
+}
+
+func BenchmarkParseAppFilterDocument(b *testing.B) {
+	data := buildLargeAppFilter(20000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseAppFilterDocument(strings.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStreamAppFilter(b *testing.B) {
+	data := buildLargeAppFilter(20000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n := 0
+		if err := StreamAppFilter(strings.NewReader(data), func(globals.Item) error {
+			n++
+			return nil
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
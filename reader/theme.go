@@ -0,0 +1,66 @@
+package reader
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/beevik/etree"
+
+	"circle-center/globals"
+)
+
+// ParseThemeFromReader parses a theme_resources.xml file from the provided
+// io.Reader. Every <AppIcon name image> child of <Theme> becomes a
+// globals.ThemeIcon; the preceding comment, if any, is used as AppName.
+func ParseThemeFromReader(r io.Reader) (globals.ThemeResources, error) {
+	doc := etree.NewDocument()
+	if _, err := doc.ReadFrom(r); err != nil {
+		return globals.ThemeResources{}, fmt.Errorf("failed to read xml: %w", err)
+	}
+
+	root := doc.SelectElement("Theme")
+	if root == nil {
+		return globals.ThemeResources{}, fmt.Errorf("missing <Theme> root element")
+	}
+
+	var res globals.ThemeResources
+	var currentComment string
+	for _, tok := range root.Child {
+		switch t := tok.(type) {
+		case *etree.Comment:
+			currentComment = ParseCommentText(t.Data)
+		case *etree.Element:
+			switch t.Tag {
+			case "Label":
+				res.Label = strings.TrimSpace(t.SelectAttrValue("value", ""))
+			case "AppIcon":
+				comp := strings.TrimSpace(t.SelectAttrValue("name", ""))
+				// theme_resources stores bare "pkg/activity" components.
+				pkg, act := ParseComponentInfo("ComponentInfo{" + comp + "}")
+				res.Icons = append(res.Icons, globals.ThemeIcon{
+					Component:    comp,
+					Drawable:     strings.TrimSpace(t.SelectAttrValue("image", "")),
+					AppName:      currentComment,
+					PackageName:  pkg,
+					ActivityName: act,
+				})
+				currentComment = ""
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// ParseThemeFile opens and parses a theme_resources.xml file from the given path.
+func ParseThemeFile(path string) (globals.ThemeResources, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return globals.ThemeResources{}, fmt.Errorf("cannot open file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return ParseThemeFromReader(f)
+}
@@ -1,6 +1,23 @@
 package reader
 
-import "strings"
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// CharsetReader converts a document declared in a non-UTF-8 encoding (e.g.
+// encoding="ISO-8859-1") to UTF-8 for encoding/xml. Labels that are not
+// recognised are passed through unchanged, which matches the etree default
+// the parsers used before.
+func CharsetReader(label string, input io.Reader) (io.Reader, error) {
+	r, err := charset.NewReaderLabel(label, input)
+	if err != nil {
+		return input, nil
+	}
+	return r, nil
+}
 
 // ParseComponentInfo parses a ComponentInfo string (e.g. "ComponentInfo{pkg/act}")
 // and returns its package and activity names. Empty strings are returned when
@@ -38,3 +55,27 @@ func ParseComponentInfo(component string) (packageName, activityName string) {
 func ParseCommentText(comment string) string {
 	return strings.TrimSpace(comment)
 }
+
+// NormalizeComponent returns a canonical spelling of a ComponentInfo string so
+// that equivalent components compare equal: all whitespace is removed, the
+// "ComponentInfo{" wrapper is matched case-insensitively and a relative
+// activity (".MainActivity") is expanded with the package name. Values that
+// are not wrapped in ComponentInfo{} only have their whitespace removed.
+func NormalizeComponent(component string) string {
+	const prefix = "ComponentInfo{"
+
+	s := strings.Join(strings.Fields(component), "")
+	if len(s) <= len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) || !strings.HasSuffix(s, "}") {
+		return s
+	}
+
+	inner := s[len(prefix) : len(s)-1]
+	pkg, act, found := strings.Cut(inner, "/")
+	if !found {
+		return prefix + pkg + "}"
+	}
+	if strings.HasPrefix(act, ".") {
+		act = pkg + act
+	}
+	return prefix + pkg + "/" + act + "}"
+}
@@ -0,0 +1,20 @@
+package reader
+
+import "testing"
+
+func TestNormalizeComponent(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ComponentInfo{com.example/com.example.Main}", "ComponentInfo{com.example/com.example.Main}"},
+		{"ComponentInfo{com.example/.Main}", "ComponentInfo{com.example/com.example.Main}"},
+		{"componentinfo{ com.example / .Main }", "ComponentInfo{com.example/com.example.Main}"},
+		{"COMPONENTINFO{com.example}", "ComponentInfo{com.example}"},
+		{" :LAUNCHER_ACTION_APP_DRAWER ", ":LAUNCHER_ACTION_APP_DRAWER"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeComponent(tt.in); got != tt.want {
+			t.Errorf("NormalizeComponent(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+-- Drop project invitations migration
+
+DROP TABLE IF EXISTS project_invitations;
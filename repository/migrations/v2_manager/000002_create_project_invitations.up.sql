@@ -0,0 +1,31 @@
+-- Create project invitations migration
+-- Email invitations to collaborate on a project with a given role
+
+CREATE TABLE project_invitations (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  project_id BIGINT UNSIGNED NOT NULL,
+  email VARCHAR(255) NOT NULL COMMENT 'Invited email address, lowercased',
+  role ENUM('admin', 'editor', 'viewer') NOT NULL COMMENT 'Role granted on acceptance',
+  token_hash CHAR(64) NOT NULL COMMENT 'SHA-256 of the accept token, never store plaintext',
+  status ENUM('pending', 'accepted', 'declined', 'revoked') NOT NULL DEFAULT 'pending' COMMENT 'Invitation state',
+  invited_by_user_id BIGINT UNSIGNED NOT NULL,
+  responded_by_user_id BIGINT UNSIGNED NULL COMMENT 'User who accepted or declined',
+  expires_at TIMESTAMP(6) NOT NULL COMMENT 'Accept token expiry, extended on resend',
+  sent_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  responded_at TIMESTAMP(6) NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+  -- Indexes and constraints
+  PRIMARY KEY (id),
+  UNIQUE INDEX idx_unique_token_hash (token_hash),
+  INDEX idx_project_status (project_id, status),
+  INDEX idx_email_status (email, status),
+  INDEX idx_invited_by_user_id (invited_by_user_id),
+
+  -- Foreign key constraints
+  CONSTRAINT fk_project_invitations_project_id FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+  CONSTRAINT fk_project_invitations_invited_by FOREIGN KEY (invited_by_user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_project_invitations_responded_by FOREIGN KEY (responded_by_user_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci 
+  COMMENT='Project collaboration invitations by email';
@@ -0,0 +1,3 @@
+-- Drop project ownership transfers migration
+
+DROP TABLE IF EXISTS project_ownership_transfers;
@@ -0,0 +1,25 @@
+-- Create project ownership transfers migration
+-- Ownership transfers proposed by the owner and accepted by the new owner
+
+CREATE TABLE project_ownership_transfers (
+  id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  project_id BIGINT UNSIGNED NOT NULL,
+  from_user_id BIGINT UNSIGNED NOT NULL COMMENT 'Owner who proposed the transfer',
+  to_user_id BIGINT UNSIGNED NOT NULL COMMENT 'Member who becomes the owner on acceptance',
+  status ENUM('pending', 'accepted', 'declined', 'cancelled') NOT NULL DEFAULT 'pending' COMMENT 'Transfer state',
+  responded_at TIMESTAMP(6) NULL,
+  created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+
+  -- Indexes and constraints
+  PRIMARY KEY (id),
+  INDEX idx_project_status (project_id, status),
+  INDEX idx_to_user_status (to_user_id, status),
+  INDEX idx_from_user_id (from_user_id),
+
+  -- Foreign key constraints
+  CONSTRAINT fk_project_ownership_transfers_project_id FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+  CONSTRAINT fk_project_ownership_transfers_from_user FOREIGN KEY (from_user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_project_ownership_transfers_to_user FOREIGN KEY (to_user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci 
+  COMMENT='Project ownership transfers';
@@ -0,0 +1,6 @@
+-- Drop project archive and soft delete migration
+
+ALTER TABLE projects
+  DROP INDEX idx_deleted_at,
+  DROP COLUMN deleted_at,
+  DROP COLUMN archived_at;
@@ -0,0 +1,7 @@
+-- Add project archive and soft delete migration
+-- Archived projects are read-only; deleted projects are purged after a retention window
+
+ALTER TABLE projects
+  ADD COLUMN archived_at TIMESTAMP(6) NULL COMMENT 'Set while the project is archived (read-only)' AFTER icon_count,
+  ADD COLUMN deleted_at TIMESTAMP(6) NULL COMMENT 'Set when the project was deleted; purged after the retention window' AFTER archived_at,
+  ADD INDEX idx_deleted_at (deleted_at);
@@ -10,6 +10,9 @@ INSERT INTO projects (
 -- name: GetProjectByID :one
 SELECT * FROM projects WHERE id = ? LIMIT 1;
 
+-- name: LockProject :one
+SELECT id FROM projects WHERE id = ? FOR UPDATE;
+
 -- name: GetProjectBySlug :one
 SELECT * FROM projects WHERE owner_user_id = ? AND slug = ? LIMIT 1;
 
@@ -23,15 +26,15 @@ SELECT * FROM projects WHERE owner_user_id = ? ORDER BY created_at DESC LIMIT ?
 -- name: ListOwnedProjectIDs :many
 SELECT id 
 FROM projects 
-WHERE owner_user_id = ? 
+WHERE owner_user_id = ? AND deleted_at IS NULL 
 ORDER BY created_at DESC 
 LIMIT ? OFFSET ?;
 
 -- name: ListPublicProjects :many
-SELECT * FROM projects WHERE visibility = 'public' ORDER BY created_at DESC LIMIT ? OFFSET ?;
+SELECT * FROM projects WHERE visibility = 'public' AND deleted_at IS NULL ORDER BY created_at DESC LIMIT ? OFFSET ?;
 
 -- name: ListProjectsByVisibility :many
-SELECT * FROM projects WHERE visibility = ? ORDER BY created_at DESC LIMIT ? OFFSET ?;
+SELECT * FROM projects WHERE visibility = ? AND deleted_at IS NULL ORDER BY created_at DESC LIMIT ? OFFSET ?;
 
 -- name: UpdateProject :exec
 UPDATE projects SET 
@@ -49,11 +52,50 @@ UPDATE projects SET
   updated_at = CURRENT_TIMESTAMP(6)
 WHERE id = ?;
 
+-- name: UpdateProjectOwner :execresult
+UPDATE projects SET 
+  owner_user_id = sqlc.arg(new_owner_user_id),
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = sqlc.arg(id) AND owner_user_id = sqlc.arg(owner_user_id);
+
 -- name: DeleteProject :exec
 DELETE FROM projects WHERE id = ? AND owner_user_id = ?;
 
+-- name: ArchiveProject :exec
+UPDATE projects SET 
+  archived_at = CURRENT_TIMESTAMP(6),
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND archived_at IS NULL;
+
+-- name: UnarchiveProject :exec
+UPDATE projects SET 
+  archived_at = NULL,
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ?;
+
+-- name: SoftDeleteProject :exec
+UPDATE projects SET 
+  deleted_at = CURRENT_TIMESTAMP(6),
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND deleted_at IS NULL;
+
+-- name: RestoreProject :exec
+UPDATE projects SET 
+  deleted_at = NULL,
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ?;
+
+-- name: ListDeletedProjectsByOwner :many
+SELECT * FROM projects WHERE owner_user_id = ? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC;
+
+-- name: ListProjectsDeletedBefore :many
+SELECT id FROM projects WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY deleted_at LIMIT ?;
+
+-- name: PurgeProject :execresult
+DELETE FROM projects WHERE id = ? AND deleted_at IS NOT NULL AND deleted_at < ?;
+
 -- name: CountProjectsByOwner :one
-SELECT COUNT(*) FROM projects WHERE owner_user_id = ?;
+SELECT COUNT(*) FROM projects WHERE owner_user_id = ? AND deleted_at IS NULL;
 
 -- name: CountProjectsByVisibility :one
 SELECT COUNT(*) FROM projects WHERE visibility = ?;
@@ -92,17 +134,19 @@ ORDER BY upr.added_at DESC;
 
 -- Lightweight ID fetch for collaborator projects (excluding owner role)
 -- name: ListCollaboratorProjectIDs :many
-SELECT project_id 
-FROM user_project_roles 
-WHERE user_id = ? AND role <> 'owner' 
-ORDER BY added_at DESC 
+SELECT upr.project_id 
+FROM user_project_roles upr
+JOIN projects p ON upr.project_id = p.id
+WHERE upr.user_id = ? AND upr.role <> 'owner' AND p.deleted_at IS NULL 
+ORDER BY upr.added_at DESC 
 LIMIT ? OFFSET ?;
 
 -- Count collaborator projects (excluding owner role)
 -- name: CountCollaboratorProjects :one
 SELECT COUNT(*) 
-FROM user_project_roles 
-WHERE user_id = ? AND role <> 'owner';
+FROM user_project_roles upr
+JOIN projects p ON upr.project_id = p.id
+WHERE upr.user_id = ? AND upr.role <> 'owner' AND p.deleted_at IS NULL;
 
 -- name: UpdateUserProjectRole :exec
 UPDATE user_project_roles SET role = ? WHERE user_id = ? AND project_id = ?;
@@ -152,6 +196,72 @@ DELETE FROM project_api_keys WHERE project_id = ?;
 -- name: CountActiveAPIKeys :one
 SELECT COUNT(*) FROM project_api_keys WHERE project_id = ? AND active = TRUE;
 
+-- =============================================================================
+-- PROJECT INVITATIONS MANAGEMENT
+-- =============================================================================
+
+-- name: CreateProjectInvitation :execresult
+INSERT INTO project_invitations (
+  project_id, email, role, token_hash, invited_by_user_id, expires_at
+) VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetProjectInvitationByID :one
+SELECT * FROM project_invitations WHERE id = ? LIMIT 1;
+
+-- name: GetProjectInvitationByTokenHash :one
+SELECT * FROM project_invitations WHERE token_hash = ? LIMIT 1;
+
+-- name: GetPendingProjectInvitation :one
+SELECT * FROM project_invitations
+WHERE project_id = ? AND email = ? AND status = 'pending'
+LIMIT 1;
+
+-- name: ListProjectInvitations :many
+SELECT * FROM project_invitations
+WHERE project_id = ? AND status = ?
+ORDER BY created_at DESC;
+
+-- name: ListPendingInvitationsByEmail :many
+SELECT pi.id, pi.project_id, pi.role, pi.expires_at, pi.created_at, p.name, p.slug, u.username AS invited_by_username
+FROM project_invitations pi
+JOIN projects p ON pi.project_id = p.id
+JOIN users u ON pi.invited_by_user_id = u.id
+WHERE pi.email = ? AND pi.status = 'pending' AND pi.expires_at > CURRENT_TIMESTAMP(6) AND p.deleted_at IS NULL
+ORDER BY pi.created_at DESC;
+
+-- name: UpdateProjectInvitationStatus :execresult
+UPDATE project_invitations SET 
+  status = ?,
+  responded_by_user_id = ?,
+  responded_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND status = 'pending';
+
+-- name: UpdateProjectInvitationToken :exec
+UPDATE project_invitations SET 
+  token_hash = ?,
+  expires_at = ?,
+  sent_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND status = 'pending';
+
+-- =============================================================================
+-- PROJECT OWNERSHIP TRANSFERS
+-- =============================================================================
+
+-- name: CreateProjectOwnershipTransfer :execresult
+INSERT INTO project_ownership_transfers (project_id, from_user_id, to_user_id) VALUES (?, ?, ?);
+
+-- name: GetPendingOwnershipTransfer :one
+SELECT * FROM project_ownership_transfers
+WHERE project_id = ? AND status = 'pending'
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: UpdateOwnershipTransferStatus :execresult
+UPDATE project_ownership_transfers SET 
+  status = ?,
+  responded_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND status = 'pending';
+
 -- =============================================================================
 -- ICONS MANAGEMENT
 -- =============================================================================
@@ -187,6 +297,12 @@ UPDATE icons SET
   updated_at = CURRENT_TIMESTAMP(6)
 WHERE id = ? AND project_id = ?;
 
+-- name: UpdateIconDrawable :exec
+UPDATE icons SET 
+  drawable = ?,
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND project_id = ?;
+
 -- name: UpdateIconStatus :exec
 UPDATE icons SET 
   status = ?,
@@ -371,7 +487,7 @@ FROM user_quotas uq
 LEFT JOIN (
   SELECT owner_user_id, COUNT(*) as project_count 
   FROM projects 
-  WHERE owner_user_id = ? 
+  WHERE owner_user_id = ? AND deleted_at IS NULL
   GROUP BY owner_user_id
 ) p ON uq.user_id = p.owner_user_id
 WHERE uq.user_id = ?;
@@ -473,3 +589,11 @@ JOIN icons i2 ON i1.component_info = i2.component_info
   AND i1.id != i2.id
 WHERE i1.project_id = ?
 ORDER BY i1.component_info, i1.created_at ASC;
+
+-- Component identifiers stored for a package in any project's icons or
+-- request items, used to build the component index for activity resolution
+-- name: ListComponentsByPackage :many
+SELECT component_info FROM icons WHERE icons.pkg = ?
+UNION ALL
+SELECT component_info FROM request_items WHERE request_items.pkg = ?
+LIMIT ?;
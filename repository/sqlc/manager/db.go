@@ -24,6 +24,9 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.archiveProjectStmt, err = db.PrepareContext(ctx, archiveProject); err != nil {
+		return nil, fmt.Errorf("error preparing query ArchiveProject: %w", err)
+	}
 	if q.checkUserQuotaStmt, err = db.PrepareContext(ctx, checkUserQuota); err != nil {
 		return nil, fmt.Errorf("error preparing query CheckUserQuota: %w", err)
 	}
@@ -72,6 +75,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.createProjectAPIKeyStmt, err = db.PrepareContext(ctx, createProjectAPIKey); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateProjectAPIKey: %w", err)
 	}
+	if q.createProjectInvitationStmt, err = db.PrepareContext(ctx, createProjectInvitation); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateProjectInvitation: %w", err)
+	}
+	if q.createProjectOwnershipTransferStmt, err = db.PrepareContext(ctx, createProjectOwnershipTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateProjectOwnershipTransfer: %w", err)
+	}
 	if q.createRequestItemStmt, err = db.PrepareContext(ctx, createRequestItem); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateRequestItem: %w", err)
 	}
@@ -147,6 +156,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getItemStatsStmt, err = db.PrepareContext(ctx, getItemStats); err != nil {
 		return nil, fmt.Errorf("error preparing query GetItemStats: %w", err)
 	}
+	if q.getPendingOwnershipTransferStmt, err = db.PrepareContext(ctx, getPendingOwnershipTransfer); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPendingOwnershipTransfer: %w", err)
+	}
+	if q.getPendingProjectInvitationStmt, err = db.PrepareContext(ctx, getPendingProjectInvitation); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPendingProjectInvitation: %w", err)
+	}
 	if q.getProjectAPIKeyByHashStmt, err = db.PrepareContext(ctx, getProjectAPIKeyByHash); err != nil {
 		return nil, fmt.Errorf("error preparing query GetProjectAPIKeyByHash: %w", err)
 	}
@@ -162,6 +177,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getProjectBySlugStmt, err = db.PrepareContext(ctx, getProjectBySlug); err != nil {
 		return nil, fmt.Errorf("error preparing query GetProjectBySlug: %w", err)
 	}
+	if q.getProjectInvitationByIDStmt, err = db.PrepareContext(ctx, getProjectInvitationByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProjectInvitationByID: %w", err)
+	}
+	if q.getProjectInvitationByTokenHashStmt, err = db.PrepareContext(ctx, getProjectInvitationByTokenHash); err != nil {
+		return nil, fmt.Errorf("error preparing query GetProjectInvitationByTokenHash: %w", err)
+	}
 	if q.getProjectStatsStmt, err = db.PrepareContext(ctx, getProjectStats); err != nil {
 		return nil, fmt.Errorf("error preparing query GetProjectStats: %w", err)
 	}
@@ -186,6 +207,12 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listCollaboratorProjectIDsStmt, err = db.PrepareContext(ctx, listCollaboratorProjectIDs); err != nil {
 		return nil, fmt.Errorf("error preparing query ListCollaboratorProjectIDs: %w", err)
 	}
+	if q.listComponentsByPackageStmt, err = db.PrepareContext(ctx, listComponentsByPackage); err != nil {
+		return nil, fmt.Errorf("error preparing query ListComponentsByPackage: %w", err)
+	}
+	if q.listDeletedProjectsByOwnerStmt, err = db.PrepareContext(ctx, listDeletedProjectsByOwner); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDeletedProjectsByOwner: %w", err)
+	}
 	if q.listIconsByPackageStmt, err = db.PrepareContext(ctx, listIconsByPackage); err != nil {
 		return nil, fmt.Errorf("error preparing query ListIconsByPackage: %w", err)
 	}
@@ -198,6 +225,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listOwnedProjectIDsStmt, err = db.PrepareContext(ctx, listOwnedProjectIDs); err != nil {
 		return nil, fmt.Errorf("error preparing query ListOwnedProjectIDs: %w", err)
 	}
+	if q.listPendingInvitationsByEmailStmt, err = db.PrepareContext(ctx, listPendingInvitationsByEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPendingInvitationsByEmail: %w", err)
+	}
 	if q.listProjectAPIKeysStmt, err = db.PrepareContext(ctx, listProjectAPIKeys); err != nil {
 		return nil, fmt.Errorf("error preparing query ListProjectAPIKeys: %w", err)
 	}
@@ -207,6 +237,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listProjectIconsStmt, err = db.PrepareContext(ctx, listProjectIcons); err != nil {
 		return nil, fmt.Errorf("error preparing query ListProjectIcons: %w", err)
 	}
+	if q.listProjectInvitationsStmt, err = db.PrepareContext(ctx, listProjectInvitations); err != nil {
+		return nil, fmt.Errorf("error preparing query ListProjectInvitations: %w", err)
+	}
 	if q.listProjectRequestItemsStmt, err = db.PrepareContext(ctx, listProjectRequestItems); err != nil {
 		return nil, fmt.Errorf("error preparing query ListProjectRequestItems: %w", err)
 	}
@@ -219,6 +252,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listProjectsByVisibilityStmt, err = db.PrepareContext(ctx, listProjectsByVisibility); err != nil {
 		return nil, fmt.Errorf("error preparing query ListProjectsByVisibility: %w", err)
 	}
+	if q.listProjectsDeletedBeforeStmt, err = db.PrepareContext(ctx, listProjectsDeletedBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query ListProjectsDeletedBefore: %w", err)
+	}
 	if q.listPublicProjectsStmt, err = db.PrepareContext(ctx, listPublicProjects); err != nil {
 		return nil, fmt.Errorf("error preparing query ListPublicProjects: %w", err)
 	}
@@ -234,27 +270,57 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.listUserProjectsStmt, err = db.PrepareContext(ctx, listUserProjects); err != nil {
 		return nil, fmt.Errorf("error preparing query ListUserProjects: %w", err)
 	}
+	if q.lockProjectStmt, err = db.PrepareContext(ctx, lockProject); err != nil {
+		return nil, fmt.Errorf("error preparing query LockProject: %w", err)
+	}
+	if q.purgeProjectStmt, err = db.PrepareContext(ctx, purgeProject); err != nil {
+		return nil, fmt.Errorf("error preparing query PurgeProject: %w", err)
+	}
+	if q.restoreProjectStmt, err = db.PrepareContext(ctx, restoreProject); err != nil {
+		return nil, fmt.Errorf("error preparing query RestoreProject: %w", err)
+	}
 	if q.searchIconsStmt, err = db.PrepareContext(ctx, searchIcons); err != nil {
 		return nil, fmt.Errorf("error preparing query SearchIcons: %w", err)
 	}
+	if q.softDeleteProjectStmt, err = db.PrepareContext(ctx, softDeleteProject); err != nil {
+		return nil, fmt.Errorf("error preparing query SoftDeleteProject: %w", err)
+	}
+	if q.unarchiveProjectStmt, err = db.PrepareContext(ctx, unarchiveProject); err != nil {
+		return nil, fmt.Errorf("error preparing query UnarchiveProject: %w", err)
+	}
 	if q.updateAPIKeyLastUsedStmt, err = db.PrepareContext(ctx, updateAPIKeyLastUsed); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateAPIKeyLastUsed: %w", err)
 	}
 	if q.updateIconStmt, err = db.PrepareContext(ctx, updateIcon); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateIcon: %w", err)
 	}
+	if q.updateIconDrawableStmt, err = db.PrepareContext(ctx, updateIconDrawable); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateIconDrawable: %w", err)
+	}
 	if q.updateIconStatusStmt, err = db.PrepareContext(ctx, updateIconStatus); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateIconStatus: %w", err)
 	}
 	if q.updateItemResolutionStmt, err = db.PrepareContext(ctx, updateItemResolution); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateItemResolution: %w", err)
 	}
+	if q.updateOwnershipTransferStatusStmt, err = db.PrepareContext(ctx, updateOwnershipTransferStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateOwnershipTransferStatus: %w", err)
+	}
 	if q.updateProjectStmt, err = db.PrepareContext(ctx, updateProject); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateProject: %w", err)
 	}
 	if q.updateProjectIconCountStmt, err = db.PrepareContext(ctx, updateProjectIconCount); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateProjectIconCount: %w", err)
 	}
+	if q.updateProjectInvitationStatusStmt, err = db.PrepareContext(ctx, updateProjectInvitationStatus); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateProjectInvitationStatus: %w", err)
+	}
+	if q.updateProjectInvitationTokenStmt, err = db.PrepareContext(ctx, updateProjectInvitationToken); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateProjectInvitationToken: %w", err)
+	}
+	if q.updateProjectOwnerStmt, err = db.PrepareContext(ctx, updateProjectOwner); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateProjectOwner: %w", err)
+	}
 	if q.updateRequestArchivePathStmt, err = db.PrepareContext(ctx, updateRequestArchivePath); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateRequestArchivePath: %w", err)
 	}
@@ -275,6 +341,11 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 
 func (q *Queries) Close() error {
 	var err error
+	if q.archiveProjectStmt != nil {
+		if cerr := q.archiveProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing archiveProjectStmt: %w", cerr)
+		}
+	}
 	if q.checkUserQuotaStmt != nil {
 		if cerr := q.checkUserQuotaStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing checkUserQuotaStmt: %w", cerr)
@@ -355,6 +426,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createProjectAPIKeyStmt: %w", cerr)
 		}
 	}
+	if q.createProjectInvitationStmt != nil {
+		if cerr := q.createProjectInvitationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createProjectInvitationStmt: %w", cerr)
+		}
+	}
+	if q.createProjectOwnershipTransferStmt != nil {
+		if cerr := q.createProjectOwnershipTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createProjectOwnershipTransferStmt: %w", cerr)
+		}
+	}
 	if q.createRequestItemStmt != nil {
 		if cerr := q.createRequestItemStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createRequestItemStmt: %w", cerr)
@@ -480,6 +561,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getItemStatsStmt: %w", cerr)
 		}
 	}
+	if q.getPendingOwnershipTransferStmt != nil {
+		if cerr := q.getPendingOwnershipTransferStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPendingOwnershipTransferStmt: %w", cerr)
+		}
+	}
+	if q.getPendingProjectInvitationStmt != nil {
+		if cerr := q.getPendingProjectInvitationStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPendingProjectInvitationStmt: %w", cerr)
+		}
+	}
 	if q.getProjectAPIKeyByHashStmt != nil {
 		if cerr := q.getProjectAPIKeyByHashStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getProjectAPIKeyByHashStmt: %w", cerr)
@@ -505,6 +596,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getProjectBySlugStmt: %w", cerr)
 		}
 	}
+	if q.getProjectInvitationByIDStmt != nil {
+		if cerr := q.getProjectInvitationByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProjectInvitationByIDStmt: %w", cerr)
+		}
+	}
+	if q.getProjectInvitationByTokenHashStmt != nil {
+		if cerr := q.getProjectInvitationByTokenHashStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getProjectInvitationByTokenHashStmt: %w", cerr)
+		}
+	}
 	if q.getProjectStatsStmt != nil {
 		if cerr := q.getProjectStatsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getProjectStatsStmt: %w", cerr)
@@ -545,6 +646,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listCollaboratorProjectIDsStmt: %w", cerr)
 		}
 	}
+	if q.listComponentsByPackageStmt != nil {
+		if cerr := q.listComponentsByPackageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listComponentsByPackageStmt: %w", cerr)
+		}
+	}
+	if q.listDeletedProjectsByOwnerStmt != nil {
+		if cerr := q.listDeletedProjectsByOwnerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDeletedProjectsByOwnerStmt: %w", cerr)
+		}
+	}
 	if q.listIconsByPackageStmt != nil {
 		if cerr := q.listIconsByPackageStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listIconsByPackageStmt: %w", cerr)
@@ -565,6 +676,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listOwnedProjectIDsStmt: %w", cerr)
 		}
 	}
+	if q.listPendingInvitationsByEmailStmt != nil {
+		if cerr := q.listPendingInvitationsByEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPendingInvitationsByEmailStmt: %w", cerr)
+		}
+	}
 	if q.listProjectAPIKeysStmt != nil {
 		if cerr := q.listProjectAPIKeysStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listProjectAPIKeysStmt: %w", cerr)
@@ -580,6 +696,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listProjectIconsStmt: %w", cerr)
 		}
 	}
+	if q.listProjectInvitationsStmt != nil {
+		if cerr := q.listProjectInvitationsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listProjectInvitationsStmt: %w", cerr)
+		}
+	}
 	if q.listProjectRequestItemsStmt != nil {
 		if cerr := q.listProjectRequestItemsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listProjectRequestItemsStmt: %w", cerr)
@@ -600,6 +721,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listProjectsByVisibilityStmt: %w", cerr)
 		}
 	}
+	if q.listProjectsDeletedBeforeStmt != nil {
+		if cerr := q.listProjectsDeletedBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listProjectsDeletedBeforeStmt: %w", cerr)
+		}
+	}
 	if q.listPublicProjectsStmt != nil {
 		if cerr := q.listPublicProjectsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listPublicProjectsStmt: %w", cerr)
@@ -625,11 +751,36 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing listUserProjectsStmt: %w", cerr)
 		}
 	}
+	if q.lockProjectStmt != nil {
+		if cerr := q.lockProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing lockProjectStmt: %w", cerr)
+		}
+	}
+	if q.purgeProjectStmt != nil {
+		if cerr := q.purgeProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing purgeProjectStmt: %w", cerr)
+		}
+	}
+	if q.restoreProjectStmt != nil {
+		if cerr := q.restoreProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing restoreProjectStmt: %w", cerr)
+		}
+	}
 	if q.searchIconsStmt != nil {
 		if cerr := q.searchIconsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing searchIconsStmt: %w", cerr)
 		}
 	}
+	if q.softDeleteProjectStmt != nil {
+		if cerr := q.softDeleteProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing softDeleteProjectStmt: %w", cerr)
+		}
+	}
+	if q.unarchiveProjectStmt != nil {
+		if cerr := q.unarchiveProjectStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing unarchiveProjectStmt: %w", cerr)
+		}
+	}
 	if q.updateAPIKeyLastUsedStmt != nil {
 		if cerr := q.updateAPIKeyLastUsedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateAPIKeyLastUsedStmt: %w", cerr)
@@ -640,6 +791,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateIconStmt: %w", cerr)
 		}
 	}
+	if q.updateIconDrawableStmt != nil {
+		if cerr := q.updateIconDrawableStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateIconDrawableStmt: %w", cerr)
+		}
+	}
 	if q.updateIconStatusStmt != nil {
 		if cerr := q.updateIconStatusStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateIconStatusStmt: %w", cerr)
@@ -650,6 +806,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateItemResolutionStmt: %w", cerr)
 		}
 	}
+	if q.updateOwnershipTransferStatusStmt != nil {
+		if cerr := q.updateOwnershipTransferStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateOwnershipTransferStatusStmt: %w", cerr)
+		}
+	}
 	if q.updateProjectStmt != nil {
 		if cerr := q.updateProjectStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateProjectStmt: %w", cerr)
@@ -660,6 +821,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateProjectIconCountStmt: %w", cerr)
 		}
 	}
+	if q.updateProjectInvitationStatusStmt != nil {
+		if cerr := q.updateProjectInvitationStatusStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateProjectInvitationStatusStmt: %w", cerr)
+		}
+	}
+	if q.updateProjectInvitationTokenStmt != nil {
+		if cerr := q.updateProjectInvitationTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateProjectInvitationTokenStmt: %w", cerr)
+		}
+	}
+	if q.updateProjectOwnerStmt != nil {
+		if cerr := q.updateProjectOwnerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateProjectOwnerStmt: %w", cerr)
+		}
+	}
 	if q.updateRequestArchivePathStmt != nil {
 		if cerr := q.updateRequestArchivePathStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateRequestArchivePathStmt: %w", cerr)
@@ -722,177 +898,221 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                               DBTX
-	tx                               *sql.Tx
-	checkUserQuotaStmt               *sql.Stmt
-	countActiveAPIKeysStmt           *sql.Stmt
-	countCollaboratorProjectsStmt    *sql.Stmt
-	countIconsByStatusStmt           *sql.Stmt
-	countItemsByResolutionStmt       *sql.Stmt
-	countProjectCollaboratorsStmt    *sql.Stmt
-	countProjectIconsStmt            *sql.Stmt
-	countProjectRequestsStmt         *sql.Stmt
-	countProjectsByOwnerStmt         *sql.Stmt
-	countProjectsByVisibilityStmt    *sql.Stmt
-	countRequestItemsStmt            *sql.Stmt
-	countRequestsByStatusStmt        *sql.Stmt
-	createIconStmt                   *sql.Stmt
-	createIconRequestStmt            *sql.Stmt
-	createProjectStmt                *sql.Stmt
-	createProjectAPIKeyStmt          *sql.Stmt
-	createRequestItemStmt            *sql.Stmt
-	createUserProjectRoleStmt        *sql.Stmt
-	createUserQuotaStmt              *sql.Stmt
-	deactivateAPIKeyStmt             *sql.Stmt
-	deleteAPIKeyStmt                 *sql.Stmt
-	deleteIconStmt                   *sql.Stmt
-	deleteIconRequestStmt            *sql.Stmt
-	deleteProjectStmt                *sql.Stmt
-	deleteProjectAPIKeysStmt         *sql.Stmt
-	deleteProjectCollaboratorsStmt   *sql.Stmt
-	deleteProjectIconsStmt           *sql.Stmt
-	deleteProjectRequestItemsStmt    *sql.Stmt
-	deleteProjectRequestsStmt        *sql.Stmt
-	deleteRequestItemStmt            *sql.Stmt
-	deleteRequestItemsStmt           *sql.Stmt
-	deleteUserProjectRoleStmt        *sql.Stmt
-	deleteUserQuotaStmt              *sql.Stmt
-	getDuplicateIconsStmt            *sql.Stmt
-	getIconByComponentStmt           *sql.Stmt
-	getIconByIDStmt                  *sql.Stmt
-	getIconRequestByIDStmt           *sql.Stmt
-	getIconRequestByIDAndProjectStmt *sql.Stmt
-	getIconStatsStmt                 *sql.Stmt
-	getIconWithRequestInfoStmt       *sql.Stmt
-	getItemStatsStmt                 *sql.Stmt
-	getProjectAPIKeyByHashStmt       *sql.Stmt
-	getProjectAPIKeyByIDStmt         *sql.Stmt
-	getProjectByIDStmt               *sql.Stmt
-	getProjectByIDAndOwnerStmt       *sql.Stmt
-	getProjectBySlugStmt             *sql.Stmt
-	getProjectStatsStmt              *sql.Stmt
-	getProjectWithStatsStmt          *sql.Stmt
-	getRequestItemByComponentStmt    *sql.Stmt
-	getRequestItemByIDStmt           *sql.Stmt
-	getRequestStatsStmt              *sql.Stmt
-	getUserProjectRoleStmt           *sql.Stmt
-	getUserQuotaStmt                 *sql.Stmt
-	listCollaboratorProjectIDsStmt   *sql.Stmt
-	listIconsByPackageStmt           *sql.Stmt
-	listIconsByStatusStmt            *sql.Stmt
-	listItemsByResolutionStmt        *sql.Stmt
-	listOwnedProjectIDsStmt          *sql.Stmt
-	listProjectAPIKeysStmt           *sql.Stmt
-	listProjectCollaboratorsStmt     *sql.Stmt
-	listProjectIconsStmt             *sql.Stmt
-	listProjectRequestItemsStmt      *sql.Stmt
-	listProjectRequestsStmt          *sql.Stmt
-	listProjectsByOwnerStmt          *sql.Stmt
-	listProjectsByVisibilityStmt     *sql.Stmt
-	listPublicProjectsStmt           *sql.Stmt
-	listRecentActivityStmt           *sql.Stmt
-	listRequestItemsStmt             *sql.Stmt
-	listRequestsByStatusStmt         *sql.Stmt
-	listUserProjectsStmt             *sql.Stmt
-	searchIconsStmt                  *sql.Stmt
-	updateAPIKeyLastUsedStmt         *sql.Stmt
-	updateIconStmt                   *sql.Stmt
-	updateIconStatusStmt             *sql.Stmt
-	updateItemResolutionStmt         *sql.Stmt
-	updateProjectStmt                *sql.Stmt
-	updateProjectIconCountStmt       *sql.Stmt
-	updateRequestArchivePathStmt     *sql.Stmt
-	updateRequestItemStmt            *sql.Stmt
-	updateRequestStatusStmt          *sql.Stmt
-	updateUserProjectRoleStmt        *sql.Stmt
-	updateUserQuotaStmt              *sql.Stmt
+	db                                  DBTX
+	tx                                  *sql.Tx
+	archiveProjectStmt                  *sql.Stmt
+	checkUserQuotaStmt                  *sql.Stmt
+	countActiveAPIKeysStmt              *sql.Stmt
+	countCollaboratorProjectsStmt       *sql.Stmt
+	countIconsByStatusStmt              *sql.Stmt
+	countItemsByResolutionStmt          *sql.Stmt
+	countProjectCollaboratorsStmt       *sql.Stmt
+	countProjectIconsStmt               *sql.Stmt
+	countProjectRequestsStmt            *sql.Stmt
+	countProjectsByOwnerStmt            *sql.Stmt
+	countProjectsByVisibilityStmt       *sql.Stmt
+	countRequestItemsStmt               *sql.Stmt
+	countRequestsByStatusStmt           *sql.Stmt
+	createIconStmt                      *sql.Stmt
+	createIconRequestStmt               *sql.Stmt
+	createProjectStmt                   *sql.Stmt
+	createProjectAPIKeyStmt             *sql.Stmt
+	createProjectInvitationStmt         *sql.Stmt
+	createProjectOwnershipTransferStmt  *sql.Stmt
+	createRequestItemStmt               *sql.Stmt
+	createUserProjectRoleStmt           *sql.Stmt
+	createUserQuotaStmt                 *sql.Stmt
+	deactivateAPIKeyStmt                *sql.Stmt
+	deleteAPIKeyStmt                    *sql.Stmt
+	deleteIconStmt                      *sql.Stmt
+	deleteIconRequestStmt               *sql.Stmt
+	deleteProjectStmt                   *sql.Stmt
+	deleteProjectAPIKeysStmt            *sql.Stmt
+	deleteProjectCollaboratorsStmt      *sql.Stmt
+	deleteProjectIconsStmt              *sql.Stmt
+	deleteProjectRequestItemsStmt       *sql.Stmt
+	deleteProjectRequestsStmt           *sql.Stmt
+	deleteRequestItemStmt               *sql.Stmt
+	deleteRequestItemsStmt              *sql.Stmt
+	deleteUserProjectRoleStmt           *sql.Stmt
+	deleteUserQuotaStmt                 *sql.Stmt
+	getDuplicateIconsStmt               *sql.Stmt
+	getIconByComponentStmt              *sql.Stmt
+	getIconByIDStmt                     *sql.Stmt
+	getIconRequestByIDStmt              *sql.Stmt
+	getIconRequestByIDAndProjectStmt    *sql.Stmt
+	getIconStatsStmt                    *sql.Stmt
+	getIconWithRequestInfoStmt          *sql.Stmt
+	getItemStatsStmt                    *sql.Stmt
+	getPendingOwnershipTransferStmt     *sql.Stmt
+	getPendingProjectInvitationStmt     *sql.Stmt
+	getProjectAPIKeyByHashStmt          *sql.Stmt
+	getProjectAPIKeyByIDStmt            *sql.Stmt
+	getProjectByIDStmt                  *sql.Stmt
+	getProjectByIDAndOwnerStmt          *sql.Stmt
+	getProjectBySlugStmt                *sql.Stmt
+	getProjectInvitationByIDStmt        *sql.Stmt
+	getProjectInvitationByTokenHashStmt *sql.Stmt
+	getProjectStatsStmt                 *sql.Stmt
+	getProjectWithStatsStmt             *sql.Stmt
+	getRequestItemByComponentStmt       *sql.Stmt
+	getRequestItemByIDStmt              *sql.Stmt
+	getRequestStatsStmt                 *sql.Stmt
+	getUserProjectRoleStmt              *sql.Stmt
+	getUserQuotaStmt                    *sql.Stmt
+	listCollaboratorProjectIDsStmt      *sql.Stmt
+	listComponentsByPackageStmt         *sql.Stmt
+	listDeletedProjectsByOwnerStmt      *sql.Stmt
+	listIconsByPackageStmt              *sql.Stmt
+	listIconsByStatusStmt               *sql.Stmt
+	listItemsByResolutionStmt           *sql.Stmt
+	listOwnedProjectIDsStmt             *sql.Stmt
+	listPendingInvitationsByEmailStmt   *sql.Stmt
+	listProjectAPIKeysStmt              *sql.Stmt
+	listProjectCollaboratorsStmt        *sql.Stmt
+	listProjectIconsStmt                *sql.Stmt
+	listProjectInvitationsStmt          *sql.Stmt
+	listProjectRequestItemsStmt         *sql.Stmt
+	listProjectRequestsStmt             *sql.Stmt
+	listProjectsByOwnerStmt             *sql.Stmt
+	listProjectsByVisibilityStmt        *sql.Stmt
+	listProjectsDeletedBeforeStmt       *sql.Stmt
+	listPublicProjectsStmt              *sql.Stmt
+	listRecentActivityStmt              *sql.Stmt
+	listRequestItemsStmt                *sql.Stmt
+	listRequestsByStatusStmt            *sql.Stmt
+	listUserProjectsStmt                *sql.Stmt
+	lockProjectStmt                     *sql.Stmt
+	purgeProjectStmt                    *sql.Stmt
+	restoreProjectStmt                  *sql.Stmt
+	searchIconsStmt                     *sql.Stmt
+	softDeleteProjectStmt               *sql.Stmt
+	unarchiveProjectStmt                *sql.Stmt
+	updateAPIKeyLastUsedStmt            *sql.Stmt
+	updateIconStmt                      *sql.Stmt
+	updateIconDrawableStmt              *sql.Stmt
+	updateIconStatusStmt                *sql.Stmt
+	updateItemResolutionStmt            *sql.Stmt
+	updateOwnershipTransferStatusStmt   *sql.Stmt
+	updateProjectStmt                   *sql.Stmt
+	updateProjectIconCountStmt          *sql.Stmt
+	updateProjectInvitationStatusStmt   *sql.Stmt
+	updateProjectInvitationTokenStmt    *sql.Stmt
+	updateProjectOwnerStmt              *sql.Stmt
+	updateRequestArchivePathStmt        *sql.Stmt
+	updateRequestItemStmt               *sql.Stmt
+	updateRequestStatusStmt             *sql.Stmt
+	updateUserProjectRoleStmt           *sql.Stmt
+	updateUserQuotaStmt                 *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                               tx,
-		tx:                               tx,
-		checkUserQuotaStmt:               q.checkUserQuotaStmt,
-		countActiveAPIKeysStmt:           q.countActiveAPIKeysStmt,
-		countCollaboratorProjectsStmt:    q.countCollaboratorProjectsStmt,
-		countIconsByStatusStmt:           q.countIconsByStatusStmt,
-		countItemsByResolutionStmt:       q.countItemsByResolutionStmt,
-		countProjectCollaboratorsStmt:    q.countProjectCollaboratorsStmt,
-		countProjectIconsStmt:            q.countProjectIconsStmt,
-		countProjectRequestsStmt:         q.countProjectRequestsStmt,
-		countProjectsByOwnerStmt:         q.countProjectsByOwnerStmt,
-		countProjectsByVisibilityStmt:    q.countProjectsByVisibilityStmt,
-		countRequestItemsStmt:            q.countRequestItemsStmt,
-		countRequestsByStatusStmt:        q.countRequestsByStatusStmt,
-		createIconStmt:                   q.createIconStmt,
-		createIconRequestStmt:            q.createIconRequestStmt,
-		createProjectStmt:                q.createProjectStmt,
-		createProjectAPIKeyStmt:          q.createProjectAPIKeyStmt,
-		createRequestItemStmt:            q.createRequestItemStmt,
-		createUserProjectRoleStmt:        q.createUserProjectRoleStmt,
-		createUserQuotaStmt:              q.createUserQuotaStmt,
-		deactivateAPIKeyStmt:             q.deactivateAPIKeyStmt,
-		deleteAPIKeyStmt:                 q.deleteAPIKeyStmt,
-		deleteIconStmt:                   q.deleteIconStmt,
-		deleteIconRequestStmt:            q.deleteIconRequestStmt,
-		deleteProjectStmt:                q.deleteProjectStmt,
-		deleteProjectAPIKeysStmt:         q.deleteProjectAPIKeysStmt,
-		deleteProjectCollaboratorsStmt:   q.deleteProjectCollaboratorsStmt,
-		deleteProjectIconsStmt:           q.deleteProjectIconsStmt,
-		deleteProjectRequestItemsStmt:    q.deleteProjectRequestItemsStmt,
-		deleteProjectRequestsStmt:        q.deleteProjectRequestsStmt,
-		deleteRequestItemStmt:            q.deleteRequestItemStmt,
-		deleteRequestItemsStmt:           q.deleteRequestItemsStmt,
-		deleteUserProjectRoleStmt:        q.deleteUserProjectRoleStmt,
-		deleteUserQuotaStmt:              q.deleteUserQuotaStmt,
-		getDuplicateIconsStmt:            q.getDuplicateIconsStmt,
-		getIconByComponentStmt:           q.getIconByComponentStmt,
-		getIconByIDStmt:                  q.getIconByIDStmt,
-		getIconRequestByIDStmt:           q.getIconRequestByIDStmt,
-		getIconRequestByIDAndProjectStmt: q.getIconRequestByIDAndProjectStmt,
-		getIconStatsStmt:                 q.getIconStatsStmt,
-		getIconWithRequestInfoStmt:       q.getIconWithRequestInfoStmt,
-		getItemStatsStmt:                 q.getItemStatsStmt,
-		getProjectAPIKeyByHashStmt:       q.getProjectAPIKeyByHashStmt,
-		getProjectAPIKeyByIDStmt:         q.getProjectAPIKeyByIDStmt,
-		getProjectByIDStmt:               q.getProjectByIDStmt,
-		getProjectByIDAndOwnerStmt:       q.getProjectByIDAndOwnerStmt,
-		getProjectBySlugStmt:             q.getProjectBySlugStmt,
-		getProjectStatsStmt:              q.getProjectStatsStmt,
-		getProjectWithStatsStmt:          q.getProjectWithStatsStmt,
-		getRequestItemByComponentStmt:    q.getRequestItemByComponentStmt,
-		getRequestItemByIDStmt:           q.getRequestItemByIDStmt,
-		getRequestStatsStmt:              q.getRequestStatsStmt,
-		getUserProjectRoleStmt:           q.getUserProjectRoleStmt,
-		getUserQuotaStmt:                 q.getUserQuotaStmt,
-		listCollaboratorProjectIDsStmt:   q.listCollaboratorProjectIDsStmt,
-		listIconsByPackageStmt:           q.listIconsByPackageStmt,
-		listIconsByStatusStmt:            q.listIconsByStatusStmt,
-		listItemsByResolutionStmt:        q.listItemsByResolutionStmt,
-		listOwnedProjectIDsStmt:          q.listOwnedProjectIDsStmt,
-		listProjectAPIKeysStmt:           q.listProjectAPIKeysStmt,
-		listProjectCollaboratorsStmt:     q.listProjectCollaboratorsStmt,
-		listProjectIconsStmt:             q.listProjectIconsStmt,
-		listProjectRequestItemsStmt:      q.listProjectRequestItemsStmt,
-		listProjectRequestsStmt:          q.listProjectRequestsStmt,
-		listProjectsByOwnerStmt:          q.listProjectsByOwnerStmt,
-		listProjectsByVisibilityStmt:     q.listProjectsByVisibilityStmt,
-		listPublicProjectsStmt:           q.listPublicProjectsStmt,
-		listRecentActivityStmt:           q.listRecentActivityStmt,
-		listRequestItemsStmt:             q.listRequestItemsStmt,
-		listRequestsByStatusStmt:         q.listRequestsByStatusStmt,
-		listUserProjectsStmt:             q.listUserProjectsStmt,
-		searchIconsStmt:                  q.searchIconsStmt,
-		updateAPIKeyLastUsedStmt:         q.updateAPIKeyLastUsedStmt,
-		updateIconStmt:                   q.updateIconStmt,
-		updateIconStatusStmt:             q.updateIconStatusStmt,
-		updateItemResolutionStmt:         q.updateItemResolutionStmt,
-		updateProjectStmt:                q.updateProjectStmt,
-		updateProjectIconCountStmt:       q.updateProjectIconCountStmt,
-		updateRequestArchivePathStmt:     q.updateRequestArchivePathStmt,
-		updateRequestItemStmt:            q.updateRequestItemStmt,
-		updateRequestStatusStmt:          q.updateRequestStatusStmt,
-		updateUserProjectRoleStmt:        q.updateUserProjectRoleStmt,
-		updateUserQuotaStmt:              q.updateUserQuotaStmt,
+		db:                                  tx,
+		tx:                                  tx,
+		archiveProjectStmt:                  q.archiveProjectStmt,
+		checkUserQuotaStmt:                  q.checkUserQuotaStmt,
+		countActiveAPIKeysStmt:              q.countActiveAPIKeysStmt,
+		countCollaboratorProjectsStmt:       q.countCollaboratorProjectsStmt,
+		countIconsByStatusStmt:              q.countIconsByStatusStmt,
+		countItemsByResolutionStmt:          q.countItemsByResolutionStmt,
+		countProjectCollaboratorsStmt:       q.countProjectCollaboratorsStmt,
+		countProjectIconsStmt:               q.countProjectIconsStmt,
+		countProjectRequestsStmt:            q.countProjectRequestsStmt,
+		countProjectsByOwnerStmt:            q.countProjectsByOwnerStmt,
+		countProjectsByVisibilityStmt:       q.countProjectsByVisibilityStmt,
+		countRequestItemsStmt:               q.countRequestItemsStmt,
+		countRequestsByStatusStmt:           q.countRequestsByStatusStmt,
+		createIconStmt:                      q.createIconStmt,
+		createIconRequestStmt:               q.createIconRequestStmt,
+		createProjectStmt:                   q.createProjectStmt,
+		createProjectAPIKeyStmt:             q.createProjectAPIKeyStmt,
+		createProjectInvitationStmt:         q.createProjectInvitationStmt,
+		createProjectOwnershipTransferStmt:  q.createProjectOwnershipTransferStmt,
+		createRequestItemStmt:               q.createRequestItemStmt,
+		createUserProjectRoleStmt:           q.createUserProjectRoleStmt,
+		createUserQuotaStmt:                 q.createUserQuotaStmt,
+		deactivateAPIKeyStmt:                q.deactivateAPIKeyStmt,
+		deleteAPIKeyStmt:                    q.deleteAPIKeyStmt,
+		deleteIconStmt:                      q.deleteIconStmt,
+		deleteIconRequestStmt:               q.deleteIconRequestStmt,
+		deleteProjectStmt:                   q.deleteProjectStmt,
+		deleteProjectAPIKeysStmt:            q.deleteProjectAPIKeysStmt,
+		deleteProjectCollaboratorsStmt:      q.deleteProjectCollaboratorsStmt,
+		deleteProjectIconsStmt:              q.deleteProjectIconsStmt,
+		deleteProjectRequestItemsStmt:       q.deleteProjectRequestItemsStmt,
+		deleteProjectRequestsStmt:           q.deleteProjectRequestsStmt,
+		deleteRequestItemStmt:               q.deleteRequestItemStmt,
+		deleteRequestItemsStmt:              q.deleteRequestItemsStmt,
+		deleteUserProjectRoleStmt:           q.deleteUserProjectRoleStmt,
+		deleteUserQuotaStmt:                 q.deleteUserQuotaStmt,
+		getDuplicateIconsStmt:               q.getDuplicateIconsStmt,
+		getIconByComponentStmt:              q.getIconByComponentStmt,
+		getIconByIDStmt:                     q.getIconByIDStmt,
+		getIconRequestByIDStmt:              q.getIconRequestByIDStmt,
+		getIconRequestByIDAndProjectStmt:    q.getIconRequestByIDAndProjectStmt,
+		getIconStatsStmt:                    q.getIconStatsStmt,
+		getIconWithRequestInfoStmt:          q.getIconWithRequestInfoStmt,
+		getItemStatsStmt:                    q.getItemStatsStmt,
+		getPendingOwnershipTransferStmt:     q.getPendingOwnershipTransferStmt,
+		getPendingProjectInvitationStmt:     q.getPendingProjectInvitationStmt,
+		getProjectAPIKeyByHashStmt:          q.getProjectAPIKeyByHashStmt,
+		getProjectAPIKeyByIDStmt:            q.getProjectAPIKeyByIDStmt,
+		getProjectByIDStmt:                  q.getProjectByIDStmt,
+		getProjectByIDAndOwnerStmt:          q.getProjectByIDAndOwnerStmt,
+		getProjectBySlugStmt:                q.getProjectBySlugStmt,
+		getProjectInvitationByIDStmt:        q.getProjectInvitationByIDStmt,
+		getProjectInvitationByTokenHashStmt: q.getProjectInvitationByTokenHashStmt,
+		getProjectStatsStmt:                 q.getProjectStatsStmt,
+		getProjectWithStatsStmt:             q.getProjectWithStatsStmt,
+		getRequestItemByComponentStmt:       q.getRequestItemByComponentStmt,
+		getRequestItemByIDStmt:              q.getRequestItemByIDStmt,
+		getRequestStatsStmt:                 q.getRequestStatsStmt,
+		getUserProjectRoleStmt:              q.getUserProjectRoleStmt,
+		getUserQuotaStmt:                    q.getUserQuotaStmt,
+		listCollaboratorProjectIDsStmt:      q.listCollaboratorProjectIDsStmt,
+		listComponentsByPackageStmt:         q.listComponentsByPackageStmt,
+		listDeletedProjectsByOwnerStmt:      q.listDeletedProjectsByOwnerStmt,
+		listIconsByPackageStmt:              q.listIconsByPackageStmt,
+		listIconsByStatusStmt:               q.listIconsByStatusStmt,
+		listItemsByResolutionStmt:           q.listItemsByResolutionStmt,
+		listOwnedProjectIDsStmt:             q.listOwnedProjectIDsStmt,
+		listPendingInvitationsByEmailStmt:   q.listPendingInvitationsByEmailStmt,
+		listProjectAPIKeysStmt:              q.listProjectAPIKeysStmt,
+		listProjectCollaboratorsStmt:        q.listProjectCollaboratorsStmt,
+		listProjectIconsStmt:                q.listProjectIconsStmt,
+		listProjectInvitationsStmt:          q.listProjectInvitationsStmt,
+		listProjectRequestItemsStmt:         q.listProjectRequestItemsStmt,
+		listProjectRequestsStmt:             q.listProjectRequestsStmt,
+		listProjectsByOwnerStmt:             q.listProjectsByOwnerStmt,
+		listProjectsByVisibilityStmt:        q.listProjectsByVisibilityStmt,
+		listProjectsDeletedBeforeStmt:       q.listProjectsDeletedBeforeStmt,
+		listPublicProjectsStmt:              q.listPublicProjectsStmt,
+		listRecentActivityStmt:              q.listRecentActivityStmt,
+		listRequestItemsStmt:                q.listRequestItemsStmt,
+		listRequestsByStatusStmt:            q.listRequestsByStatusStmt,
+		listUserProjectsStmt:                q.listUserProjectsStmt,
+		lockProjectStmt:                     q.lockProjectStmt,
+		purgeProjectStmt:                    q.purgeProjectStmt,
+		restoreProjectStmt:                  q.restoreProjectStmt,
+		searchIconsStmt:                     q.searchIconsStmt,
+		softDeleteProjectStmt:               q.softDeleteProjectStmt,
+		unarchiveProjectStmt:                q.unarchiveProjectStmt,
+		updateAPIKeyLastUsedStmt:            q.updateAPIKeyLastUsedStmt,
+		updateIconStmt:                      q.updateIconStmt,
+		updateIconDrawableStmt:              q.updateIconDrawableStmt,
+		updateIconStatusStmt:                q.updateIconStatusStmt,
+		updateItemResolutionStmt:            q.updateItemResolutionStmt,
+		updateOwnershipTransferStatusStmt:   q.updateOwnershipTransferStatusStmt,
+		updateProjectStmt:                   q.updateProjectStmt,
+		updateProjectIconCountStmt:          q.updateProjectIconCountStmt,
+		updateProjectInvitationStatusStmt:   q.updateProjectInvitationStatusStmt,
+		updateProjectInvitationTokenStmt:    q.updateProjectInvitationTokenStmt,
+		updateProjectOwnerStmt:              q.updateProjectOwnerStmt,
+		updateRequestArchivePathStmt:        q.updateRequestArchivePathStmt,
+		updateRequestItemStmt:               q.updateRequestItemStmt,
+		updateRequestStatusStmt:             q.updateRequestStatusStmt,
+		updateUserProjectRoleStmt:           q.updateUserProjectRoleStmt,
+		updateUserQuotaStmt:                 q.updateUserQuotaStmt,
 	}
 }
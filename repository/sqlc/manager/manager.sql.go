@@ -12,6 +12,18 @@ import (
 	"time"
 )
 
+const archiveProject = `-- name: ArchiveProject :exec
+UPDATE projects SET 
+  archived_at = CURRENT_TIMESTAMP(6),
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND archived_at IS NULL
+`
+
+func (q *Queries) ArchiveProject(ctx context.Context, id uint64) error {
+	_, err := q.exec(ctx, q.archiveProjectStmt, archiveProject, id)
+	return err
+}
+
 const checkUserQuota = `-- name: CheckUserQuota :one
 SELECT 
   uq.max_projects,
@@ -25,7 +37,7 @@ FROM user_quotas uq
 LEFT JOIN (
   SELECT owner_user_id, COUNT(*) as project_count 
   FROM projects 
-  WHERE owner_user_id = ? 
+  WHERE owner_user_id = ? AND deleted_at IS NULL
   GROUP BY owner_user_id
 ) p ON uq.user_id = p.owner_user_id
 WHERE uq.user_id = ?
@@ -62,8 +74,9 @@ func (q *Queries) CountActiveAPIKeys(ctx context.Context, projectID uint64) (int
 
 const countCollaboratorProjects = `-- name: CountCollaboratorProjects :one
 SELECT COUNT(*) 
-FROM user_project_roles 
-WHERE user_id = ? AND role <> 'owner'
+FROM user_project_roles upr
+JOIN projects p ON upr.project_id = p.id
+WHERE upr.user_id = ? AND upr.role <> 'owner' AND p.deleted_at IS NULL
 `
 
 // Count collaborator projects (excluding owner role)
@@ -140,7 +153,7 @@ func (q *Queries) CountProjectRequests(ctx context.Context, projectID uint64) (i
 }
 
 const countProjectsByOwner = `-- name: CountProjectsByOwner :one
-SELECT COUNT(*) FROM projects WHERE owner_user_id = ?
+SELECT COUNT(*) FROM projects WHERE owner_user_id = ? AND deleted_at IS NULL
 `
 
 func (q *Queries) CountProjectsByOwner(ctx context.Context, ownerUserID uint64) (int64, error) {
@@ -296,6 +309,54 @@ func (q *Queries) CreateProjectAPIKey(ctx context.Context, arg CreateProjectAPIK
 	return q.exec(ctx, q.createProjectAPIKeyStmt, createProjectAPIKey, arg.ProjectID, arg.Name, arg.TokenHash)
 }
 
+const createProjectInvitation = `-- name: CreateProjectInvitation :execresult
+
+INSERT INTO project_invitations (
+  project_id, email, role, token_hash, invited_by_user_id, expires_at
+) VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateProjectInvitationParams struct {
+	ProjectID       uint64                 `json:"project_id"`
+	Email           string                 `json:"email"`
+	Role            ProjectInvitationsRole `json:"role"`
+	TokenHash       string                 `json:"token_hash"`
+	InvitedByUserID uint64                 `json:"invited_by_user_id"`
+	ExpiresAt       time.Time              `json:"expires_at"`
+}
+
+// =============================================================================
+// PROJECT INVITATIONS MANAGEMENT
+// =============================================================================
+func (q *Queries) CreateProjectInvitation(ctx context.Context, arg CreateProjectInvitationParams) (sql.Result, error) {
+	return q.exec(ctx, q.createProjectInvitationStmt, createProjectInvitation,
+		arg.ProjectID,
+		arg.Email,
+		arg.Role,
+		arg.TokenHash,
+		arg.InvitedByUserID,
+		arg.ExpiresAt,
+	)
+}
+
+const createProjectOwnershipTransfer = `-- name: CreateProjectOwnershipTransfer :execresult
+
+INSERT INTO project_ownership_transfers (project_id, from_user_id, to_user_id) VALUES (?, ?, ?)
+`
+
+type CreateProjectOwnershipTransferParams struct {
+	ProjectID  uint64 `json:"project_id"`
+	FromUserID uint64 `json:"from_user_id"`
+	ToUserID   uint64 `json:"to_user_id"`
+}
+
+// =============================================================================
+// PROJECT OWNERSHIP TRANSFERS
+// =============================================================================
+func (q *Queries) CreateProjectOwnershipTransfer(ctx context.Context, arg CreateProjectOwnershipTransferParams) (sql.Result, error) {
+	return q.exec(ctx, q.createProjectOwnershipTransferStmt, createProjectOwnershipTransfer, arg.ProjectID, arg.FromUserID, arg.ToUserID)
+}
+
 const createRequestItem = `-- name: CreateRequestItem :execresult
 
 INSERT INTO request_items (
@@ -802,6 +863,61 @@ func (q *Queries) GetItemStats(ctx context.Context, requestID uint64) (GetItemSt
 	return i, err
 }
 
+const getPendingOwnershipTransfer = `-- name: GetPendingOwnershipTransfer :one
+SELECT id, project_id, from_user_id, to_user_id, status, responded_at, created_at, updated_at FROM project_ownership_transfers
+WHERE project_id = ? AND status = 'pending'
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetPendingOwnershipTransfer(ctx context.Context, projectID uint64) (ProjectOwnershipTransfer, error) {
+	row := q.queryRow(ctx, q.getPendingOwnershipTransferStmt, getPendingOwnershipTransfer, projectID)
+	var i ProjectOwnershipTransfer
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.FromUserID,
+		&i.ToUserID,
+		&i.Status,
+		&i.RespondedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getPendingProjectInvitation = `-- name: GetPendingProjectInvitation :one
+SELECT id, project_id, email, role, token_hash, status, invited_by_user_id, responded_by_user_id, expires_at, sent_at, responded_at, created_at, updated_at FROM project_invitations
+WHERE project_id = ? AND email = ? AND status = 'pending'
+LIMIT 1
+`
+
+type GetPendingProjectInvitationParams struct {
+	ProjectID uint64 `json:"project_id"`
+	Email     string `json:"email"`
+}
+
+func (q *Queries) GetPendingProjectInvitation(ctx context.Context, arg GetPendingProjectInvitationParams) (ProjectInvitation, error) {
+	row := q.queryRow(ctx, q.getPendingProjectInvitationStmt, getPendingProjectInvitation, arg.ProjectID, arg.Email)
+	var i ProjectInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Email,
+		&i.Role,
+		&i.TokenHash,
+		&i.Status,
+		&i.InvitedByUserID,
+		&i.RespondedByUserID,
+		&i.ExpiresAt,
+		&i.SentAt,
+		&i.RespondedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getProjectAPIKeyByHash = `-- name: GetProjectAPIKeyByHash :one
 SELECT id, project_id, name, token_hash, active, last_used_at, created_at FROM project_api_keys WHERE token_hash = ? AND active = TRUE LIMIT 1
 `
@@ -841,7 +957,7 @@ func (q *Queries) GetProjectAPIKeyByID(ctx context.Context, id uint64) (ProjectA
 }
 
 const getProjectByID = `-- name: GetProjectByID :one
-SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, created_at, updated_at FROM projects WHERE id = ? LIMIT 1
+SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, archived_at, deleted_at, created_at, updated_at FROM projects WHERE id = ? LIMIT 1
 `
 
 func (q *Queries) GetProjectByID(ctx context.Context, id uint64) (Project, error) {
@@ -856,6 +972,8 @@ func (q *Queries) GetProjectByID(ctx context.Context, id uint64) (Project, error
 		&i.Visibility,
 		&i.Description,
 		&i.IconCount,
+		&i.ArchivedAt,
+		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -863,7 +981,7 @@ func (q *Queries) GetProjectByID(ctx context.Context, id uint64) (Project, error
 }
 
 const getProjectByIDAndOwner = `-- name: GetProjectByIDAndOwner :one
-SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, created_at, updated_at FROM projects WHERE id = ? AND owner_user_id = ? LIMIT 1
+SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, archived_at, deleted_at, created_at, updated_at FROM projects WHERE id = ? AND owner_user_id = ? LIMIT 1
 `
 
 type GetProjectByIDAndOwnerParams struct {
@@ -883,6 +1001,8 @@ func (q *Queries) GetProjectByIDAndOwner(ctx context.Context, arg GetProjectByID
 		&i.Visibility,
 		&i.Description,
 		&i.IconCount,
+		&i.ArchivedAt,
+		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -890,7 +1010,7 @@ func (q *Queries) GetProjectByIDAndOwner(ctx context.Context, arg GetProjectByID
 }
 
 const getProjectBySlug = `-- name: GetProjectBySlug :one
-SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, created_at, updated_at FROM projects WHERE owner_user_id = ? AND slug = ? LIMIT 1
+SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, archived_at, deleted_at, created_at, updated_at FROM projects WHERE owner_user_id = ? AND slug = ? LIMIT 1
 `
 
 type GetProjectBySlugParams struct {
@@ -910,6 +1030,58 @@ func (q *Queries) GetProjectBySlug(ctx context.Context, arg GetProjectBySlugPara
 		&i.Visibility,
 		&i.Description,
 		&i.IconCount,
+		&i.ArchivedAt,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectInvitationByID = `-- name: GetProjectInvitationByID :one
+SELECT id, project_id, email, role, token_hash, status, invited_by_user_id, responded_by_user_id, expires_at, sent_at, responded_at, created_at, updated_at FROM project_invitations WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetProjectInvitationByID(ctx context.Context, id uint64) (ProjectInvitation, error) {
+	row := q.queryRow(ctx, q.getProjectInvitationByIDStmt, getProjectInvitationByID, id)
+	var i ProjectInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Email,
+		&i.Role,
+		&i.TokenHash,
+		&i.Status,
+		&i.InvitedByUserID,
+		&i.RespondedByUserID,
+		&i.ExpiresAt,
+		&i.SentAt,
+		&i.RespondedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProjectInvitationByTokenHash = `-- name: GetProjectInvitationByTokenHash :one
+SELECT id, project_id, email, role, token_hash, status, invited_by_user_id, responded_by_user_id, expires_at, sent_at, responded_at, created_at, updated_at FROM project_invitations WHERE token_hash = ? LIMIT 1
+`
+
+func (q *Queries) GetProjectInvitationByTokenHash(ctx context.Context, tokenHash string) (ProjectInvitation, error) {
+	row := q.queryRow(ctx, q.getProjectInvitationByTokenHashStmt, getProjectInvitationByTokenHash, tokenHash)
+	var i ProjectInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Email,
+		&i.Role,
+		&i.TokenHash,
+		&i.Status,
+		&i.InvitedByUserID,
+		&i.RespondedByUserID,
+		&i.ExpiresAt,
+		&i.SentAt,
+		&i.RespondedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -941,7 +1113,7 @@ func (q *Queries) GetProjectStats(ctx context.Context, ownerUserID uint64) (GetP
 const getProjectWithStats = `-- name: GetProjectWithStats :one
 
 SELECT 
-  p.id, p.owner_user_id, p.name, p.slug, p.package_name, p.visibility, p.description, p.icon_count, p.created_at, p.updated_at,
+  p.id, p.owner_user_id, p.name, p.slug, p.package_name, p.visibility, p.description, p.icon_count, p.archived_at, p.deleted_at, p.created_at, p.updated_at,
   COALESCE(icon_stats.total_icons, 0) as total_icons,
   COALESCE(icon_stats.published_icons, 0) as published_icons,
   COALESCE(request_stats.total_requests, 0) as total_requests,
@@ -981,6 +1153,8 @@ type GetProjectWithStatsRow struct {
 	Visibility        ProjectsVisibility `json:"visibility"`
 	Description       sql.NullString     `json:"description"`
 	IconCount         uint32             `json:"icon_count"`
+	ArchivedAt        sql.NullTime       `json:"archived_at"`
+	DeletedAt         sql.NullTime       `json:"deleted_at"`
 	CreatedAt         time.Time          `json:"created_at"`
 	UpdatedAt         time.Time          `json:"updated_at"`
 	TotalIcons        int64              `json:"total_icons"`
@@ -1004,6 +1178,8 @@ func (q *Queries) GetProjectWithStats(ctx context.Context, id uint64) (GetProjec
 		&i.Visibility,
 		&i.Description,
 		&i.IconCount,
+		&i.ArchivedAt,
+		&i.DeletedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.TotalIcons,
@@ -1132,10 +1308,11 @@ func (q *Queries) GetUserQuota(ctx context.Context, userID uint64) (UserQuota, e
 }
 
 const listCollaboratorProjectIDs = `-- name: ListCollaboratorProjectIDs :many
-SELECT project_id 
-FROM user_project_roles 
-WHERE user_id = ? AND role <> 'owner' 
-ORDER BY added_at DESC 
+SELECT upr.project_id 
+FROM user_project_roles upr
+JOIN projects p ON upr.project_id = p.id
+WHERE upr.user_id = ? AND upr.role <> 'owner' AND p.deleted_at IS NULL 
+ORDER BY upr.added_at DESC 
 LIMIT ? OFFSET ?
 `
 
@@ -1169,6 +1346,84 @@ func (q *Queries) ListCollaboratorProjectIDs(ctx context.Context, arg ListCollab
 	return items, nil
 }
 
+const listComponentsByPackage = `-- name: ListComponentsByPackage :many
+SELECT component_info FROM icons WHERE icons.pkg = ?
+UNION ALL
+SELECT component_info FROM request_items WHERE request_items.pkg = ?
+LIMIT ?
+`
+
+type ListComponentsByPackageParams struct {
+	Pkg   string `json:"pkg"`
+	Pkg_2 string `json:"pkg_2"`
+	Limit int32  `json:"limit"`
+}
+
+// Component identifiers stored for a package in any project's icons or
+// request items, used to build the component index for activity resolution
+func (q *Queries) ListComponentsByPackage(ctx context.Context, arg ListComponentsByPackageParams) ([]string, error) {
+	rows, err := q.query(ctx, q.listComponentsByPackageStmt, listComponentsByPackage, arg.Pkg, arg.Pkg_2, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var component_info string
+		if err := rows.Scan(&component_info); err != nil {
+			return nil, err
+		}
+		items = append(items, component_info)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedProjectsByOwner = `-- name: ListDeletedProjectsByOwner :many
+SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, archived_at, deleted_at, created_at, updated_at FROM projects WHERE owner_user_id = ? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC
+`
+
+func (q *Queries) ListDeletedProjectsByOwner(ctx context.Context, ownerUserID uint64) ([]Project, error) {
+	rows, err := q.query(ctx, q.listDeletedProjectsByOwnerStmt, listDeletedProjectsByOwner, ownerUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Project{}
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerUserID,
+			&i.Name,
+			&i.Slug,
+			&i.PackageName,
+			&i.Visibility,
+			&i.Description,
+			&i.IconCount,
+			&i.ArchivedAt,
+			&i.DeletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listIconsByPackage = `-- name: ListIconsByPackage :many
 SELECT id, project_id, name, pkg, component_info, drawable, status, metadata, created_at, updated_at FROM icons WHERE project_id = ? AND pkg = ? ORDER BY name ASC
 `
@@ -1310,7 +1565,7 @@ func (q *Queries) ListItemsByResolution(ctx context.Context, arg ListItemsByReso
 const listOwnedProjectIDs = `-- name: ListOwnedProjectIDs :many
 SELECT id 
 FROM projects 
-WHERE owner_user_id = ? 
+WHERE owner_user_id = ? AND deleted_at IS NULL 
 ORDER BY created_at DESC 
 LIMIT ? OFFSET ?
 `
@@ -1345,6 +1600,58 @@ func (q *Queries) ListOwnedProjectIDs(ctx context.Context, arg ListOwnedProjectI
 	return items, nil
 }
 
+const listPendingInvitationsByEmail = `-- name: ListPendingInvitationsByEmail :many
+SELECT pi.id, pi.project_id, pi.role, pi.expires_at, pi.created_at, p.name, p.slug, u.username AS invited_by_username
+FROM project_invitations pi
+JOIN projects p ON pi.project_id = p.id
+JOIN users u ON pi.invited_by_user_id = u.id
+WHERE pi.email = ? AND pi.status = 'pending' AND pi.expires_at > CURRENT_TIMESTAMP(6) AND p.deleted_at IS NULL
+ORDER BY pi.created_at DESC
+`
+
+type ListPendingInvitationsByEmailRow struct {
+	ID                uint64                 `json:"id"`
+	ProjectID         uint64                 `json:"project_id"`
+	Role              ProjectInvitationsRole `json:"role"`
+	ExpiresAt         time.Time              `json:"expires_at"`
+	CreatedAt         time.Time              `json:"created_at"`
+	Name              string                 `json:"name"`
+	Slug              string                 `json:"slug"`
+	InvitedByUsername string                 `json:"invited_by_username"`
+}
+
+func (q *Queries) ListPendingInvitationsByEmail(ctx context.Context, email string) ([]ListPendingInvitationsByEmailRow, error) {
+	rows, err := q.query(ctx, q.listPendingInvitationsByEmailStmt, listPendingInvitationsByEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPendingInvitationsByEmailRow{}
+	for rows.Next() {
+		var i ListPendingInvitationsByEmailRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Role,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.Name,
+			&i.Slug,
+			&i.InvitedByUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listProjectAPIKeys = `-- name: ListProjectAPIKeys :many
 SELECT id, project_id, name, token_hash, active, last_used_at, created_at FROM project_api_keys WHERE project_id = ? ORDER BY created_at DESC
 `
@@ -1473,6 +1780,54 @@ func (q *Queries) ListProjectIcons(ctx context.Context, arg ListProjectIconsPara
 	return items, nil
 }
 
+const listProjectInvitations = `-- name: ListProjectInvitations :many
+SELECT id, project_id, email, role, token_hash, status, invited_by_user_id, responded_by_user_id, expires_at, sent_at, responded_at, created_at, updated_at FROM project_invitations
+WHERE project_id = ? AND status = ?
+ORDER BY created_at DESC
+`
+
+type ListProjectInvitationsParams struct {
+	ProjectID uint64                   `json:"project_id"`
+	Status    ProjectInvitationsStatus `json:"status"`
+}
+
+func (q *Queries) ListProjectInvitations(ctx context.Context, arg ListProjectInvitationsParams) ([]ProjectInvitation, error) {
+	rows, err := q.query(ctx, q.listProjectInvitationsStmt, listProjectInvitations, arg.ProjectID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ProjectInvitation{}
+	for rows.Next() {
+		var i ProjectInvitation
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.Email,
+			&i.Role,
+			&i.TokenHash,
+			&i.Status,
+			&i.InvitedByUserID,
+			&i.RespondedByUserID,
+			&i.ExpiresAt,
+			&i.SentAt,
+			&i.RespondedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listProjectRequestItems = `-- name: ListProjectRequestItems :many
 SELECT id, request_id, project_id, name, pkg, component_info, drawable, matched_icon_id, resolution, notes, created_at, updated_at FROM request_items WHERE project_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?
 `
@@ -1564,7 +1919,7 @@ func (q *Queries) ListProjectRequests(ctx context.Context, arg ListProjectReques
 }
 
 const listProjectsByOwner = `-- name: ListProjectsByOwner :many
-SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, created_at, updated_at FROM projects WHERE owner_user_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?
+SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, archived_at, deleted_at, created_at, updated_at FROM projects WHERE owner_user_id = ? ORDER BY created_at DESC LIMIT ? OFFSET ?
 `
 
 type ListProjectsByOwnerParams struct {
@@ -1591,6 +1946,8 @@ func (q *Queries) ListProjectsByOwner(ctx context.Context, arg ListProjectsByOwn
 			&i.Visibility,
 			&i.Description,
 			&i.IconCount,
+			&i.ArchivedAt,
+			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -1608,7 +1965,7 @@ func (q *Queries) ListProjectsByOwner(ctx context.Context, arg ListProjectsByOwn
 }
 
 const listProjectsByVisibility = `-- name: ListProjectsByVisibility :many
-SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, created_at, updated_at FROM projects WHERE visibility = ? ORDER BY created_at DESC LIMIT ? OFFSET ?
+SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, archived_at, deleted_at, created_at, updated_at FROM projects WHERE visibility = ? AND deleted_at IS NULL ORDER BY created_at DESC LIMIT ? OFFSET ?
 `
 
 type ListProjectsByVisibilityParams struct {
@@ -1635,6 +1992,8 @@ func (q *Queries) ListProjectsByVisibility(ctx context.Context, arg ListProjects
 			&i.Visibility,
 			&i.Description,
 			&i.IconCount,
+			&i.ArchivedAt,
+			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -1651,8 +2010,40 @@ func (q *Queries) ListProjectsByVisibility(ctx context.Context, arg ListProjects
 	return items, nil
 }
 
+const listProjectsDeletedBefore = `-- name: ListProjectsDeletedBefore :many
+SELECT id FROM projects WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY deleted_at LIMIT ?
+`
+
+type ListProjectsDeletedBeforeParams struct {
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	Limit     int32        `json:"limit"`
+}
+
+func (q *Queries) ListProjectsDeletedBefore(ctx context.Context, arg ListProjectsDeletedBeforeParams) ([]uint64, error) {
+	rows, err := q.query(ctx, q.listProjectsDeletedBeforeStmt, listProjectsDeletedBefore, arg.DeletedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uint64{}
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPublicProjects = `-- name: ListPublicProjects :many
-SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, created_at, updated_at FROM projects WHERE visibility = 'public' ORDER BY created_at DESC LIMIT ? OFFSET ?
+SELECT id, owner_user_id, name, slug, package_name, visibility, description, icon_count, archived_at, deleted_at, created_at, updated_at FROM projects WHERE visibility = 'public' AND deleted_at IS NULL ORDER BY created_at DESC LIMIT ? OFFSET ?
 `
 
 type ListPublicProjectsParams struct {
@@ -1678,6 +2069,8 @@ func (q *Queries) ListPublicProjects(ctx context.Context, arg ListPublicProjects
 			&i.Visibility,
 			&i.Description,
 			&i.IconCount,
+			&i.ArchivedAt,
+			&i.DeletedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -1917,6 +2310,41 @@ func (q *Queries) ListUserProjects(ctx context.Context, userID uint64) ([]ListUs
 	return items, nil
 }
 
+const lockProject = `-- name: LockProject :one
+SELECT id FROM projects WHERE id = ? FOR UPDATE
+`
+
+func (q *Queries) LockProject(ctx context.Context, id uint64) (uint64, error) {
+	row := q.queryRow(ctx, q.lockProjectStmt, lockProject, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const purgeProject = `-- name: PurgeProject :execresult
+DELETE FROM projects WHERE id = ? AND deleted_at IS NOT NULL AND deleted_at < ?
+`
+
+type PurgeProjectParams struct {
+	ID        uint64       `json:"id"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
+}
+
+func (q *Queries) PurgeProject(ctx context.Context, arg PurgeProjectParams) (sql.Result, error) {
+	return q.exec(ctx, q.purgeProjectStmt, purgeProject, arg.ID, arg.DeletedAt)
+}
+
+const restoreProject = `-- name: RestoreProject :exec
+UPDATE projects SET 
+  deleted_at = NULL,
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ?
+`
+
+func (q *Queries) RestoreProject(ctx context.Context, id uint64) error {
+	_, err := q.exec(ctx, q.restoreProjectStmt, restoreProject, id)
+	return err
+}
+
 const searchIcons = `-- name: SearchIcons :many
 SELECT id, project_id, name, pkg, component_info, drawable, status, metadata, created_at, updated_at FROM icons 
 WHERE project_id = ? 
@@ -1983,6 +2411,30 @@ func (q *Queries) SearchIcons(ctx context.Context, arg SearchIconsParams) ([]Ico
 	return items, nil
 }
 
+const softDeleteProject = `-- name: SoftDeleteProject :exec
+UPDATE projects SET 
+  deleted_at = CURRENT_TIMESTAMP(6),
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteProject(ctx context.Context, id uint64) error {
+	_, err := q.exec(ctx, q.softDeleteProjectStmt, softDeleteProject, id)
+	return err
+}
+
+const unarchiveProject = `-- name: UnarchiveProject :exec
+UPDATE projects SET 
+  archived_at = NULL,
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ?
+`
+
+func (q *Queries) UnarchiveProject(ctx context.Context, id uint64) error {
+	_, err := q.exec(ctx, q.unarchiveProjectStmt, unarchiveProject, id)
+	return err
+}
+
 const updateAPIKeyLastUsed = `-- name: UpdateAPIKeyLastUsed :exec
 UPDATE project_api_keys SET 
   last_used_at = CURRENT_TIMESTAMP(6)
@@ -2031,6 +2483,24 @@ func (q *Queries) UpdateIcon(ctx context.Context, arg UpdateIconParams) error {
 	return err
 }
 
+const updateIconDrawable = `-- name: UpdateIconDrawable :exec
+UPDATE icons SET 
+  drawable = ?,
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND project_id = ?
+`
+
+type UpdateIconDrawableParams struct {
+	Drawable  string `json:"drawable"`
+	ID        uint64 `json:"id"`
+	ProjectID uint64 `json:"project_id"`
+}
+
+func (q *Queries) UpdateIconDrawable(ctx context.Context, arg UpdateIconDrawableParams) error {
+	_, err := q.exec(ctx, q.updateIconDrawableStmt, updateIconDrawable, arg.Drawable, arg.ID, arg.ProjectID)
+	return err
+}
+
 const updateIconStatus = `-- name: UpdateIconStatus :exec
 UPDATE icons SET 
   status = ?,
@@ -2077,6 +2547,22 @@ func (q *Queries) UpdateItemResolution(ctx context.Context, arg UpdateItemResolu
 	return err
 }
 
+const updateOwnershipTransferStatus = `-- name: UpdateOwnershipTransferStatus :execresult
+UPDATE project_ownership_transfers SET 
+  status = ?,
+  responded_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND status = 'pending'
+`
+
+type UpdateOwnershipTransferStatusParams struct {
+	Status ProjectOwnershipTransfersStatus `json:"status"`
+	ID     uint64                          `json:"id"`
+}
+
+func (q *Queries) UpdateOwnershipTransferStatus(ctx context.Context, arg UpdateOwnershipTransferStatusParams) (sql.Result, error) {
+	return q.exec(ctx, q.updateOwnershipTransferStatusStmt, updateOwnershipTransferStatus, arg.Status, arg.ID)
+}
+
 const updateProject = `-- name: UpdateProject :exec
 UPDATE projects SET 
   name = ?, 
@@ -2128,6 +2614,60 @@ func (q *Queries) UpdateProjectIconCount(ctx context.Context, arg UpdateProjectI
 	return err
 }
 
+const updateProjectInvitationStatus = `-- name: UpdateProjectInvitationStatus :execresult
+UPDATE project_invitations SET 
+  status = ?,
+  responded_by_user_id = ?,
+  responded_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND status = 'pending'
+`
+
+type UpdateProjectInvitationStatusParams struct {
+	Status            ProjectInvitationsStatus `json:"status"`
+	RespondedByUserID sql.NullInt64            `json:"responded_by_user_id"`
+	ID                uint64                   `json:"id"`
+}
+
+func (q *Queries) UpdateProjectInvitationStatus(ctx context.Context, arg UpdateProjectInvitationStatusParams) (sql.Result, error) {
+	return q.exec(ctx, q.updateProjectInvitationStatusStmt, updateProjectInvitationStatus, arg.Status, arg.RespondedByUserID, arg.ID)
+}
+
+const updateProjectInvitationToken = `-- name: UpdateProjectInvitationToken :exec
+UPDATE project_invitations SET 
+  token_hash = ?,
+  expires_at = ?,
+  sent_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND status = 'pending'
+`
+
+type UpdateProjectInvitationTokenParams struct {
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ID        uint64    `json:"id"`
+}
+
+func (q *Queries) UpdateProjectInvitationToken(ctx context.Context, arg UpdateProjectInvitationTokenParams) error {
+	_, err := q.exec(ctx, q.updateProjectInvitationTokenStmt, updateProjectInvitationToken, arg.TokenHash, arg.ExpiresAt, arg.ID)
+	return err
+}
+
+const updateProjectOwner = `-- name: UpdateProjectOwner :execresult
+UPDATE projects SET 
+  owner_user_id = ?,
+  updated_at = CURRENT_TIMESTAMP(6)
+WHERE id = ? AND owner_user_id = ?
+`
+
+type UpdateProjectOwnerParams struct {
+	NewOwnerUserID uint64 `json:"new_owner_user_id"`
+	ID             uint64 `json:"id"`
+	OwnerUserID    uint64 `json:"owner_user_id"`
+}
+
+func (q *Queries) UpdateProjectOwner(ctx context.Context, arg UpdateProjectOwnerParams) (sql.Result, error) {
+	return q.exec(ctx, q.updateProjectOwnerStmt, updateProjectOwner, arg.NewOwnerUserID, arg.ID, arg.OwnerUserID)
+}
+
 const updateRequestArchivePath = `-- name: UpdateRequestArchivePath :exec
 UPDATE icon_requests SET 
   archive_path = ?,
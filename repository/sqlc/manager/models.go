@@ -143,6 +143,137 @@ func (ns NullIconsStatus) Value() (driver.Value, error) {
 	return string(ns.IconsStatus), nil
 }
 
+type ProjectInvitationsRole string
+
+const (
+	ProjectInvitationsRoleAdmin  ProjectInvitationsRole = "admin"
+	ProjectInvitationsRoleEditor ProjectInvitationsRole = "editor"
+	ProjectInvitationsRoleViewer ProjectInvitationsRole = "viewer"
+)
+
+func (e *ProjectInvitationsRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProjectInvitationsRole(s)
+	case string:
+		*e = ProjectInvitationsRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProjectInvitationsRole: %T", src)
+	}
+	return nil
+}
+
+type NullProjectInvitationsRole struct {
+	ProjectInvitationsRole ProjectInvitationsRole `json:"project_invitations_role"`
+	Valid                  bool                   `json:"valid"` // Valid is true if ProjectInvitationsRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProjectInvitationsRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProjectInvitationsRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProjectInvitationsRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProjectInvitationsRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProjectInvitationsRole), nil
+}
+
+type ProjectInvitationsStatus string
+
+const (
+	ProjectInvitationsStatusPending  ProjectInvitationsStatus = "pending"
+	ProjectInvitationsStatusAccepted ProjectInvitationsStatus = "accepted"
+	ProjectInvitationsStatusDeclined ProjectInvitationsStatus = "declined"
+	ProjectInvitationsStatusRevoked  ProjectInvitationsStatus = "revoked"
+)
+
+func (e *ProjectInvitationsStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProjectInvitationsStatus(s)
+	case string:
+		*e = ProjectInvitationsStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProjectInvitationsStatus: %T", src)
+	}
+	return nil
+}
+
+type NullProjectInvitationsStatus struct {
+	ProjectInvitationsStatus ProjectInvitationsStatus `json:"project_invitations_status"`
+	Valid                    bool                     `json:"valid"` // Valid is true if ProjectInvitationsStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProjectInvitationsStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProjectInvitationsStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProjectInvitationsStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProjectInvitationsStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProjectInvitationsStatus), nil
+}
+
+type ProjectOwnershipTransfersStatus string
+
+const (
+	ProjectOwnershipTransfersStatusPending   ProjectOwnershipTransfersStatus = "pending"
+	ProjectOwnershipTransfersStatusAccepted  ProjectOwnershipTransfersStatus = "accepted"
+	ProjectOwnershipTransfersStatusDeclined  ProjectOwnershipTransfersStatus = "declined"
+	ProjectOwnershipTransfersStatusCancelled ProjectOwnershipTransfersStatus = "cancelled"
+)
+
+func (e *ProjectOwnershipTransfersStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProjectOwnershipTransfersStatus(s)
+	case string:
+		*e = ProjectOwnershipTransfersStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProjectOwnershipTransfersStatus: %T", src)
+	}
+	return nil
+}
+
+type NullProjectOwnershipTransfersStatus struct {
+	ProjectOwnershipTransfersStatus ProjectOwnershipTransfersStatus `json:"project_ownership_transfers_status"`
+	Valid                           bool                            `json:"valid"` // Valid is true if ProjectOwnershipTransfersStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProjectOwnershipTransfersStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProjectOwnershipTransfersStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProjectOwnershipTransfersStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProjectOwnershipTransfersStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProjectOwnershipTransfersStatus), nil
+}
+
 type ProjectsVisibility string
 
 const (
@@ -328,9 +459,13 @@ type Project struct {
 	// Project description
 	Description sql.NullString `json:"description"`
 	// Cached icon count for performance
-	IconCount uint32    `json:"icon_count"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	IconCount uint32 `json:"icon_count"`
+	// Set while the project is archived (read-only)
+	ArchivedAt sql.NullTime `json:"archived_at"`
+	// Set when the project was deleted; purged after the retention window
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
 }
 
 // API keys for project authentication
@@ -348,6 +483,44 @@ type ProjectApiKey struct {
 	CreatedAt  time.Time    `json:"created_at"`
 }
 
+// Project collaboration invitations by email
+type ProjectInvitation struct {
+	ID        uint64 `json:"id"`
+	ProjectID uint64 `json:"project_id"`
+	// Invited email address, lowercased
+	Email string `json:"email"`
+	// Role granted on acceptance
+	Role ProjectInvitationsRole `json:"role"`
+	// SHA-256 of the accept token, never store plaintext
+	TokenHash string `json:"token_hash"`
+	// Invitation state
+	Status          ProjectInvitationsStatus `json:"status"`
+	InvitedByUserID uint64                   `json:"invited_by_user_id"`
+	// User who accepted or declined
+	RespondedByUserID sql.NullInt64 `json:"responded_by_user_id"`
+	// Accept token expiry, extended on resend
+	ExpiresAt   time.Time    `json:"expires_at"`
+	SentAt      time.Time    `json:"sent_at"`
+	RespondedAt sql.NullTime `json:"responded_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Project ownership transfers
+type ProjectOwnershipTransfer struct {
+	ID        uint64 `json:"id"`
+	ProjectID uint64 `json:"project_id"`
+	// Owner who proposed the transfer
+	FromUserID uint64 `json:"from_user_id"`
+	// Member who becomes the owner on acceptance
+	ToUserID uint64 `json:"to_user_id"`
+	// Transfer state
+	Status      ProjectOwnershipTransfersStatus `json:"status"`
+	RespondedAt sql.NullTime                    `json:"responded_at"`
+	CreatedAt   time.Time                       `json:"created_at"`
+	UpdatedAt   time.Time                       `json:"updated_at"`
+}
+
 // Individual request items within a batch
 type RequestItem struct {
 	ID        uint64 `json:"id"`
@@ -10,6 +10,7 @@ import (
 )
 
 type Querier interface {
+	ArchiveProject(ctx context.Context, id uint64) error
 	CheckUserQuota(ctx context.Context, arg CheckUserQuotaParams) (CheckUserQuotaRow, error)
 	CountActiveAPIKeys(ctx context.Context, projectID uint64) (int64, error)
 	// Count collaborator projects (excluding owner role)
@@ -40,6 +41,14 @@ type Querier interface {
 	// =============================================================================
 	CreateProjectAPIKey(ctx context.Context, arg CreateProjectAPIKeyParams) (sql.Result, error)
 	// =============================================================================
+	// PROJECT INVITATIONS MANAGEMENT
+	// =============================================================================
+	CreateProjectInvitation(ctx context.Context, arg CreateProjectInvitationParams) (sql.Result, error)
+	// =============================================================================
+	// PROJECT OWNERSHIP TRANSFERS
+	// =============================================================================
+	CreateProjectOwnershipTransfer(ctx context.Context, arg CreateProjectOwnershipTransferParams) (sql.Result, error)
+	// =============================================================================
 	// REQUEST ITEMS MANAGEMENT
 	// =============================================================================
 	CreateRequestItem(ctx context.Context, arg CreateRequestItemParams) (sql.Result, error)
@@ -73,11 +82,15 @@ type Querier interface {
 	GetIconStats(ctx context.Context, projectID uint64) (GetIconStatsRow, error)
 	GetIconWithRequestInfo(ctx context.Context, id uint64) (GetIconWithRequestInfoRow, error)
 	GetItemStats(ctx context.Context, requestID uint64) (GetItemStatsRow, error)
+	GetPendingOwnershipTransfer(ctx context.Context, projectID uint64) (ProjectOwnershipTransfer, error)
+	GetPendingProjectInvitation(ctx context.Context, arg GetPendingProjectInvitationParams) (ProjectInvitation, error)
 	GetProjectAPIKeyByHash(ctx context.Context, tokenHash string) (ProjectApiKey, error)
 	GetProjectAPIKeyByID(ctx context.Context, id uint64) (ProjectApiKey, error)
 	GetProjectByID(ctx context.Context, id uint64) (Project, error)
 	GetProjectByIDAndOwner(ctx context.Context, arg GetProjectByIDAndOwnerParams) (Project, error)
 	GetProjectBySlug(ctx context.Context, arg GetProjectBySlugParams) (Project, error)
+	GetProjectInvitationByID(ctx context.Context, id uint64) (ProjectInvitation, error)
+	GetProjectInvitationByTokenHash(ctx context.Context, tokenHash string) (ProjectInvitation, error)
 	GetProjectStats(ctx context.Context, ownerUserID uint64) (GetProjectStatsRow, error)
 	// =============================================================================
 	// COMPLEX QUERIES AND JOINS
@@ -90,30 +103,47 @@ type Querier interface {
 	GetUserQuota(ctx context.Context, userID uint64) (UserQuota, error)
 	// Lightweight ID fetch for collaborator projects (excluding owner role)
 	ListCollaboratorProjectIDs(ctx context.Context, arg ListCollaboratorProjectIDsParams) ([]uint64, error)
+	// Component identifiers stored for a package in any project's icons or
+	// request items, used to build the component index for activity resolution
+	ListComponentsByPackage(ctx context.Context, arg ListComponentsByPackageParams) ([]string, error)
+	ListDeletedProjectsByOwner(ctx context.Context, ownerUserID uint64) ([]Project, error)
 	ListIconsByPackage(ctx context.Context, arg ListIconsByPackageParams) ([]Icon, error)
 	ListIconsByStatus(ctx context.Context, arg ListIconsByStatusParams) ([]Icon, error)
 	ListItemsByResolution(ctx context.Context, arg ListItemsByResolutionParams) ([]RequestItem, error)
 	// Lightweight ID fetch for owner projects (useful for code-side merging/pagination)
 	ListOwnedProjectIDs(ctx context.Context, arg ListOwnedProjectIDsParams) ([]uint64, error)
+	ListPendingInvitationsByEmail(ctx context.Context, email string) ([]ListPendingInvitationsByEmailRow, error)
 	ListProjectAPIKeys(ctx context.Context, projectID uint64) ([]ProjectApiKey, error)
 	ListProjectCollaborators(ctx context.Context, projectID uint64) ([]ListProjectCollaboratorsRow, error)
 	ListProjectIcons(ctx context.Context, arg ListProjectIconsParams) ([]Icon, error)
+	ListProjectInvitations(ctx context.Context, arg ListProjectInvitationsParams) ([]ProjectInvitation, error)
 	ListProjectRequestItems(ctx context.Context, arg ListProjectRequestItemsParams) ([]RequestItem, error)
 	ListProjectRequests(ctx context.Context, arg ListProjectRequestsParams) ([]IconRequest, error)
 	ListProjectsByOwner(ctx context.Context, arg ListProjectsByOwnerParams) ([]Project, error)
 	ListProjectsByVisibility(ctx context.Context, arg ListProjectsByVisibilityParams) ([]Project, error)
+	ListProjectsDeletedBefore(ctx context.Context, arg ListProjectsDeletedBeforeParams) ([]uint64, error)
 	ListPublicProjects(ctx context.Context, arg ListPublicProjectsParams) ([]Project, error)
 	ListRecentActivity(ctx context.Context, arg ListRecentActivityParams) ([]ListRecentActivityRow, error)
 	ListRequestItems(ctx context.Context, requestID uint64) ([]RequestItem, error)
 	ListRequestsByStatus(ctx context.Context, arg ListRequestsByStatusParams) ([]IconRequest, error)
 	ListUserProjects(ctx context.Context, userID uint64) ([]ListUserProjectsRow, error)
+	LockProject(ctx context.Context, id uint64) (uint64, error)
+	PurgeProject(ctx context.Context, arg PurgeProjectParams) (sql.Result, error)
+	RestoreProject(ctx context.Context, id uint64) error
 	SearchIcons(ctx context.Context, arg SearchIconsParams) ([]Icon, error)
+	SoftDeleteProject(ctx context.Context, id uint64) error
+	UnarchiveProject(ctx context.Context, id uint64) error
 	UpdateAPIKeyLastUsed(ctx context.Context, id uint64) error
 	UpdateIcon(ctx context.Context, arg UpdateIconParams) error
+	UpdateIconDrawable(ctx context.Context, arg UpdateIconDrawableParams) error
 	UpdateIconStatus(ctx context.Context, arg UpdateIconStatusParams) error
 	UpdateItemResolution(ctx context.Context, arg UpdateItemResolutionParams) error
+	UpdateOwnershipTransferStatus(ctx context.Context, arg UpdateOwnershipTransferStatusParams) (sql.Result, error)
 	UpdateProject(ctx context.Context, arg UpdateProjectParams) error
 	UpdateProjectIconCount(ctx context.Context, arg UpdateProjectIconCountParams) error
+	UpdateProjectInvitationStatus(ctx context.Context, arg UpdateProjectInvitationStatusParams) (sql.Result, error)
+	UpdateProjectInvitationToken(ctx context.Context, arg UpdateProjectInvitationTokenParams) error
+	UpdateProjectOwner(ctx context.Context, arg UpdateProjectOwnerParams) (sql.Result, error)
 	UpdateRequestArchivePath(ctx context.Context, arg UpdateRequestArchivePathParams) error
 	UpdateRequestItem(ctx context.Context, arg UpdateRequestItemParams) error
 	UpdateRequestStatus(ctx context.Context, arg UpdateRequestStatusParams) error